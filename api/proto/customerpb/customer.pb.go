@@ -21,9 +21,59 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+type CustomerType int32
+
+const (
+	CustomerType_CUSTOMER_TYPE_UNSPECIFIED  CustomerType = 0
+	CustomerType_CUSTOMER_TYPE_INDIVIDUAL   CustomerType = 1
+	CustomerType_CUSTOMER_TYPE_LEGAL_ENTITY CustomerType = 2
+)
+
+// Enum value maps for CustomerType.
+var (
+	CustomerType_name = map[int32]string{
+		0: "CUSTOMER_TYPE_UNSPECIFIED",
+		1: "CUSTOMER_TYPE_INDIVIDUAL",
+		2: "CUSTOMER_TYPE_LEGAL_ENTITY",
+	}
+	CustomerType_value = map[string]int32{
+		"CUSTOMER_TYPE_UNSPECIFIED":  0,
+		"CUSTOMER_TYPE_INDIVIDUAL":   1,
+		"CUSTOMER_TYPE_LEGAL_ENTITY": 2,
+	}
+)
+
+func (x CustomerType) Enum() *CustomerType {
+	p := new(CustomerType)
+	*p = x
+	return p
+}
+
+func (x CustomerType) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (CustomerType) Descriptor() protoreflect.EnumDescriptor {
+	return file_customer_proto_enumTypes[0].Descriptor()
+}
+
+func (CustomerType) Type() protoreflect.EnumType {
+	return &file_customer_proto_enumTypes[0]
+}
+
+func (x CustomerType) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use CustomerType.Descriptor instead.
+func (CustomerType) EnumDescriptor() ([]byte, []int) {
+	return file_customer_proto_rawDescGZIP(), []int{0}
+}
+
 type UpsertCustomerRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Idn           string                 `protobuf:"bytes,1,opt,name=idn,proto3" json:"idn,omitempty"`
+	RequestId     string                 `protobuf:"bytes,2,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -65,11 +115,25 @@ func (x *UpsertCustomerRequest) GetIdn() string {
 	return ""
 }
 
+func (x *UpsertCustomerRequest) GetRequestId() string {
+	if x != nil {
+		return x.RequestId
+	}
+	return ""
+}
+
 type CustomerResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
 	Idn           string                 `protobuf:"bytes,2,opt,name=idn,proto3" json:"idn,omitempty"`
 	CreatedAt     string                 `protobuf:"bytes,3,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	Created       bool                   `protobuf:"varint,4,opt,name=created,proto3" json:"created,omitempty"`
+	Name          string                 `protobuf:"bytes,5,opt,name=name,proto3" json:"name,omitempty"`
+	Type          CustomerType           `protobuf:"varint,6,opt,name=type,proto3,enum=customer.CustomerType" json:"type,omitempty"`
+	Phone         string                 `protobuf:"bytes,7,opt,name=phone,proto3" json:"phone,omitempty"`
+	Email         string                 `protobuf:"bytes,8,opt,name=email,proto3" json:"email,omitempty"`
+	LegalAddress  string                 `protobuf:"bytes,9,opt,name=legal_address,json=legalAddress,proto3" json:"legal_address,omitempty"`
+	UpdatedAt     string                 `protobuf:"bytes,10,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -125,20 +189,803 @@ func (x *CustomerResponse) GetCreatedAt() string {
 	return ""
 }
 
+func (x *CustomerResponse) GetCreated() bool {
+	if x != nil {
+		return x.Created
+	}
+	return false
+}
+
+func (x *CustomerResponse) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *CustomerResponse) GetType() CustomerType {
+	if x != nil {
+		return x.Type
+	}
+	return CustomerType_CUSTOMER_TYPE_UNSPECIFIED
+}
+
+func (x *CustomerResponse) GetPhone() string {
+	if x != nil {
+		return x.Phone
+	}
+	return ""
+}
+
+func (x *CustomerResponse) GetEmail() string {
+	if x != nil {
+		return x.Email
+	}
+	return ""
+}
+
+func (x *CustomerResponse) GetLegalAddress() string {
+	if x != nil {
+		return x.LegalAddress
+	}
+	return ""
+}
+
+func (x *CustomerResponse) GetUpdatedAt() string {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return ""
+}
+
+type BatchUpsertCustomersRequest struct {
+	state         protoimpl.MessageState   `protogen:"open.v1"`
+	Items         []*UpsertCustomerRequest `protobuf:"bytes,1,rep,name=items,proto3" json:"items,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchUpsertCustomersRequest) Reset() {
+	*x = BatchUpsertCustomersRequest{}
+	mi := &file_customer_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchUpsertCustomersRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchUpsertCustomersRequest) ProtoMessage() {}
+
+func (x *BatchUpsertCustomersRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_customer_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchUpsertCustomersRequest.ProtoReflect.Descriptor instead.
+func (*BatchUpsertCustomersRequest) Descriptor() ([]byte, []int) {
+	return file_customer_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *BatchUpsertCustomersRequest) GetItems() []*UpsertCustomerRequest {
+	if x != nil {
+		return x.Items
+	}
+	return nil
+}
+
+type BatchUpsertCustomersResponse struct {
+	state         protoimpl.MessageState  `protogen:"open.v1"`
+	Results       []*UpsertCustomerResult `protobuf:"bytes,1,rep,name=results,proto3" json:"results,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchUpsertCustomersResponse) Reset() {
+	*x = BatchUpsertCustomersResponse{}
+	mi := &file_customer_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchUpsertCustomersResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchUpsertCustomersResponse) ProtoMessage() {}
+
+func (x *BatchUpsertCustomersResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_customer_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchUpsertCustomersResponse.ProtoReflect.Descriptor instead.
+func (*BatchUpsertCustomersResponse) Descriptor() ([]byte, []int) {
+	return file_customer_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *BatchUpsertCustomersResponse) GetResults() []*UpsertCustomerResult {
+	if x != nil {
+		return x.Results
+	}
+	return nil
+}
+
+type UpsertCustomerResult struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Customer      *CustomerResponse      `protobuf:"bytes,1,opt,name=customer,proto3" json:"customer,omitempty"`
+	Violations    []*FieldViolation      `protobuf:"bytes,2,rep,name=violations,proto3" json:"violations,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpsertCustomerResult) Reset() {
+	*x = UpsertCustomerResult{}
+	mi := &file_customer_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpsertCustomerResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpsertCustomerResult) ProtoMessage() {}
+
+func (x *UpsertCustomerResult) ProtoReflect() protoreflect.Message {
+	mi := &file_customer_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpsertCustomerResult.ProtoReflect.Descriptor instead.
+func (*UpsertCustomerResult) Descriptor() ([]byte, []int) {
+	return file_customer_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *UpsertCustomerResult) GetCustomer() *CustomerResponse {
+	if x != nil {
+		return x.Customer
+	}
+	return nil
+}
+
+func (x *UpsertCustomerResult) GetViolations() []*FieldViolation {
+	if x != nil {
+		return x.Violations
+	}
+	return nil
+}
+
+type FieldViolation struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Field         string                 `protobuf:"bytes,1,opt,name=field,proto3" json:"field,omitempty"`
+	Description   string                 `protobuf:"bytes,2,opt,name=description,proto3" json:"description,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FieldViolation) Reset() {
+	*x = FieldViolation{}
+	mi := &file_customer_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FieldViolation) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FieldViolation) ProtoMessage() {}
+
+func (x *FieldViolation) ProtoReflect() protoreflect.Message {
+	mi := &file_customer_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FieldViolation.ProtoReflect.Descriptor instead.
+func (*FieldViolation) Descriptor() ([]byte, []int) {
+	return file_customer_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *FieldViolation) GetField() string {
+	if x != nil {
+		return x.Field
+	}
+	return ""
+}
+
+func (x *FieldViolation) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+type CompensateUpsertCustomerRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	RequestId     string                 `protobuf:"bytes,1,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CompensateUpsertCustomerRequest) Reset() {
+	*x = CompensateUpsertCustomerRequest{}
+	mi := &file_customer_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CompensateUpsertCustomerRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CompensateUpsertCustomerRequest) ProtoMessage() {}
+
+func (x *CompensateUpsertCustomerRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_customer_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CompensateUpsertCustomerRequest.ProtoReflect.Descriptor instead.
+func (*CompensateUpsertCustomerRequest) Descriptor() ([]byte, []int) {
+	return file_customer_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *CompensateUpsertCustomerRequest) GetRequestId() string {
+	if x != nil {
+		return x.RequestId
+	}
+	return ""
+}
+
+type CompensateUpsertCustomerResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Deleted       bool                   `protobuf:"varint,1,opt,name=deleted,proto3" json:"deleted,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CompensateUpsertCustomerResponse) Reset() {
+	*x = CompensateUpsertCustomerResponse{}
+	mi := &file_customer_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CompensateUpsertCustomerResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CompensateUpsertCustomerResponse) ProtoMessage() {}
+
+func (x *CompensateUpsertCustomerResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_customer_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CompensateUpsertCustomerResponse.ProtoReflect.Descriptor instead.
+func (*CompensateUpsertCustomerResponse) Descriptor() ([]byte, []int) {
+	return file_customer_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *CompensateUpsertCustomerResponse) GetDeleted() bool {
+	if x != nil {
+		return x.Deleted
+	}
+	return false
+}
+
+type GetCustomerByIdRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetCustomerByIdRequest) Reset() {
+	*x = GetCustomerByIdRequest{}
+	mi := &file_customer_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetCustomerByIdRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetCustomerByIdRequest) ProtoMessage() {}
+
+func (x *GetCustomerByIdRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_customer_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetCustomerByIdRequest.ProtoReflect.Descriptor instead.
+func (*GetCustomerByIdRequest) Descriptor() ([]byte, []int) {
+	return file_customer_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *GetCustomerByIdRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type GetCustomerByIdnRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Idn           string                 `protobuf:"bytes,1,opt,name=idn,proto3" json:"idn,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetCustomerByIdnRequest) Reset() {
+	*x = GetCustomerByIdnRequest{}
+	mi := &file_customer_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetCustomerByIdnRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetCustomerByIdnRequest) ProtoMessage() {}
+
+func (x *GetCustomerByIdnRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_customer_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetCustomerByIdnRequest.ProtoReflect.Descriptor instead.
+func (*GetCustomerByIdnRequest) Descriptor() ([]byte, []int) {
+	return file_customer_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *GetCustomerByIdnRequest) GetIdn() string {
+	if x != nil {
+		return x.Idn
+	}
+	return ""
+}
+
+type GetCustomersRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Ids           []string               `protobuf:"bytes,1,rep,name=ids,proto3" json:"ids,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetCustomersRequest) Reset() {
+	*x = GetCustomersRequest{}
+	mi := &file_customer_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetCustomersRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetCustomersRequest) ProtoMessage() {}
+
+func (x *GetCustomersRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_customer_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetCustomersRequest.ProtoReflect.Descriptor instead.
+func (*GetCustomersRequest) Descriptor() ([]byte, []int) {
+	return file_customer_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *GetCustomersRequest) GetIds() []string {
+	if x != nil {
+		return x.Ids
+	}
+	return nil
+}
+
+type GetCustomersResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Customers     []*CustomerResponse    `protobuf:"bytes,1,rep,name=customers,proto3" json:"customers,omitempty"`
+	NotFoundIds   []string               `protobuf:"bytes,2,rep,name=not_found_ids,json=notFoundIds,proto3" json:"not_found_ids,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetCustomersResponse) Reset() {
+	*x = GetCustomersResponse{}
+	mi := &file_customer_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetCustomersResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetCustomersResponse) ProtoMessage() {}
+
+func (x *GetCustomersResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_customer_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetCustomersResponse.ProtoReflect.Descriptor instead.
+func (*GetCustomersResponse) Descriptor() ([]byte, []int) {
+	return file_customer_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *GetCustomersResponse) GetCustomers() []*CustomerResponse {
+	if x != nil {
+		return x.Customers
+	}
+	return nil
+}
+
+func (x *GetCustomersResponse) GetNotFoundIds() []string {
+	if x != nil {
+		return x.NotFoundIds
+	}
+	return nil
+}
+
+type UpdateCustomerRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Phone         string                 `protobuf:"bytes,3,opt,name=phone,proto3" json:"phone,omitempty"`
+	Email         string                 `protobuf:"bytes,4,opt,name=email,proto3" json:"email,omitempty"`
+	LegalAddress  string                 `protobuf:"bytes,5,opt,name=legal_address,json=legalAddress,proto3" json:"legal_address,omitempty"`
+	UpdateMask    []string               `protobuf:"bytes,6,rep,name=update_mask,json=updateMask,proto3" json:"update_mask,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateCustomerRequest) Reset() {
+	*x = UpdateCustomerRequest{}
+	mi := &file_customer_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateCustomerRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateCustomerRequest) ProtoMessage() {}
+
+func (x *UpdateCustomerRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_customer_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateCustomerRequest.ProtoReflect.Descriptor instead.
+func (*UpdateCustomerRequest) Descriptor() ([]byte, []int) {
+	return file_customer_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *UpdateCustomerRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *UpdateCustomerRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *UpdateCustomerRequest) GetPhone() string {
+	if x != nil {
+		return x.Phone
+	}
+	return ""
+}
+
+func (x *UpdateCustomerRequest) GetEmail() string {
+	if x != nil {
+		return x.Email
+	}
+	return ""
+}
+
+func (x *UpdateCustomerRequest) GetLegalAddress() string {
+	if x != nil {
+		return x.LegalAddress
+	}
+	return ""
+}
+
+func (x *UpdateCustomerRequest) GetUpdateMask() []string {
+	if x != nil {
+		return x.UpdateMask
+	}
+	return nil
+}
+
+type ListCustomersRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Type          CustomerType           `protobuf:"varint,1,opt,name=type,proto3,enum=customer.CustomerType" json:"type,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	PageSize      int32                  `protobuf:"varint,3,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	PageToken     string                 `protobuf:"bytes,4,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListCustomersRequest) Reset() {
+	*x = ListCustomersRequest{}
+	mi := &file_customer_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListCustomersRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListCustomersRequest) ProtoMessage() {}
+
+func (x *ListCustomersRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_customer_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListCustomersRequest.ProtoReflect.Descriptor instead.
+func (*ListCustomersRequest) Descriptor() ([]byte, []int) {
+	return file_customer_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *ListCustomersRequest) GetType() CustomerType {
+	if x != nil {
+		return x.Type
+	}
+	return CustomerType_CUSTOMER_TYPE_UNSPECIFIED
+}
+
+func (x *ListCustomersRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *ListCustomersRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+func (x *ListCustomersRequest) GetPageToken() string {
+	if x != nil {
+		return x.PageToken
+	}
+	return ""
+}
+
+type ListCustomersResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Customers     []*CustomerResponse    `protobuf:"bytes,1,rep,name=customers,proto3" json:"customers,omitempty"`
+	NextPageToken string                 `protobuf:"bytes,2,opt,name=next_page_token,json=nextPageToken,proto3" json:"next_page_token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListCustomersResponse) Reset() {
+	*x = ListCustomersResponse{}
+	mi := &file_customer_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListCustomersResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListCustomersResponse) ProtoMessage() {}
+
+func (x *ListCustomersResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_customer_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListCustomersResponse.ProtoReflect.Descriptor instead.
+func (*ListCustomersResponse) Descriptor() ([]byte, []int) {
+	return file_customer_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *ListCustomersResponse) GetCustomers() []*CustomerResponse {
+	if x != nil {
+		return x.Customers
+	}
+	return nil
+}
+
+func (x *ListCustomersResponse) GetNextPageToken() string {
+	if x != nil {
+		return x.NextPageToken
+	}
+	return ""
+}
+
 var File_customer_proto protoreflect.FileDescriptor
 
 const file_customer_proto_rawDesc = "" +
 	"\n" +
-	"\x0ecustomer.proto\x12\bcustomer\")\n" +
+	"\x0ecustomer.proto\x12\bcustomer\"H\n" +
 	"\x15UpsertCustomerRequest\x12\x10\n" +
-	"\x03idn\x18\x01 \x01(\tR\x03idn\"S\n" +
+	"\x03idn\x18\x01 \x01(\tR\x03idn\x12\x1d\n" +
+	"\n" +
+	"request_id\x18\x02 \x01(\tR\trequestId\"\x9d\x02\n" +
 	"\x10CustomerResponse\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\x12\x10\n" +
 	"\x03idn\x18\x02 \x01(\tR\x03idn\x12\x1d\n" +
 	"\n" +
-	"created_at\x18\x03 \x01(\tR\tcreatedAt2`\n" +
+	"created_at\x18\x03 \x01(\tR\tcreatedAt\x12\x18\n" +
+	"\acreated\x18\x04 \x01(\bR\acreated\x12\x12\n" +
+	"\x04name\x18\x05 \x01(\tR\x04name\x12*\n" +
+	"\x04type\x18\x06 \x01(\x0e2\x16.customer.CustomerTypeR\x04type\x12\x14\n" +
+	"\x05phone\x18\a \x01(\tR\x05phone\x12\x14\n" +
+	"\x05email\x18\b \x01(\tR\x05email\x12#\n" +
+	"\rlegal_address\x18\t \x01(\tR\flegalAddress\x12\x1d\n" +
+	"\n" +
+	"updated_at\x18\n" +
+	" \x01(\tR\tupdatedAt\"T\n" +
+	"\x1bBatchUpsertCustomersRequest\x125\n" +
+	"\x05items\x18\x01 \x03(\v2\x1f.customer.UpsertCustomerRequestR\x05items\"X\n" +
+	"\x1cBatchUpsertCustomersResponse\x128\n" +
+	"\aresults\x18\x01 \x03(\v2\x1e.customer.UpsertCustomerResultR\aresults\"\x88\x01\n" +
+	"\x14UpsertCustomerResult\x126\n" +
+	"\bcustomer\x18\x01 \x01(\v2\x1a.customer.CustomerResponseR\bcustomer\x128\n" +
+	"\n" +
+	"violations\x18\x02 \x03(\v2\x18.customer.FieldViolationR\n" +
+	"violations\"H\n" +
+	"\x0eFieldViolation\x12\x14\n" +
+	"\x05field\x18\x01 \x01(\tR\x05field\x12 \n" +
+	"\vdescription\x18\x02 \x01(\tR\vdescription\"@\n" +
+	"\x1fCompensateUpsertCustomerRequest\x12\x1d\n" +
+	"\n" +
+	"request_id\x18\x01 \x01(\tR\trequestId\"<\n" +
+	" CompensateUpsertCustomerResponse\x12\x18\n" +
+	"\adeleted\x18\x01 \x01(\bR\adeleted\"(\n" +
+	"\x16GetCustomerByIdRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"+\n" +
+	"\x17GetCustomerByIdnRequest\x12\x10\n" +
+	"\x03idn\x18\x01 \x01(\tR\x03idn\"'\n" +
+	"\x13GetCustomersRequest\x12\x10\n" +
+	"\x03ids\x18\x01 \x03(\tR\x03ids\"t\n" +
+	"\x14GetCustomersResponse\x128\n" +
+	"\tcustomers\x18\x01 \x03(\v2\x1a.customer.CustomerResponseR\tcustomers\x12\"\n" +
+	"\rnot_found_ids\x18\x02 \x03(\tR\vnotFoundIds\"\xad\x01\n" +
+	"\x15UpdateCustomerRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x14\n" +
+	"\x05phone\x18\x03 \x01(\tR\x05phone\x12\x14\n" +
+	"\x05email\x18\x04 \x01(\tR\x05email\x12#\n" +
+	"\rlegal_address\x18\x05 \x01(\tR\flegalAddress\x12\x1f\n" +
+	"\vupdate_mask\x18\x06 \x03(\tR\n" +
+	"updateMask\"\x92\x01\n" +
+	"\x14ListCustomersRequest\x12*\n" +
+	"\x04type\x18\x01 \x01(\x0e2\x16.customer.CustomerTypeR\x04type\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x1b\n" +
+	"\tpage_size\x18\x03 \x01(\x05R\bpageSize\x12\x1d\n" +
+	"\n" +
+	"page_token\x18\x04 \x01(\tR\tpageToken\"y\n" +
+	"\x15ListCustomersResponse\x128\n" +
+	"\tcustomers\x18\x01 \x03(\v2\x1a.customer.CustomerResponseR\tcustomers\x12&\n" +
+	"\x0fnext_page_token\x18\x02 \x01(\tR\rnextPageToken*k\n" +
+	"\fCustomerType\x12\x1d\n" +
+	"\x19CUSTOMER_TYPE_UNSPECIFIED\x10\x00\x12\x1c\n" +
+	"\x18CUSTOMER_TYPE_INDIVIDUAL\x10\x01\x12\x1e\n" +
+	"\x1aCUSTOMER_TYPE_LEGAL_ENTITY\x10\x022\xba\x06\n" +
 	"\x0fCustomerService\x12M\n" +
-	"\x0eUpsertCustomer\x12\x1f.customer.UpsertCustomerRequest\x1a\x1a.customer.CustomerResponseB\x16Z\x14api/proto/customerpbb\x06proto3"
+	"\x0eUpsertCustomer\x12\x1f.customer.UpsertCustomerRequest\x1a\x1a.customer.CustomerResponse\x12e\n" +
+	"\x14BatchUpsertCustomers\x12%.customer.BatchUpsertCustomersRequest\x1a&.customer.BatchUpsertCustomersResponse\x12j\n" +
+	"\x15UpsertCustomersStream\x12%.customer.BatchUpsertCustomersRequest\x1a&.customer.BatchUpsertCustomersResponse(\x010\x01\x12q\n" +
+	"\x18CompensateUpsertCustomer\x12).customer.CompensateUpsertCustomerRequest\x1a*.customer.CompensateUpsertCustomerResponse\x12O\n" +
+	"\x0fGetCustomerById\x12 .customer.GetCustomerByIdRequest\x1a\x1a.customer.CustomerResponse\x12Q\n" +
+	"\x10GetCustomerByIdn\x12!.customer.GetCustomerByIdnRequest\x1a\x1a.customer.CustomerResponse\x12M\n" +
+	"\fGetCustomers\x12\x1d.customer.GetCustomersRequest\x1a\x1e.customer.GetCustomersResponse\x12M\n" +
+	"\x0eUpdateCustomer\x12\x1f.customer.UpdateCustomerRequest\x1a\x1a.customer.CustomerResponse\x12P\n" +
+	"\rListCustomers\x12\x1e.customer.ListCustomersRequest\x1a\x1f.customer.ListCustomersResponseB\x16Z\x14api/proto/customerpbb\x06proto3"
 
 var (
 	file_customer_proto_rawDescOnce sync.Once
@@ -152,19 +999,58 @@ func file_customer_proto_rawDescGZIP() []byte {
 	return file_customer_proto_rawDescData
 }
 
-var file_customer_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
+var file_customer_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_customer_proto_msgTypes = make([]protoimpl.MessageInfo, 15)
 var file_customer_proto_goTypes = []any{
-	(*UpsertCustomerRequest)(nil), // 0: customer.UpsertCustomerRequest
-	(*CustomerResponse)(nil),      // 1: customer.CustomerResponse
+	(CustomerType)(0),                        // 0: customer.CustomerType
+	(*UpsertCustomerRequest)(nil),            // 1: customer.UpsertCustomerRequest
+	(*CustomerResponse)(nil),                 // 2: customer.CustomerResponse
+	(*BatchUpsertCustomersRequest)(nil),      // 3: customer.BatchUpsertCustomersRequest
+	(*BatchUpsertCustomersResponse)(nil),     // 4: customer.BatchUpsertCustomersResponse
+	(*UpsertCustomerResult)(nil),             // 5: customer.UpsertCustomerResult
+	(*FieldViolation)(nil),                   // 6: customer.FieldViolation
+	(*CompensateUpsertCustomerRequest)(nil),  // 7: customer.CompensateUpsertCustomerRequest
+	(*CompensateUpsertCustomerResponse)(nil), // 8: customer.CompensateUpsertCustomerResponse
+	(*GetCustomerByIdRequest)(nil),           // 9: customer.GetCustomerByIdRequest
+	(*GetCustomerByIdnRequest)(nil),          // 10: customer.GetCustomerByIdnRequest
+	(*GetCustomersRequest)(nil),              // 11: customer.GetCustomersRequest
+	(*GetCustomersResponse)(nil),             // 12: customer.GetCustomersResponse
+	(*UpdateCustomerRequest)(nil),            // 13: customer.UpdateCustomerRequest
+	(*ListCustomersRequest)(nil),             // 14: customer.ListCustomersRequest
+	(*ListCustomersResponse)(nil),            // 15: customer.ListCustomersResponse
 }
 var file_customer_proto_depIdxs = []int32{
-	0, // 0: customer.CustomerService.UpsertCustomer:input_type -> customer.UpsertCustomerRequest
-	1, // 1: customer.CustomerService.UpsertCustomer:output_type -> customer.CustomerResponse
-	1, // [1:2] is the sub-list for method output_type
-	0, // [0:1] is the sub-list for method input_type
-	0, // [0:0] is the sub-list for extension type_name
-	0, // [0:0] is the sub-list for extension extendee
-	0, // [0:0] is the sub-list for field type_name
+	0,  // 0: customer.CustomerResponse.type:type_name -> customer.CustomerType
+	1,  // 1: customer.BatchUpsertCustomersRequest.items:type_name -> customer.UpsertCustomerRequest
+	5,  // 2: customer.BatchUpsertCustomersResponse.results:type_name -> customer.UpsertCustomerResult
+	2,  // 3: customer.UpsertCustomerResult.customer:type_name -> customer.CustomerResponse
+	6,  // 4: customer.UpsertCustomerResult.violations:type_name -> customer.FieldViolation
+	2,  // 5: customer.GetCustomersResponse.customers:type_name -> customer.CustomerResponse
+	0,  // 6: customer.ListCustomersRequest.type:type_name -> customer.CustomerType
+	2,  // 7: customer.ListCustomersResponse.customers:type_name -> customer.CustomerResponse
+	1,  // 8: customer.CustomerService.UpsertCustomer:input_type -> customer.UpsertCustomerRequest
+	3,  // 9: customer.CustomerService.BatchUpsertCustomers:input_type -> customer.BatchUpsertCustomersRequest
+	3,  // 10: customer.CustomerService.UpsertCustomersStream:input_type -> customer.BatchUpsertCustomersRequest
+	7,  // 11: customer.CustomerService.CompensateUpsertCustomer:input_type -> customer.CompensateUpsertCustomerRequest
+	9,  // 12: customer.CustomerService.GetCustomerById:input_type -> customer.GetCustomerByIdRequest
+	10, // 13: customer.CustomerService.GetCustomerByIdn:input_type -> customer.GetCustomerByIdnRequest
+	11, // 14: customer.CustomerService.GetCustomers:input_type -> customer.GetCustomersRequest
+	13, // 15: customer.CustomerService.UpdateCustomer:input_type -> customer.UpdateCustomerRequest
+	14, // 16: customer.CustomerService.ListCustomers:input_type -> customer.ListCustomersRequest
+	2,  // 17: customer.CustomerService.UpsertCustomer:output_type -> customer.CustomerResponse
+	4,  // 18: customer.CustomerService.BatchUpsertCustomers:output_type -> customer.BatchUpsertCustomersResponse
+	4,  // 19: customer.CustomerService.UpsertCustomersStream:output_type -> customer.BatchUpsertCustomersResponse
+	8,  // 20: customer.CustomerService.CompensateUpsertCustomer:output_type -> customer.CompensateUpsertCustomerResponse
+	2,  // 21: customer.CustomerService.GetCustomerById:output_type -> customer.CustomerResponse
+	2,  // 22: customer.CustomerService.GetCustomerByIdn:output_type -> customer.CustomerResponse
+	12, // 23: customer.CustomerService.GetCustomers:output_type -> customer.GetCustomersResponse
+	2,  // 24: customer.CustomerService.UpdateCustomer:output_type -> customer.CustomerResponse
+	15, // 25: customer.CustomerService.ListCustomers:output_type -> customer.ListCustomersResponse
+	17, // [17:26] is the sub-list for method output_type
+	8,  // [8:17] is the sub-list for method input_type
+	8,  // [8:8] is the sub-list for extension type_name
+	8,  // [8:8] is the sub-list for extension extendee
+	0,  // [0:8] is the sub-list for field type_name
 }
 
 func init() { file_customer_proto_init() }
@@ -177,13 +1063,14 @@ func file_customer_proto_init() {
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_customer_proto_rawDesc), len(file_customer_proto_rawDesc)),
-			NumEnums:      0,
-			NumMessages:   2,
+			NumEnums:      1,
+			NumMessages:   15,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
 		GoTypes:           file_customer_proto_goTypes,
 		DependencyIndexes: file_customer_proto_depIdxs,
+		EnumInfos:         file_customer_proto_enumTypes,
 		MessageInfos:      file_customer_proto_msgTypes,
 	}.Build()
 	File_customer_proto = out.File
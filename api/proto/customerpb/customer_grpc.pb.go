@@ -19,7 +19,15 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	CustomerService_UpsertCustomer_FullMethodName = "/customer.CustomerService/UpsertCustomer"
+	CustomerService_UpsertCustomer_FullMethodName           = "/customer.CustomerService/UpsertCustomer"
+	CustomerService_BatchUpsertCustomers_FullMethodName     = "/customer.CustomerService/BatchUpsertCustomers"
+	CustomerService_UpsertCustomersStream_FullMethodName    = "/customer.CustomerService/UpsertCustomersStream"
+	CustomerService_CompensateUpsertCustomer_FullMethodName = "/customer.CustomerService/CompensateUpsertCustomer"
+	CustomerService_GetCustomerById_FullMethodName          = "/customer.CustomerService/GetCustomerById"
+	CustomerService_GetCustomerByIdn_FullMethodName         = "/customer.CustomerService/GetCustomerByIdn"
+	CustomerService_GetCustomers_FullMethodName             = "/customer.CustomerService/GetCustomers"
+	CustomerService_UpdateCustomer_FullMethodName           = "/customer.CustomerService/UpdateCustomer"
+	CustomerService_ListCustomers_FullMethodName            = "/customer.CustomerService/ListCustomers"
 )
 
 // CustomerServiceClient is the client API for CustomerService service.
@@ -27,6 +35,14 @@ const (
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
 type CustomerServiceClient interface {
 	UpsertCustomer(ctx context.Context, in *UpsertCustomerRequest, opts ...grpc.CallOption) (*CustomerResponse, error)
+	BatchUpsertCustomers(ctx context.Context, in *BatchUpsertCustomersRequest, opts ...grpc.CallOption) (*BatchUpsertCustomersResponse, error)
+	UpsertCustomersStream(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[BatchUpsertCustomersRequest, BatchUpsertCustomersResponse], error)
+	CompensateUpsertCustomer(ctx context.Context, in *CompensateUpsertCustomerRequest, opts ...grpc.CallOption) (*CompensateUpsertCustomerResponse, error)
+	GetCustomerById(ctx context.Context, in *GetCustomerByIdRequest, opts ...grpc.CallOption) (*CustomerResponse, error)
+	GetCustomerByIdn(ctx context.Context, in *GetCustomerByIdnRequest, opts ...grpc.CallOption) (*CustomerResponse, error)
+	GetCustomers(ctx context.Context, in *GetCustomersRequest, opts ...grpc.CallOption) (*GetCustomersResponse, error)
+	UpdateCustomer(ctx context.Context, in *UpdateCustomerRequest, opts ...grpc.CallOption) (*CustomerResponse, error)
+	ListCustomers(ctx context.Context, in *ListCustomersRequest, opts ...grpc.CallOption) (*ListCustomersResponse, error)
 }
 
 type customerServiceClient struct {
@@ -47,11 +63,102 @@ func (c *customerServiceClient) UpsertCustomer(ctx context.Context, in *UpsertCu
 	return out, nil
 }
 
+func (c *customerServiceClient) BatchUpsertCustomers(ctx context.Context, in *BatchUpsertCustomersRequest, opts ...grpc.CallOption) (*BatchUpsertCustomersResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BatchUpsertCustomersResponse)
+	err := c.cc.Invoke(ctx, CustomerService_BatchUpsertCustomers_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *customerServiceClient) UpsertCustomersStream(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[BatchUpsertCustomersRequest, BatchUpsertCustomersResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &CustomerService_ServiceDesc.Streams[0], CustomerService_UpsertCustomersStream_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[BatchUpsertCustomersRequest, BatchUpsertCustomersResponse]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type CustomerService_UpsertCustomersStreamClient = grpc.BidiStreamingClient[BatchUpsertCustomersRequest, BatchUpsertCustomersResponse]
+
+func (c *customerServiceClient) CompensateUpsertCustomer(ctx context.Context, in *CompensateUpsertCustomerRequest, opts ...grpc.CallOption) (*CompensateUpsertCustomerResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CompensateUpsertCustomerResponse)
+	err := c.cc.Invoke(ctx, CustomerService_CompensateUpsertCustomer_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *customerServiceClient) GetCustomerById(ctx context.Context, in *GetCustomerByIdRequest, opts ...grpc.CallOption) (*CustomerResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CustomerResponse)
+	err := c.cc.Invoke(ctx, CustomerService_GetCustomerById_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *customerServiceClient) GetCustomerByIdn(ctx context.Context, in *GetCustomerByIdnRequest, opts ...grpc.CallOption) (*CustomerResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CustomerResponse)
+	err := c.cc.Invoke(ctx, CustomerService_GetCustomerByIdn_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *customerServiceClient) GetCustomers(ctx context.Context, in *GetCustomersRequest, opts ...grpc.CallOption) (*GetCustomersResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetCustomersResponse)
+	err := c.cc.Invoke(ctx, CustomerService_GetCustomers_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *customerServiceClient) UpdateCustomer(ctx context.Context, in *UpdateCustomerRequest, opts ...grpc.CallOption) (*CustomerResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CustomerResponse)
+	err := c.cc.Invoke(ctx, CustomerService_UpdateCustomer_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *customerServiceClient) ListCustomers(ctx context.Context, in *ListCustomersRequest, opts ...grpc.CallOption) (*ListCustomersResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListCustomersResponse)
+	err := c.cc.Invoke(ctx, CustomerService_ListCustomers_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // CustomerServiceServer is the server API for CustomerService service.
 // All implementations must embed UnimplementedCustomerServiceServer
 // for forward compatibility.
 type CustomerServiceServer interface {
 	UpsertCustomer(context.Context, *UpsertCustomerRequest) (*CustomerResponse, error)
+	BatchUpsertCustomers(context.Context, *BatchUpsertCustomersRequest) (*BatchUpsertCustomersResponse, error)
+	UpsertCustomersStream(grpc.BidiStreamingServer[BatchUpsertCustomersRequest, BatchUpsertCustomersResponse]) error
+	CompensateUpsertCustomer(context.Context, *CompensateUpsertCustomerRequest) (*CompensateUpsertCustomerResponse, error)
+	GetCustomerById(context.Context, *GetCustomerByIdRequest) (*CustomerResponse, error)
+	GetCustomerByIdn(context.Context, *GetCustomerByIdnRequest) (*CustomerResponse, error)
+	GetCustomers(context.Context, *GetCustomersRequest) (*GetCustomersResponse, error)
+	UpdateCustomer(context.Context, *UpdateCustomerRequest) (*CustomerResponse, error)
+	ListCustomers(context.Context, *ListCustomersRequest) (*ListCustomersResponse, error)
 	mustEmbedUnimplementedCustomerServiceServer()
 }
 
@@ -65,6 +172,30 @@ type UnimplementedCustomerServiceServer struct{}
 func (UnimplementedCustomerServiceServer) UpsertCustomer(context.Context, *UpsertCustomerRequest) (*CustomerResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "method UpsertCustomer not implemented")
 }
+func (UnimplementedCustomerServiceServer) BatchUpsertCustomers(context.Context, *BatchUpsertCustomersRequest) (*BatchUpsertCustomersResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method BatchUpsertCustomers not implemented")
+}
+func (UnimplementedCustomerServiceServer) UpsertCustomersStream(grpc.BidiStreamingServer[BatchUpsertCustomersRequest, BatchUpsertCustomersResponse]) error {
+	return status.Error(codes.Unimplemented, "method UpsertCustomersStream not implemented")
+}
+func (UnimplementedCustomerServiceServer) CompensateUpsertCustomer(context.Context, *CompensateUpsertCustomerRequest) (*CompensateUpsertCustomerResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method CompensateUpsertCustomer not implemented")
+}
+func (UnimplementedCustomerServiceServer) GetCustomerById(context.Context, *GetCustomerByIdRequest) (*CustomerResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetCustomerById not implemented")
+}
+func (UnimplementedCustomerServiceServer) GetCustomerByIdn(context.Context, *GetCustomerByIdnRequest) (*CustomerResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetCustomerByIdn not implemented")
+}
+func (UnimplementedCustomerServiceServer) GetCustomers(context.Context, *GetCustomersRequest) (*GetCustomersResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetCustomers not implemented")
+}
+func (UnimplementedCustomerServiceServer) UpdateCustomer(context.Context, *UpdateCustomerRequest) (*CustomerResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method UpdateCustomer not implemented")
+}
+func (UnimplementedCustomerServiceServer) ListCustomers(context.Context, *ListCustomersRequest) (*ListCustomersResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListCustomers not implemented")
+}
 func (UnimplementedCustomerServiceServer) mustEmbedUnimplementedCustomerServiceServer() {}
 func (UnimplementedCustomerServiceServer) testEmbeddedByValue()                         {}
 
@@ -104,6 +235,139 @@ func _CustomerService_UpsertCustomer_Handler(srv interface{}, ctx context.Contex
 	return interceptor(ctx, in, info, handler)
 }
 
+func _CustomerService_BatchUpsertCustomers_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BatchUpsertCustomersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CustomerServiceServer).BatchUpsertCustomers(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CustomerService_BatchUpsertCustomers_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CustomerServiceServer).BatchUpsertCustomers(ctx, req.(*BatchUpsertCustomersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CustomerService_UpsertCustomersStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(CustomerServiceServer).UpsertCustomersStream(&grpc.GenericServerStream[BatchUpsertCustomersRequest, BatchUpsertCustomersResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type CustomerService_UpsertCustomersStreamServer = grpc.BidiStreamingServer[BatchUpsertCustomersRequest, BatchUpsertCustomersResponse]
+
+func _CustomerService_CompensateUpsertCustomer_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CompensateUpsertCustomerRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CustomerServiceServer).CompensateUpsertCustomer(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CustomerService_CompensateUpsertCustomer_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CustomerServiceServer).CompensateUpsertCustomer(ctx, req.(*CompensateUpsertCustomerRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CustomerService_GetCustomerById_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetCustomerByIdRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CustomerServiceServer).GetCustomerById(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CustomerService_GetCustomerById_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CustomerServiceServer).GetCustomerById(ctx, req.(*GetCustomerByIdRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CustomerService_GetCustomerByIdn_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetCustomerByIdnRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CustomerServiceServer).GetCustomerByIdn(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CustomerService_GetCustomerByIdn_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CustomerServiceServer).GetCustomerByIdn(ctx, req.(*GetCustomerByIdnRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CustomerService_GetCustomers_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetCustomersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CustomerServiceServer).GetCustomers(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CustomerService_GetCustomers_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CustomerServiceServer).GetCustomers(ctx, req.(*GetCustomersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CustomerService_UpdateCustomer_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateCustomerRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CustomerServiceServer).UpdateCustomer(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CustomerService_UpdateCustomer_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CustomerServiceServer).UpdateCustomer(ctx, req.(*UpdateCustomerRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CustomerService_ListCustomers_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListCustomersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CustomerServiceServer).ListCustomers(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CustomerService_ListCustomers_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CustomerServiceServer).ListCustomers(ctx, req.(*ListCustomersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // CustomerService_ServiceDesc is the grpc.ServiceDesc for CustomerService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -115,7 +379,42 @@ var CustomerService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "UpsertCustomer",
 			Handler:    _CustomerService_UpsertCustomer_Handler,
 		},
+		{
+			MethodName: "BatchUpsertCustomers",
+			Handler:    _CustomerService_BatchUpsertCustomers_Handler,
+		},
+		{
+			MethodName: "CompensateUpsertCustomer",
+			Handler:    _CustomerService_CompensateUpsertCustomer_Handler,
+		},
+		{
+			MethodName: "GetCustomerById",
+			Handler:    _CustomerService_GetCustomerById_Handler,
+		},
+		{
+			MethodName: "GetCustomerByIdn",
+			Handler:    _CustomerService_GetCustomerByIdn_Handler,
+		},
+		{
+			MethodName: "GetCustomers",
+			Handler:    _CustomerService_GetCustomers_Handler,
+		},
+		{
+			MethodName: "UpdateCustomer",
+			Handler:    _CustomerService_UpdateCustomer_Handler,
+		},
+		{
+			MethodName: "ListCustomers",
+			Handler:    _CustomerService_ListCustomers_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "UpsertCustomersStream",
+			Handler:       _CustomerService_UpsertCustomersStream_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "customer.proto",
 }
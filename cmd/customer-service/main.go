@@ -55,6 +55,8 @@ func main() {
 
 	grpcServer := grpc.NewServer(
 		grpc.StatsHandler(otelgrpc.NewServerHandler()),
+		grpc.ChainUnaryInterceptor(cgrpc.UnaryErrorInterceptor()),
+		grpc.ChainStreamInterceptor(cgrpc.StreamErrorInterceptor()),
 	)
 
 	pb.RegisterCustomerServiceServer(grpcServer, cgrpc.New(svc))
@@ -2,10 +2,12 @@ package main
 
 import (
 	"context"
+	"fmt"
 	"log/slog"
 	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
 	"syscall"
 	"time"
 
@@ -16,7 +18,10 @@ import (
 	"google.golang.org/grpc/credentials/insecure"
 
 	pb "transline.kz/api/proto/customerpb"
+	"transline.kz/internal/location"
 	"transline.kz/internal/otel"
+	"transline.kz/internal/outbox"
+	"transline.kz/internal/ratelimit"
 	shgrpc "transline.kz/internal/shipment/grpc"
 	shhttp "transline.kz/internal/shipment/http"
 	"transline.kz/internal/shipment/repo"
@@ -53,6 +58,15 @@ func main() {
 	}
 	cancel()
 
+	// Разовые команды обслуживания: shipment-service <command>
+	if len(os.Args) > 1 {
+		if err := runCommand(db, os.Args[1]); err != nil {
+			slog.Error("command failed", "command", os.Args[1], "err", err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	// gRPC client (через Envoy, с OTel StatsHandler)
 	conn, err := grpc.Dial(
 		"envoy:9090",
@@ -71,18 +85,253 @@ func main() {
 
 	// Application layers
 	repository := repo.New(db)
-	service := shservice.New(repository, customerClient)
+	service := shservice.New(repository, customerClient, location.Default(), shservice.Config{
+		QuoteTTL:              durationEnv("QUOTE_TTL", shservice.DefaultQuoteTTL),
+		TrackingURL:           os.Getenv("PUBLIC_TRACKING_URL"),
+		CancelGracePeriod:     durationEnv("CANCEL_GRACE_PERIOD", shservice.DefaultCancelGracePeriod),
+		WebhookTimeout:        durationEnv("WEBHOOK_TIMEOUT", shservice.DefaultWebhookTimeout),
+		WebhookMaxAttempts:    intEnv("WEBHOOK_MAX_ATTEMPTS", shservice.DefaultWebhookMaxAttempts),
+		AllowInsecureWebhooks: os.Getenv("WEBHOOK_ALLOW_HTTP") == "true",
+		AllowPrivateWebhooks:  os.Getenv("WEBHOOK_ALLOW_PRIVATE") == "true",
+	})
 	handler := shhttp.New(service)
 
+	// Фоновые задачи: очистка истёкших Idempotency-Key и компенсация
+	// зависших saga
+	idempotencyTTL := durationEnv("IDEMPOTENCY_KEY_TTL", 24*time.Hour)
+	bgCtx, stopBackground := context.WithCancel(context.Background())
+	defer stopBackground()
+	go runPeriodically(bgCtx, time.Hour, "purge idempotency keys", func(ctx context.Context) (int64, error) {
+		return service.PurgeExpiredIdempotencyKeys(ctx)
+	})
+	trackLimiter := ratelimit.New(
+		intEnv("TRACK_RATE_LIMIT", 30),
+		durationEnv("TRACK_RATE_WINDOW", time.Minute),
+	)
+	go runPeriodically(bgCtx, time.Minute, "prune track rate limits", func(context.Context) (int64, error) {
+		return int64(trackLimiter.Prune()), nil
+	})
+	sagaStaleAfter := durationEnv("SAGA_STALE_AFTER", 5*time.Minute)
+	go runPeriodically(bgCtx, time.Minute, "recover shipment sagas", func(ctx context.Context) (int64, error) {
+		n, err := service.RecoverSagas(ctx, sagaStaleAfter, 100)
+		return int64(n), err
+	})
+
+	// Outbox: события shipment-service и customer-service (общая БД)
+	// публикует relay этого сервиса в шину; на шину подписаны вебхуки и,
+	// если задан, внешний брокер
+	bus := outbox.NewBus()
+	bus.Subscribe(outbox.ShipmentCreated, service.EnqueueWebhooks)
+	bus.Subscribe(outbox.ShipmentStatusChanged, service.EnqueueWebhooks)
+	broker, closeBroker, err := newBroker()
+	if err != nil {
+		slog.Error("outbox publisher error", "err", err)
+		os.Exit(1)
+	}
+	if broker != nil {
+		defer closeBroker()
+		bus.Subscribe("", broker.Publish)
+	}
+	relay := outbox.NewRelay(db, bus, outbox.RelayConfig{
+		BatchSize:   intEnv("OUTBOX_BATCH_SIZE", outbox.DefaultBatchSize),
+		MaxAttempts: intEnv("OUTBOX_MAX_ATTEMPTS", outbox.DefaultMaxAttempts),
+		ClaimLease:  durationEnv("OUTBOX_CLAIM_LEASE", outbox.DefaultClaimLease),
+	})
+	go runPeriodically(bgCtx, durationEnv("OUTBOX_POLL_INTERVAL", time.Second), "relay outbox", relay.RelayOnce)
+	outboxRetention := durationEnv("OUTBOX_RETENTION", 7*24*time.Hour)
+	go runPeriodically(bgCtx, time.Hour, "purge published outbox", func(ctx context.Context) (int64, error) {
+		return relay.PurgePublished(ctx, outboxRetention)
+	})
+	go runPeriodically(bgCtx, durationEnv("WEBHOOK_POLL_INTERVAL", 5*time.Second), "deliver webhooks", func(ctx context.Context) (int64, error) {
+		return service.DeliverWebhooks(ctx, 100)
+	})
+	webhookRetention := durationEnv("WEBHOOK_DELIVERY_RETENTION", 30*24*time.Hour)
+	go runPeriodically(bgCtx, time.Hour, "purge webhook deliveries", func(ctx context.Context) (int64, error) {
+		return service.PurgeWebhookDeliveries(ctx, webhookRetention)
+	})
+
 	// HTTP router
 	mux := http.NewServeMux()
 	mux.Handle(
-		"/api/v1/shipments",
+		"POST /api/v1/shipments",
 		otelhttp.NewHandler(
-			http.HandlerFunc(handler.Create),
+			handler.Idempotent(idempotencyTTL, handler.Create),
 			"CreateShipment",
 		),
 	)
+	mux.Handle(
+		"POST /api/v1/shipments:batch",
+		otelhttp.NewHandler(
+			handler.Idempotent(idempotencyTTL, handler.Batch),
+			"CreateShipmentsBatch",
+		),
+	)
+	mux.Handle(
+		"POST /api/v1/shipments/import",
+		otelhttp.NewHandler(
+			handler.Idempotent(idempotencyTTL, handler.Import),
+			"ImportShipments",
+		),
+	)
+	mux.Handle(
+		"GET /api/v1/shipments",
+		otelhttp.NewHandler(
+			http.HandlerFunc(handler.List),
+			"ListShipments",
+		),
+	)
+	mux.Handle(
+		"GET /api/v1/shipments/export",
+		otelhttp.NewHandler(
+			http.HandlerFunc(handler.Export),
+			"ExportShipments",
+		),
+	)
+	mux.Handle(
+		"GET /api/v1/shipments/{id}",
+		otelhttp.NewHandler(
+			http.HandlerFunc(handler.Get),
+			"GetShipment",
+		),
+	)
+	mux.Handle(
+		"PATCH /api/v1/shipments/{id}",
+		otelhttp.NewHandler(
+			http.HandlerFunc(handler.Patch),
+			"PatchShipment",
+		),
+	)
+	mux.Handle(
+		"POST /api/v1/shipments/{id}/transitions",
+		otelhttp.NewHandler(
+			http.HandlerFunc(handler.Transition),
+			"TransitionShipment",
+		),
+	)
+	mux.Handle(
+		"POST /api/v1/shipments/{id}/cancel",
+		otelhttp.NewHandler(
+			http.HandlerFunc(handler.Cancel),
+			"CancelShipment",
+		),
+	)
+	mux.Handle(
+		"GET /api/v1/shipments/{id}/events",
+		otelhttp.NewHandler(
+			http.HandlerFunc(handler.Events),
+			"ListShipmentEvents",
+		),
+	)
+	mux.Handle(
+		"POST /api/v1/shipments/{id}/events",
+		otelhttp.NewHandler(
+			http.HandlerFunc(handler.AddEvent),
+			"AddShipmentEvent",
+		),
+	)
+	mux.Handle(
+		"GET /api/v1/shipments/{id}/label",
+		otelhttp.NewHandler(
+			http.HandlerFunc(handler.Label),
+			"ShipmentLabel",
+		),
+	)
+	mux.Handle(
+		"GET /api/v1/shipments/{id}/waybill",
+		otelhttp.NewHandler(
+			http.HandlerFunc(handler.Waybill),
+			"ShipmentWaybill",
+		),
+	)
+
+	mux.Handle(
+		"POST /api/v1/webhooks",
+		otelhttp.NewHandler(
+			http.HandlerFunc(handler.CreateWebhook),
+			"CreateWebhook",
+		),
+	)
+	mux.Handle(
+		"GET /api/v1/webhooks",
+		otelhttp.NewHandler(
+			http.HandlerFunc(handler.ListWebhooks),
+			"ListWebhooks",
+		),
+	)
+	mux.Handle(
+		"GET /api/v1/webhooks/{id}",
+		otelhttp.NewHandler(
+			http.HandlerFunc(handler.GetWebhook),
+			"GetWebhook",
+		),
+	)
+	mux.Handle(
+		"DELETE /api/v1/webhooks/{id}",
+		otelhttp.NewHandler(
+			http.HandlerFunc(handler.DeleteWebhook),
+			"DeleteWebhook",
+		),
+	)
+	mux.Handle(
+		"GET /api/v1/webhooks/{id}/deliveries",
+		otelhttp.NewHandler(
+			http.HandlerFunc(handler.ListDeliveries),
+			"ListWebhookDeliveries",
+		),
+	)
+	mux.Handle(
+		"GET /api/v1/webhooks/{id}/deliveries/{deliveryId}",
+		otelhttp.NewHandler(
+			http.HandlerFunc(handler.GetDelivery),
+			"GetWebhookDelivery",
+		),
+	)
+	mux.Handle(
+		"POST /api/v1/webhooks/{id}/deliveries/{deliveryId}/redeliver",
+		otelhttp.NewHandler(
+			http.HandlerFunc(handler.Redeliver),
+			"RedeliverWebhook",
+		),
+	)
+
+	mux.Handle(
+		"GET /api/v1/locations",
+		otelhttp.NewHandler(
+			http.HandlerFunc(handler.SearchLocations),
+			"SearchLocations",
+		),
+	)
+	mux.Handle(
+		"GET /api/v1/locations/{code}",
+		otelhttp.NewHandler(
+			http.HandlerFunc(handler.GetLocation),
+			"GetLocation",
+		),
+	)
+
+	mux.Handle(
+		"POST /api/v1/quotes",
+		otelhttp.NewHandler(
+			http.HandlerFunc(handler.CreateQuote),
+			"CreateQuote",
+		),
+	)
+	mux.Handle(
+		"GET /api/v1/quotes/{id}",
+		otelhttp.NewHandler(
+			http.HandlerFunc(handler.GetQuote),
+			"GetQuote",
+		),
+	)
+
+	// Публичное отслеживание: без авторизации, с лимитом по IP
+	mux.Handle(
+		"GET /api/v1/track/{trackingNumber}",
+		otelhttp.NewHandler(
+			handler.RateLimited(trackLimiter, trustedProxyHops(), handler.Track),
+			"TrackShipment",
+		),
+	)
 
 	// HTTP server with graceful shutdown
 	server := &http.Server{
@@ -110,3 +359,111 @@ func main() {
 	}
 	slog.Info("shipment-service stopped")
 }
+
+// newBroker — внешний получатель outbox по OUTBOX_PUBLISHER: nats или file;
+// для bus (по умолчанию) события получают только подписчики внутри процесса
+func newBroker() (outbox.Publisher, func(), error) {
+	switch kind := os.Getenv("OUTBOX_PUBLISHER"); kind {
+	case "", "bus":
+		return nil, nil, nil
+	case "nats":
+		prefix := os.Getenv("OUTBOX_SUBJECT_PREFIX")
+		if prefix == "" {
+			prefix = "transline.events"
+		}
+		n, err := outbox.NewNATS(os.Getenv("NATS_URL"), prefix)
+		if err != nil {
+			return nil, nil, err
+		}
+		return n, func() { n.Close() }, nil
+	case "file":
+		f, err := outbox.OpenFileSink(os.Getenv("OUTBOX_FILE"))
+		if err != nil {
+			return nil, nil, err
+		}
+		return f, func() { f.Close() }, nil
+	default:
+		return nil, nil, fmt.Errorf("unknown OUTBOX_PUBLISHER %q: want bus, nats or file", kind)
+	}
+}
+
+func durationEnv(name string, def time.Duration) time.Duration {
+	v := os.Getenv(name)
+	if v == "" {
+		return def
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil || d <= 0 {
+		slog.Warn("invalid duration in env, using default", "name", name, "value", v, "default", def)
+		return def
+	}
+	return d
+}
+
+func intEnv(name string, def int) int {
+	v := os.Getenv(name)
+	if v == "" {
+		return def
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n <= 0 {
+		slog.Warn("invalid integer in env, using default", "name", name, "value", v, "default", def)
+		return def
+	}
+	return n
+}
+
+// trustedProxyHops — TRUSTED_PROXY_HOPS, число доверенных прокси перед сервисом
+// (в docker-compose это Envoy); 0 — X-Forwarded-For не учитывается
+func trustedProxyHops() int {
+	v := os.Getenv("TRUSTED_PROXY_HOPS")
+	if v == "" {
+		return 0
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 0 {
+		slog.Warn("invalid integer in env, using default", "name", "TRUSTED_PROXY_HOPS", "value", v, "default", 0)
+		return 0
+	}
+	return n
+}
+
+// runCommand выполняет разовую команду и завершается; gRPC-клиент и HTTP-сервер
+// не поднимаются
+func runCommand(db *pgxpool.Pool, command string) error {
+	switch command {
+	case "normalize-cities":
+		// коды городов, сохранённые до появления справочника; повторный запуск
+		// ничего не меняет
+		service := shservice.New(repo.New(db), nil, location.Default(), shservice.Config{})
+		n, err := service.NormalizeStoredCities(context.Background())
+		if err != nil {
+			return err
+		}
+		slog.Info("city codes normalized", "shipments", n)
+		return nil
+	default:
+		return fmt.Errorf("unknown command %q", command)
+	}
+}
+
+// runPeriodically вызывает job каждые every до отмены ctx и логирует результат
+func runPeriodically(ctx context.Context, every time.Duration, name string, job func(context.Context) (int64, error)) {
+	ticker := time.NewTicker(every)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			n, err := job(ctx)
+			if err != nil {
+				slog.Error("background job failed", "job", name, "err", err)
+				continue
+			}
+			if n > 0 {
+				slog.Info("background job done", "job", name, "count", n)
+			}
+		}
+	}
+}
@@ -0,0 +1,108 @@
+// Package barcode — кодирование штрихкодов Code 128 и QR без внешних
+// зависимостей. Результат — модули (полосы, клетки), отрисовка на стороне
+// вызывающего.
+package barcode
+
+import (
+	"errors"
+	"fmt"
+)
+
+var ErrUnsupported = errors.New("barcode: unsupported character")
+
+// code128Patterns — ширины полос и пробелов символов 0..106 (106 — Stop)
+var code128Patterns = [107]string{
+	"212222", "222122", "222221", "121223", "121322", "131222", "122213", "122312", "132212", "221213",
+	"221312", "231212", "112232", "122132", "122231", "113222", "123122", "123221", "223211", "221132",
+	"221231", "213212", "223112", "312131", "311222", "321122", "321221", "312212", "322112", "322211",
+	"212123", "212321", "232121", "111323", "131123", "131321", "112313", "132113", "132311", "211313",
+	"231113", "231311", "112133", "112331", "132131", "113123", "113321", "133121", "313121", "211331",
+	"231131", "213113", "213311", "213131", "311123", "311321", "331121", "312113", "312311", "332111",
+	"314111", "221411", "431111", "111224", "111422", "121124", "121421", "141122", "141221", "112214",
+	"112412", "122114", "122411", "142112", "142211", "241211", "221114", "413111", "241112", "134111",
+	"111242", "121142", "121241", "114212", "124112", "124211", "411212", "421112", "421211", "212141",
+	"214121", "412121", "111143", "111341", "131141", "114113", "114311", "411113", "411311", "113141",
+	"114131", "311141", "411131", "211412", "211214", "211232", "2331112",
+}
+
+const (
+	code128CodeC  = 99
+	code128CodeB  = 100
+	code128StartB = 104
+	code128StartC = 105
+	code128Stop   = 106
+)
+
+// Code128 кодирует печатные ASCII-символы (32..126) наборами B и C: серии
+// из четырёх и более цифр идут парами в наборе C, что делает трек-номер
+// заметно короче. Возвращает модули слева направо, true — полоса; тихие зоны
+// не включены (нужно не меньше 10 модулей с каждой стороны).
+func Code128(s string) ([]bool, error) {
+	if s == "" {
+		return nil, fmt.Errorf("%w: empty input", ErrUnsupported)
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] < 32 || s[i] > 126 {
+			return nil, fmt.Errorf("%w %q at %d", ErrUnsupported, s[i], i)
+		}
+	}
+
+	var codes []int
+	// с набора C начинаем, если начальная серия цифр чётная и длинная (или это вся строка)
+	n := digitRun(s, 0)
+	setC := n%2 == 0 && (n >= 4 || n == len(s))
+	if setC {
+		codes = append(codes, code128StartC)
+	} else {
+		codes = append(codes, code128StartB)
+	}
+	for i := 0; i < len(s); {
+		if setC {
+			if digitRun(s, i) >= 2 {
+				codes = append(codes, int(s[i]-'0')*10+int(s[i+1]-'0'))
+				i += 2
+				continue
+			}
+			codes = append(codes, code128CodeB)
+			setC = false
+			continue
+		}
+		if n := digitRun(s, i); n >= 4 {
+			// нечётную серию начинаем одной цифрой в наборе B
+			if n%2 == 1 {
+				codes = append(codes, int(s[i])-32)
+				i++
+			}
+			codes = append(codes, code128CodeC)
+			setC = true
+			continue
+		}
+		codes = append(codes, int(s[i])-32)
+		i++
+	}
+
+	sum := codes[0]
+	for i, c := range codes[1:] {
+		sum += (i + 1) * c
+	}
+	codes = append(codes, sum%103, code128Stop)
+
+	var modules []bool
+	for _, c := range codes {
+		for i, w := range code128Patterns[c] {
+			for range int(w - '0') {
+				modules = append(modules, i%2 == 0)
+			}
+		}
+	}
+	return modules, nil
+}
+
+// digitRun — длина серии цифр, начинающейся с позиции i
+func digitRun(s string, i int) int {
+	n := 0
+	for i+n < len(s) && s[i+n] >= '0' && s[i+n] <= '9' {
+		n++
+	}
+	return n
+}
@@ -0,0 +1,101 @@
+package barcode
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+// code128Symbols раскладывает модули обратно в номера символов по таблице ширин
+func code128Symbols(t *testing.T, modules []bool) []int {
+	t.Helper()
+	var widths []byte
+	for i := 0; i < len(modules); {
+		j := i
+		for j < len(modules) && modules[j] == modules[i] {
+			j++
+		}
+		widths = append(widths, byte('0'+j-i))
+		i = j
+	}
+
+	index := make(map[string]int, len(code128Patterns))
+	for c, p := range code128Patterns {
+		index[p] = c
+	}
+	var symbols []int
+	for i := 0; i < len(widths); {
+		n := 6
+		if len(widths)-i == 7 {
+			n = 7 // Stop — последняя полоса лишняя
+		}
+		c, ok := index[string(widths[i:i+n])]
+		if !ok {
+			t.Fatalf("no Code 128 symbol for widths %s at run %d", widths[i:i+n], i)
+		}
+		symbols = append(symbols, c)
+		i += n
+	}
+	return symbols
+}
+
+func TestCode128(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []int
+	}{
+		// Start B, данные, контрольный (104 + 48·1 + 42·2 + … + 35·7) mod 103 = 55, Stop
+		{"PJJ123C", []int{104, 48, 42, 42, 17, 18, 19, 35, 55, 106}},
+		{"12345678", []int{105, 12, 34, 56, 78, 47, 106}},
+		// нечётная серия цифр: первая цифра в наборе B, остальные парами в C
+		{"TL2610180000014", []int{104, 52, 44, 18, 99, 61, 1, 80, 0, 0, 14, 57, 106}},
+		{"1234a", []int{105, 12, 34, 100, 65, 24, 106}},
+		// короткая серия цифр не стоит переключения
+		{"123", []int{104, 17, 18, 19, 8, 106}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			modules, err := Code128(tt.in)
+			if err != nil {
+				t.Fatalf("Code128(%q) error = %v", tt.in, err)
+			}
+			if want := 11*(len(tt.want)-1) + 13; len(modules) != want {
+				t.Errorf("len(modules) = %d, want %d", len(modules), want)
+			}
+			if !modules[0] || !modules[len(modules)-1] {
+				t.Error("symbol must start and end with a bar")
+			}
+			if got := code128Symbols(t, modules); !slices.Equal(got, tt.want) {
+				t.Errorf("symbols = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCode128Unsupported(t *testing.T) {
+	for _, in := range []string{"", "TL\n1", "ТЛ1"} {
+		if _, err := Code128(in); !errors.Is(err, ErrUnsupported) {
+			t.Errorf("Code128(%q) error = %v, want %v", in, err, ErrUnsupported)
+		}
+	}
+}
+
+// Каждый символ — 11 модулей (Stop — 13), сумма ширин полос чётная
+func TestCode128Patterns(t *testing.T) {
+	for c, p := range code128Patterns {
+		total, bars := 0, 0
+		for i, w := range p {
+			total += int(w - '0')
+			if i%2 == 0 {
+				bars += int(w - '0')
+			}
+		}
+		want := 11
+		if c == code128Stop {
+			want = 13
+		}
+		if total != want || bars%2 != 0 {
+			t.Errorf("pattern %d (%s): width %d, bars %d", c, p, total, bars)
+		}
+	}
+}
@@ -0,0 +1,450 @@
+package barcode
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrTooLong — данные не помещаются в поддерживаемые версии QR
+var ErrTooLong = errors.New("barcode: data too long for QR code")
+
+// QR — матрица модулей QR-кода; Dark(x, y) — тёмный ли модуль.
+// Тихая зона (4 модуля) не входит в Size.
+type QR struct {
+	Size    int
+	Version int
+	modules [][]bool
+}
+
+func (q *QR) Dark(x, y int) bool {
+	return q.modules[y][x]
+}
+
+// qrVersion — блоки уровня коррекции M для версий 1–10
+type qrVersion struct {
+	ecPerBlock int
+	// группы блоков: количество и число байт данных в блоке
+	blocks1, data1 int
+	blocks2, data2 int
+	alignment      []int
+}
+
+var qrVersionsM = []qrVersion{
+	1:  {10, 1, 16, 0, 0, nil},
+	2:  {16, 1, 28, 0, 0, []int{6, 18}},
+	3:  {26, 1, 44, 0, 0, []int{6, 22}},
+	4:  {18, 2, 32, 0, 0, []int{6, 26}},
+	5:  {24, 2, 43, 0, 0, []int{6, 30}},
+	6:  {16, 4, 27, 0, 0, []int{6, 34}},
+	7:  {18, 4, 31, 0, 0, []int{6, 22, 38}},
+	8:  {22, 2, 38, 2, 39, []int{6, 24, 42}},
+	9:  {22, 3, 36, 2, 37, []int{6, 26, 46}},
+	10: {26, 4, 43, 1, 44, []int{6, 28, 50}},
+}
+
+func (v qrVersion) dataBytes() int {
+	return v.blocks1*v.data1 + v.blocks2*v.data2
+}
+
+// EncodeQR кодирует байты в QR-код в байтовом режиме с уровнем коррекции M
+// (восстанавливается ~15% повреждённых модулей) — версии 1–10, до 213 байт.
+// Маска выбирается по штрафным правилам ISO/IEC 18004.
+func EncodeQR(data []byte) (*QR, error) {
+	version := 0
+	for v := 1; v < len(qrVersionsM); v++ {
+		countBits := 8
+		if v >= 10 {
+			countBits = 16
+		}
+		if 4+countBits+8*len(data) <= 8*qrVersionsM[v].dataBytes() {
+			version = v
+			break
+		}
+	}
+	if version == 0 {
+		return nil, fmt.Errorf("%w: %d bytes", ErrTooLong, len(data))
+	}
+
+	q := newQRBuilder(version)
+	q.drawFunctionPatterns()
+	q.drawCodewords(q.codewords(data))
+
+	best, bestPenalty := -1, 0
+	for mask := range 8 {
+		q.applyMask(mask)
+		q.drawFormatBits(mask)
+		if p := q.penalty(); best < 0 || p < bestPenalty {
+			best, bestPenalty = mask, p
+		}
+		q.applyMask(mask) // XOR — повторное применение снимает маску
+	}
+	q.applyMask(best)
+	q.drawFormatBits(best)
+	return &QR{Size: q.size, Version: version, modules: q.modules}, nil
+}
+
+type qrBuilder struct {
+	version    int
+	size       int
+	modules    [][]bool
+	isFunction [][]bool
+}
+
+func newQRBuilder(version int) *qrBuilder {
+	size := 17 + 4*version
+	q := &qrBuilder{version: version, size: size}
+	q.modules = make([][]bool, size)
+	q.isFunction = make([][]bool, size)
+	for i := range size {
+		q.modules[i] = make([]bool, size)
+		q.isFunction[i] = make([]bool, size)
+	}
+	return q
+}
+
+func (q *qrBuilder) setFunction(x, y int, dark bool) {
+	q.modules[y][x] = dark
+	q.isFunction[y][x] = true
+}
+
+func (q *qrBuilder) drawFunctionPatterns() {
+	for i := range q.size {
+		q.setFunction(6, i, i%2 == 0)
+		q.setFunction(i, 6, i%2 == 0)
+	}
+
+	q.drawFinder(3, 3)
+	q.drawFinder(q.size-4, 3)
+	q.drawFinder(3, q.size-4)
+
+	align := qrVersionsM[q.version].alignment
+	last := len(align) - 1
+	for i, ax := range align {
+		for j, ay := range align {
+			// углы заняты поисковыми узорами
+			if i == 0 && j == 0 || i == 0 && j == last || i == last && j == 0 {
+				continue
+			}
+			q.drawAlignment(ax, ay)
+		}
+	}
+
+	// резервируем место под формат (запишется после выбора маски) и версию
+	q.drawFormatBits(0)
+	q.drawVersion()
+}
+
+// drawFinder — поисковый узор 7×7 с белой рамкой-разделителем
+func (q *qrBuilder) drawFinder(cx, cy int) {
+	for dy := -4; dy <= 4; dy++ {
+		for dx := -4; dx <= 4; dx++ {
+			x, y := cx+dx, cy+dy
+			if x < 0 || x >= q.size || y < 0 || y >= q.size {
+				continue
+			}
+			d := max(abs(dx), abs(dy))
+			q.setFunction(x, y, d != 2 && d != 4)
+		}
+	}
+}
+
+func (q *qrBuilder) drawAlignment(cx, cy int) {
+	for dy := -2; dy <= 2; dy++ {
+		for dx := -2; dx <= 2; dx++ {
+			q.setFunction(cx+dx, cy+dy, max(abs(dx), abs(dy)) != 1)
+		}
+	}
+}
+
+// drawFormatBits — уровень коррекции и маска (BCH(15,5)), две копии
+func (q *qrBuilder) drawFormatBits(mask int) {
+	const levelM = 0
+	data := levelM<<3 | mask
+	rem := data
+	for range 10 {
+		rem = rem<<1 ^ (rem>>9)*0x537
+	}
+	bits := (data<<10 | rem) ^ 0x5412
+
+	for i := 0; i <= 5; i++ {
+		q.setFunction(8, i, bit(bits, i))
+	}
+	q.setFunction(8, 7, bit(bits, 6))
+	q.setFunction(8, 8, bit(bits, 7))
+	q.setFunction(7, 8, bit(bits, 8))
+	for i := 9; i < 15; i++ {
+		q.setFunction(14-i, 8, bit(bits, i))
+	}
+
+	for i := range 8 {
+		q.setFunction(q.size-1-i, 8, bit(bits, i))
+	}
+	for i := 8; i < 15; i++ {
+		q.setFunction(8, q.size-15+i, bit(bits, i))
+	}
+	q.setFunction(8, q.size-8, true) // всегда тёмный модуль
+}
+
+// drawVersion — номер версии (BCH(18,6)) для версий 7+
+func (q *qrBuilder) drawVersion() {
+	if q.version < 7 {
+		return
+	}
+	rem := q.version
+	for range 12 {
+		rem = rem<<1 ^ (rem>>11)*0x1F25
+	}
+	bits := q.version<<12 | rem
+	for i := range 18 {
+		a, b := q.size-11+i%3, i/3
+		q.setFunction(a, b, bit(bits, i))
+		q.setFunction(b, a, bit(bits, i))
+	}
+}
+
+// codewords — данные в байтовом режиме с дополнением, разбитые на блоки
+// с кодами Рида — Соломона и перемежённые
+func (q *qrBuilder) codewords(data []byte) []byte {
+	v := qrVersionsM[q.version]
+	capacity := v.dataBytes()
+
+	var bb bitBuffer
+	bb.append(0b0100, 4) // байтовый режим
+	if q.version >= 10 {
+		bb.append(len(data), 16)
+	} else {
+		bb.append(len(data), 8)
+	}
+	for _, b := range data {
+		bb.append(int(b), 8)
+	}
+	bb.append(0, min(4, capacity*8-len(bb)))
+	bb.append(0, (8-len(bb)%8)%8)
+	for pad := 0xEC; len(bb) < capacity*8; pad ^= 0xEC ^ 0x11 {
+		bb.append(pad, 8)
+	}
+	raw := bb.bytes()
+
+	var dataBlocks, ecBlocks [][]byte
+	divisor := rsDivisor(v.ecPerBlock)
+	for i, off := 0, 0; i < v.blocks1+v.blocks2; i++ {
+		n := v.data1
+		if i >= v.blocks1 {
+			n = v.data2
+		}
+		block := raw[off : off+n]
+		off += n
+		dataBlocks = append(dataBlocks, block)
+		ecBlocks = append(ecBlocks, rsRemainder(block, divisor))
+	}
+
+	out := make([]byte, 0, capacity+len(ecBlocks)*v.ecPerBlock)
+	for i := range max(v.data1, v.data2) {
+		for _, b := range dataBlocks {
+			if i < len(b) {
+				out = append(out, b[i])
+			}
+		}
+	}
+	for i := range v.ecPerBlock {
+		for _, b := range ecBlocks {
+			out = append(out, b[i])
+		}
+	}
+	return out
+}
+
+// drawCodewords раскладывает биты зигзагом парами столбцов снизу вверх и обратно
+func (q *qrBuilder) drawCodewords(data []byte) {
+	i := 0
+	for right := q.size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			right = 5 // вертикальная синхронизирующая линия
+		}
+		for vert := range q.size {
+			for j := range 2 {
+				x := right - j
+				y := vert
+				if (right+1)&2 == 0 {
+					y = q.size - 1 - vert
+				}
+				if !q.isFunction[y][x] && i < len(data)*8 {
+					q.modules[y][x] = data[i>>3]>>(7-i&7)&1 == 1
+					i++
+				}
+			}
+		}
+	}
+}
+
+func (q *qrBuilder) applyMask(mask int) {
+	for y := range q.size {
+		for x := range q.size {
+			if q.isFunction[y][x] {
+				continue
+			}
+			var invert bool
+			switch mask {
+			case 0:
+				invert = (x+y)%2 == 0
+			case 1:
+				invert = y%2 == 0
+			case 2:
+				invert = x%3 == 0
+			case 3:
+				invert = (x+y)%3 == 0
+			case 4:
+				invert = (x/3+y/2)%2 == 0
+			case 5:
+				invert = x*y%2+x*y%3 == 0
+			case 6:
+				invert = (x*y%2+x*y%3)%2 == 0
+			case 7:
+				invert = ((x+y)%2+x*y%3)%2 == 0
+			}
+			if invert {
+				q.modules[y][x] = !q.modules[y][x]
+			}
+		}
+	}
+}
+
+// penalty — штраф по четырём правилам стандарта: длинные серии, блоки 2×2,
+// узоры, похожие на поисковые, и перекос доли тёмных модулей
+func (q *qrBuilder) penalty() int {
+	p := 0
+	line := make([]bool, q.size)
+	for _, vertical := range []bool{false, true} {
+		for a := range q.size {
+			for b := range q.size {
+				if vertical {
+					line[b] = q.modules[b][a]
+				} else {
+					line[b] = q.modules[a][b]
+				}
+			}
+			p += linePenalty(line)
+		}
+	}
+
+	dark := 0
+	for y := range q.size {
+		for x := range q.size {
+			c := q.modules[y][x]
+			if c {
+				dark++
+			}
+			if x+1 < q.size && y+1 < q.size &&
+				c == q.modules[y][x+1] && c == q.modules[y+1][x] && c == q.modules[y+1][x+1] {
+				p += 3
+			}
+		}
+	}
+	total := q.size * q.size
+	p += abs(dark*100/total-50) / 5 * 10
+	return p
+}
+
+var finderLike = [2][11]bool{
+	{true, false, true, true, true, false, true, false, false, false, false},
+	{false, false, false, false, true, false, true, true, true, false, true},
+}
+
+func linePenalty(line []bool) int {
+	p := 0
+	run := 1
+	for i := 1; i <= len(line); i++ {
+		if i < len(line) && line[i] == line[i-1] {
+			run++
+			continue
+		}
+		if run >= 5 {
+			p += 3 + run - 5
+		}
+		run = 1
+	}
+	for i := 0; i+11 <= len(line); i++ {
+		for _, pat := range finderLike {
+			match := true
+			for j, c := range pat {
+				if line[i+j] != c {
+					match = false
+					break
+				}
+			}
+			if match {
+				p += 40
+			}
+		}
+	}
+	return p
+}
+
+type bitBuffer []bool
+
+func (bb *bitBuffer) append(v, n int) {
+	for i := n - 1; i >= 0; i-- {
+		*bb = append(*bb, v>>i&1 == 1)
+	}
+}
+
+func (bb bitBuffer) bytes() []byte {
+	out := make([]byte, len(bb)/8)
+	for i, b := range bb {
+		if b {
+			out[i/8] |= 1 << (7 - i%8)
+		}
+	}
+	return out
+}
+
+// rsDivisor — порождающий многочлен Рида — Соломона степени degree над GF(256)
+func rsDivisor(degree int) []byte {
+	result := make([]byte, degree)
+	result[degree-1] = 1
+	root := byte(1)
+	for range degree {
+		for j := range result {
+			result[j] = gfMul(result[j], root)
+			if j+1 < len(result) {
+				result[j] ^= result[j+1]
+			}
+		}
+		root = gfMul(root, 0x02)
+	}
+	return result
+}
+
+func rsRemainder(data, divisor []byte) []byte {
+	result := make([]byte, len(divisor))
+	for _, b := range data {
+		factor := b ^ result[0]
+		copy(result, result[1:])
+		result[len(result)-1] = 0
+		for i := range result {
+			result[i] ^= gfMul(divisor[i], factor)
+		}
+	}
+	return result
+}
+
+// gfMul — умножение в GF(2^8) по модулю x^8+x^4+x^3+x^2+1
+func gfMul(x, y byte) byte {
+	var z int
+	for i := 7; i >= 0; i-- {
+		z = z<<1 ^ (z>>7)*0x11D
+		z ^= int(y>>i&1) * int(x)
+	}
+	return byte(z)
+}
+
+func bit(v, i int) bool {
+	return v>>i&1 == 1
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
@@ -0,0 +1,293 @@
+package barcode
+
+import (
+	"bytes"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// Форматная информация уровня M для масок 0..7 (ISO/IEC 18004, таблица C.1)
+var formatBitsM = [8]int{
+	0b101010000010010,
+	0b101000100100101,
+	0b101111001111100,
+	0b101101101001011,
+	0b100010111111001,
+	0b100000011001110,
+	0b100111110010111,
+	0b100101010100000,
+}
+
+// readFormatBits читает обе копии форматной информации, старший бит первым
+func readFormatBits(size int, dark func(x, y int) bool) (first, second int) {
+	var a, b []bool
+	for _, x := range []int{0, 1, 2, 3, 4, 5, 7, 8} {
+		a = append(a, dark(x, 8))
+	}
+	for _, y := range []int{7, 5, 4, 3, 2, 1, 0} {
+		a = append(a, dark(8, y))
+	}
+	for y := size - 1; y >= size-7; y-- {
+		b = append(b, dark(8, y))
+	}
+	for x := size - 8; x < size; x++ {
+		b = append(b, dark(x, 8))
+	}
+	return bitsToInt(a), bitsToInt(b)
+}
+
+func bitsToInt(bits []bool) int {
+	v := 0
+	for _, b := range bits {
+		v <<= 1
+		if b {
+			v |= 1
+		}
+	}
+	return v
+}
+
+func TestQRFormatBits(t *testing.T) {
+	for mask, want := range formatBitsM {
+		q := newQRBuilder(1)
+		q.drawFormatBits(mask)
+		first, second := readFormatBits(q.size, func(x, y int) bool { return q.modules[y][x] })
+		if first != want || second != want {
+			t.Errorf("mask %d: format bits %015b / %015b, want %015b", mask, first, second, want)
+		}
+	}
+}
+
+// Информация о версии 7 — 000111110010010100 (ISO/IEC 18004, таблица D.1)
+func TestQRVersionBits(t *testing.T) {
+	const want = 0b000111110010010100
+	q := newQRBuilder(7)
+	q.drawVersion()
+
+	var topRight, bottomLeft []bool
+	for i := 17; i >= 0; i-- {
+		a, b := q.size-11+i%3, i/3
+		topRight = append(topRight, q.modules[b][a])
+		bottomLeft = append(bottomLeft, q.modules[a][b])
+	}
+	if got := bitsToInt(topRight); got != want {
+		t.Errorf("top-right version bits = %018b, want %018b", got, want)
+	}
+	if got := bitsToInt(bottomLeft); got != want {
+		t.Errorf("bottom-left version bits = %018b, want %018b", got, want)
+	}
+}
+
+// Коды коррекции для "HELLO WORLD", версия 1-M
+func TestReedSolomon(t *testing.T) {
+	data := []byte{32, 91, 11, 120, 209, 114, 220, 77, 67, 64, 236, 17, 236, 17, 236, 17}
+	want := []byte{196, 35, 39, 119, 235, 215, 231, 226, 93, 23}
+	if got := rsRemainder(data, rsDivisor(len(want))); !bytes.Equal(got, want) {
+		t.Errorf("rsRemainder = %v, want %v", got, want)
+	}
+}
+
+func TestEncodeQRVersion(t *testing.T) {
+	// ёмкость версий в байтовом режиме на уровне M
+	tests := []struct {
+		n    int
+		want int
+	}{
+		{1, 1}, {14, 1}, {15, 2}, {26, 2}, {27, 3}, {42, 3}, {62, 4}, {84, 5},
+		{106, 6}, {122, 7}, {152, 8}, {180, 9}, {181, 10}, {213, 10},
+	}
+	for _, tt := range tests {
+		q, err := EncodeQR(bytes.Repeat([]byte{'A'}, tt.n))
+		if err != nil {
+			t.Fatalf("EncodeQR(%d bytes) error = %v", tt.n, err)
+		}
+		if q.Version != tt.want || q.Size != 17+4*tt.want {
+			t.Errorf("EncodeQR(%d bytes): version %d, size %d; want version %d", tt.n, q.Version, q.Size, tt.want)
+		}
+	}
+	if _, err := EncodeQR(bytes.Repeat([]byte{'A'}, 214)); !errors.Is(err, ErrTooLong) {
+		t.Errorf("EncodeQR(214 bytes) error = %v, want %v", err, ErrTooLong)
+	}
+}
+
+// Трек-номер на этикетке: версия 2-M, маска 2
+var qrGolden = []string{
+	"#######..##..###..#######",
+	"#.....#..#.######.#.....#",
+	"#.###.#.##..##.#..#.###.#",
+	"#.###.#.#.....##..#.###.#",
+	"#.###.#.##.#...##.#.###.#",
+	"#.....#.#.........#.....#",
+	"#######.#.#.#.#.#.#######",
+	"........#..##.#.#........",
+	"#.#####....#......#####..",
+	"..##...#.##.####.#...#.#.",
+	"#.#.#.###.######..#..##.#",
+	".#..#....#.#.#.##.#..#..#",
+	"####.#####..#.###.###.##.",
+	"#.#..#.#.##......#......#",
+	"#.#.#.#....##..##.#..####",
+	"#.#.##.#...#..##..#.##..#",
+	"#..#..#..###...######.###",
+	"........#.#.###.#...##.##",
+	"#######..#...##.#.#.#..##",
+	"#.....#.##..##.##...##.#.",
+	"#.###.#.#.#.#.#######.##.",
+	"#.###.#.###....#..####..#",
+	"#.###.#.##.##..#.#...#..#",
+	"#.....#..###..#.#.##.#..#",
+	"#######.#.##...#..#...###",
+}
+
+func TestEncodeQRGolden(t *testing.T) {
+	q, err := EncodeQR([]byte("TL2610180000014"))
+	if err != nil {
+		t.Fatalf("EncodeQR error = %v", err)
+	}
+	if q.Size != len(qrGolden) {
+		t.Fatalf("Size = %d, want %d", q.Size, len(qrGolden))
+	}
+	for y := range q.Size {
+		var row strings.Builder
+		for x := range q.Size {
+			if q.Dark(x, y) {
+				row.WriteByte('#')
+			} else {
+				row.WriteByte('.')
+			}
+		}
+		if row.String() != qrGolden[y] {
+			t.Errorf("row %2d = %s\n        want %s", y, row.String(), qrGolden[y])
+		}
+	}
+}
+
+// TestEncodeQRDecode читает символ обратно, как сканер: форматную информацию,
+// снятие маски, блоки с проверкой кодов Рида — Соломона и байтовый режим
+func TestEncodeQRDecode(t *testing.T) {
+	payloads := []string{
+		"TL2610180000014",
+		"https://transline.kz/track/TL2610180000014",
+		strings.Repeat("transline ", 20), // версия 10: информация о версии, 16-битная длина, блоки двух размеров
+	}
+	for _, payload := range payloads {
+		q, err := EncodeQR([]byte(payload))
+		if err != nil {
+			t.Fatalf("EncodeQR(%q) error = %v", payload, err)
+		}
+		if got := decodeQR(t, q); got != payload {
+			t.Errorf("decoded %q, want %q", got, payload)
+		}
+	}
+}
+
+func decodeQR(t *testing.T, q *QR) string {
+	t.Helper()
+	size := q.Size
+
+	// поисковые узоры в трёх углах
+	for _, c := range [][2]int{{0, 0}, {size - 7, 0}, {0, size - 7}} {
+		for dy := range 7 {
+			for dx := range 7 {
+				d := max(abs(dx-3), abs(dy-3))
+				if want := d != 2; q.Dark(c[0]+dx, c[1]+dy) != want {
+					t.Fatalf("finder at %v: module (%d,%d) = %v", c, dx, dy, !want)
+				}
+			}
+		}
+	}
+	for i := 8; i < size-8; i++ {
+		if q.Dark(i, 6) != (i%2 == 0) || q.Dark(6, i) != (i%2 == 0) {
+			t.Fatalf("timing pattern broken at %d", i)
+		}
+	}
+	if !q.Dark(8, size-8) {
+		t.Fatal("dark module is missing")
+	}
+
+	first, second := readFormatBits(size, q.Dark)
+	if first != second {
+		t.Fatalf("format copies differ: %015b / %015b", first, second)
+	}
+	mask := slices.Index(formatBitsM[:], first)
+	if mask < 0 {
+		t.Fatalf("format bits %015b are not level M", first)
+	}
+
+	// снимаем маску с модулей данных
+	b := newQRBuilder(q.Version)
+	b.drawFunctionPatterns()
+	for y := range size {
+		copy(b.modules[y], q.modules[y])
+	}
+	b.applyMask(mask)
+
+	v := qrVersionsM[q.Version]
+	total := v.dataBytes() + (v.blocks1+v.blocks2)*v.ecPerBlock
+	var bits bitBuffer
+	for right := size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			right = 5
+		}
+		upward := (right+1)&2 == 0
+		for vert := range size {
+			y := vert
+			if upward {
+				y = size - 1 - vert
+			}
+			for _, x := range []int{right, right - 1} {
+				if !b.isFunction[y][x] && len(bits) < total*8 {
+					bits = append(bits, b.modules[y][x])
+				}
+			}
+		}
+	}
+	raw := bits.bytes()
+
+	// перемежение: сначала i-е байты данных всех блоков, затем коды коррекции
+	n := v.blocks1 + v.blocks2
+	blocks := make([][]byte, n)
+	ec := make([][]byte, n)
+	pos := 0
+	for i := range max(v.data1, v.data2) {
+		for j := range n {
+			if j < v.blocks1 && i >= v.data1 || j >= v.blocks1 && i >= v.data2 {
+				continue
+			}
+			blocks[j] = append(blocks[j], raw[pos])
+			pos++
+		}
+	}
+	for range v.ecPerBlock {
+		for j := range n {
+			ec[j] = append(ec[j], raw[pos])
+			pos++
+		}
+	}
+	var data []byte
+	for j := range n {
+		if got := rsRemainder(blocks[j], rsDivisor(v.ecPerBlock)); !bytes.Equal(got, ec[j]) {
+			t.Fatalf("block %d: error correction mismatch", j)
+		}
+		data = append(data, blocks[j]...)
+	}
+
+	if mode := data[0] >> 4; mode != 0b0100 {
+		t.Fatalf("mode = %04b, want byte mode", mode)
+	}
+	var payload []byte
+	if q.Version >= 10 {
+		count := int(data[0]&0x0F)<<12 | int(data[1])<<4 | int(data[2]>>4)
+		for i := range count {
+			payload = append(payload, data[2+i]<<4|data[3+i]>>4)
+		}
+	} else {
+		count := int(data[0]&0x0F)<<4 | int(data[1]>>4)
+		for i := range count {
+			payload = append(payload, data[1+i]<<4|data[2+i]>>4)
+		}
+	}
+	return string(payload)
+}
@@ -0,0 +1,83 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"transline.kz/internal/customer/service"
+)
+
+// UnaryErrorInterceptor переводит доменные ошибки сервиса в gRPC-статусы
+func UnaryErrorInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		resp, err := handler(ctx, req)
+		if err != nil {
+			return nil, toStatus(info.FullMethod, err)
+		}
+		return resp, nil
+	}
+}
+
+// StreamErrorInterceptor — то же для потоковых RPC
+func StreamErrorInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := handler(srv, ss); err != nil {
+			return toStatus(info.FullMethod, err)
+		}
+		return nil
+	}
+}
+
+// toStatus: ошибки, уже являющиеся статусом, проходят как есть; внутренние
+// ошибки логируются и уходят клиенту без подробностей
+func toStatus(method string, err error) error {
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+
+	var verr *service.ValidationError
+	switch {
+	case errors.As(err, &verr):
+		return invalidArgument(verr)
+	case errors.Is(err, service.ErrInvalidArgument):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, service.ErrNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, service.ErrConflict):
+		return status.Error(codes.AlreadyExists, err.Error())
+	case errors.Is(err, service.ErrFailedPrecondition):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, service.ErrUnavailable):
+		slog.Warn("storage unavailable", "method", method, "err", err)
+		return status.Error(codes.Unavailable, "storage is temporarily unavailable")
+	case errors.Is(err, context.DeadlineExceeded):
+		return status.Error(codes.DeadlineExceeded, err.Error())
+	case errors.Is(err, context.Canceled):
+		return status.Error(codes.Canceled, err.Error())
+	}
+
+	slog.Error("internal error", "method", method, "err", err)
+	return status.Error(codes.Internal, "internal error")
+}
+
+func invalidArgument(verr *service.ValidationError) error {
+	br := &errdetails.BadRequest{}
+	for _, v := range verr.Violations {
+		br.FieldViolations = append(br.FieldViolations, &errdetails.BadRequest_FieldViolation{
+			Field:       v.Field,
+			Description: v.Description,
+		})
+	}
+
+	st, err := status.New(codes.InvalidArgument, verr.Error()).WithDetails(br)
+	if err != nil {
+		return status.Error(codes.InvalidArgument, verr.Error())
+	}
+	return st.Err()
+}
@@ -2,9 +2,12 @@ package grpc
 
 import (
 	"context"
+	"errors"
+	"io"
 	"time"
 
 	pb "transline.kz/api/proto/customerpb"
+	"transline.kz/internal/customer/repo"
 	"transline.kz/internal/customer/service"
 )
 
@@ -18,14 +21,184 @@ func New(svc *service.Service) *Server {
 }
 
 func (s *Server) UpsertCustomer(ctx context.Context, req *pb.UpsertCustomerRequest) (*pb.CustomerResponse, error) {
-	c, err := s.svc.UpsertCustomer(ctx, req.Idn)
+	c, created, err := s.svc.UpsertCustomer(ctx, req.Idn, req.RequestId)
 	if err != nil {
 		return nil, err
 	}
 
+	resp := toCustomerResponse(c)
+	resp.Created = created
+	return resp, nil
+}
+
+func (s *Server) BatchUpsertCustomers(ctx context.Context, req *pb.BatchUpsertCustomersRequest) (*pb.BatchUpsertCustomersResponse, error) {
+	return s.batchUpsert(ctx, req)
+}
+
+// UpsertCustomersStream обрабатывает каждое сообщение потока как
+// BatchUpsertCustomers; ошибка пакета (не элемента) закрывает поток
+func (s *Server) UpsertCustomersStream(stream pb.CustomerService_UpsertCustomersStreamServer) error {
+	for {
+		req, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		resp, err := s.batchUpsert(stream.Context(), req)
+		if err != nil {
+			return err
+		}
+		if err := stream.Send(resp); err != nil {
+			return err
+		}
+	}
+}
+
+func (s *Server) batchUpsert(ctx context.Context, req *pb.BatchUpsertCustomersRequest) (*pb.BatchUpsertCustomersResponse, error) {
+	reqs := make([]service.UpsertRequest, len(req.Items))
+	for i, it := range req.Items {
+		reqs[i] = service.UpsertRequest{IDN: it.Idn, RequestID: it.RequestId}
+	}
+	results, err := s.svc.BatchUpsertCustomers(ctx, reqs)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &pb.BatchUpsertCustomersResponse{Results: make([]*pb.UpsertCustomerResult, len(results))}
+	for i, res := range results {
+		out := &pb.UpsertCustomerResult{}
+		var verr *service.ValidationError
+		switch {
+		case errors.As(res.Err, &verr):
+			for _, v := range verr.Violations {
+				out.Violations = append(out.Violations, &pb.FieldViolation{Field: v.Field, Description: v.Description})
+			}
+		case res.Err != nil:
+			return nil, res.Err
+		default:
+			out.Customer = toCustomerResponse(res.Customer)
+			out.Customer.Created = res.Created
+		}
+		resp.Results[i] = out
+	}
+	return resp, nil
+}
+
+func (s *Server) CompensateUpsertCustomer(ctx context.Context, req *pb.CompensateUpsertCustomerRequest) (*pb.CompensateUpsertCustomerResponse, error) {
+	deleted, err := s.svc.CompensateUpsert(ctx, req.RequestId)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.CompensateUpsertCustomerResponse{Deleted: deleted}, nil
+}
+
+func (s *Server) GetCustomerById(ctx context.Context, req *pb.GetCustomerByIdRequest) (*pb.CustomerResponse, error) {
+	c, err := s.svc.GetCustomer(ctx, req.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	return toCustomerResponse(c), nil
+}
+
+func (s *Server) GetCustomerByIdn(ctx context.Context, req *pb.GetCustomerByIdnRequest) (*pb.CustomerResponse, error) {
+	c, err := s.svc.GetCustomerByIDN(ctx, req.Idn)
+	if err != nil {
+		return nil, err
+	}
+
+	return toCustomerResponse(c), nil
+}
+
+func (s *Server) GetCustomers(ctx context.Context, req *pb.GetCustomersRequest) (*pb.GetCustomersResponse, error) {
+	found, notFound, err := s.svc.GetCustomers(ctx, req.Ids)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &pb.GetCustomersResponse{
+		Customers:   make([]*pb.CustomerResponse, 0, len(found)),
+		NotFoundIds: notFound,
+	}
+	for i := range found {
+		resp.Customers = append(resp.Customers, toCustomerResponse(&found[i]))
+	}
+	return resp, nil
+}
+
+func (s *Server) UpdateCustomer(ctx context.Context, req *pb.UpdateCustomerRequest) (*pb.CustomerResponse, error) {
+	c, err := s.svc.UpdateCustomer(ctx, service.UpdateCustomerInput{
+		ID: req.Id,
+		Profile: repo.Profile{
+			Name:         req.Name,
+			Phone:        req.Phone,
+			Email:        req.Email,
+			LegalAddress: req.LegalAddress,
+		},
+		Mask: req.UpdateMask,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return toCustomerResponse(c), nil
+}
+
+func (s *Server) ListCustomers(ctx context.Context, req *pb.ListCustomersRequest) (*pb.ListCustomersResponse, error) {
+	res, err := s.svc.ListCustomers(ctx, service.ListCustomersInput{
+		Type:      fromCustomerType(req.Type),
+		Name:      req.Name,
+		PageSize:  int(req.PageSize),
+		PageToken: req.PageToken,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &pb.ListCustomersResponse{
+		Customers:     make([]*pb.CustomerResponse, 0, len(res.Customers)),
+		NextPageToken: res.NextPageToken,
+	}
+	for i := range res.Customers {
+		resp.Customers = append(resp.Customers, toCustomerResponse(&res.Customers[i]))
+	}
+	return resp, nil
+}
+
+func toCustomerResponse(c *repo.Customer) *pb.CustomerResponse {
 	return &pb.CustomerResponse{
-		Id:        c.ID,
-		Idn:       c.IDN,
-		CreatedAt: c.CreatedAt.Format(time.RFC3339),
-	}, nil
+		Id:           c.ID,
+		Idn:          c.IDN,
+		CreatedAt:    c.CreatedAt.Format(time.RFC3339),
+		Name:         c.Name,
+		Type:         toCustomerType(c.Type),
+		Phone:        c.Phone,
+		Email:        c.Email,
+		LegalAddress: c.LegalAddress,
+		UpdatedAt:    c.UpdatedAt.Format(time.RFC3339),
+	}
+}
+
+func toCustomerType(t string) pb.CustomerType {
+	switch t {
+	case repo.TypeIndividual:
+		return pb.CustomerType_CUSTOMER_TYPE_INDIVIDUAL
+	case repo.TypeLegalEntity:
+		return pb.CustomerType_CUSTOMER_TYPE_LEGAL_ENTITY
+	}
+	return pb.CustomerType_CUSTOMER_TYPE_UNSPECIFIED
+}
+
+func fromCustomerType(t pb.CustomerType) string {
+	switch t {
+	case pb.CustomerType_CUSTOMER_TYPE_INDIVIDUAL:
+		return repo.TypeIndividual
+	case pb.CustomerType_CUSTOMER_TYPE_LEGAL_ENTITY:
+		return repo.TypeLegalEntity
+	}
+	return ""
 }
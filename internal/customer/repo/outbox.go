@@ -0,0 +1,74 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"transline.kz/internal/outbox"
+)
+
+// upsertedData — данные CustomerUpserted; событие пишется, только когда
+// upsert вставил клиента
+type upsertedData struct {
+	CustomerID string    `json:"customerId"`
+	IDN        string    `json:"idn"`
+	Type       string    `json:"type"`
+	RequestID  string    `json:"requestId,omitempty"`
+	CreatedAt  time.Time `json:"createdAt"`
+}
+
+// publishUpserted пишет CustomerUpserted для каждого клиента; requestIDs —
+// request_id вставки в том же порядке
+func publishUpserted(ctx context.Context, tx outbox.Execer, customers []Customer, requestIDs []string) error {
+	evs := make([]outbox.Event, 0, len(customers))
+	for i, c := range customers {
+		id, err := uuid.Parse(c.ID)
+		if err != nil {
+			return fmt.Errorf("invalid customer id %q: %w", c.ID, err)
+		}
+		ev, err := outbox.NewEvent(outbox.CustomerUpserted, outbox.AggregateCustomer, id, upsertedData{
+			CustomerID: c.ID,
+			IDN:        c.IDN,
+			Type:       c.Type,
+			RequestID:  requestIDs[i],
+			CreatedAt:  c.CreatedAt.UTC(),
+		})
+		if err != nil {
+			return err
+		}
+		evs = append(evs, ev)
+	}
+	return outbox.Insert(ctx, tx, evs...)
+}
+
+// deletedData — данные CustomerDeleted: клиент удалён компенсацией upsert,
+// выполненного requestID
+type deletedData struct {
+	CustomerID string `json:"customerId"`
+	IDN        string `json:"idn"`
+	RequestID  string `json:"requestId"`
+}
+
+// publishDeleted пишет CustomerDeleted для каждого удалённого клиента
+func publishDeleted(ctx context.Context, tx outbox.Execer, customers []Customer, requestID string) error {
+	evs := make([]outbox.Event, 0, len(customers))
+	for _, c := range customers {
+		id, err := uuid.Parse(c.ID)
+		if err != nil {
+			return fmt.Errorf("invalid customer id %q: %w", c.ID, err)
+		}
+		ev, err := outbox.NewEvent(outbox.CustomerDeleted, outbox.AggregateCustomer, id, deletedData{
+			CustomerID: c.ID,
+			IDN:        c.IDN,
+			RequestID:  requestID,
+		})
+		if err != nil {
+			return err
+		}
+		evs = append(evs, ev)
+	}
+	return outbox.Insert(ctx, tx, evs...)
+}
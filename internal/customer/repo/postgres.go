@@ -2,15 +2,63 @@ package repo
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"strings"
 	"time"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
+
+	"transline.kz/internal/pgerr"
+)
+
+var ErrNotFound = errors.New("customer not found")
+
+// Типы клиента; совпадают с CHECK в customers.customer_type
+const (
+	TypeIndividual  = "INDIVIDUAL"
+	TypeLegalEntity = "LEGAL_ENTITY"
 )
 
 type Customer struct {
-	ID        string
-	IDN       string
-	CreatedAt time.Time
+	ID           string
+	IDN          string
+	Type         string
+	Name         string
+	Phone        string
+	Email        string
+	LegalAddress string
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+// Profile — редактируемые поля клиента
+type Profile struct {
+	Name         string
+	Phone        string
+	Email        string
+	LegalAddress string
+}
+
+// Колонки Profile, которые можно передать в Update
+const (
+	FieldName         = "name"
+	FieldPhone        = "phone"
+	FieldEmail        = "email"
+	FieldLegalAddress = "legal_address"
+)
+
+const customerColumns = `id, idn, customer_type, name, phone, email, legal_address, created_at, updated_at`
+
+func scanCustomer(row pgx.Row, extra ...any) (*Customer, error) {
+	c := Customer{}
+	dest := append([]any{&c.ID, &c.IDN, &c.Type, &c.Name, &c.Phone, &c.Email, &c.LegalAddress, &c.CreatedAt, &c.UpdatedAt}, extra...)
+	err := row.Scan(dest...)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	return &c, err
 }
 
 type Repo struct {
@@ -21,16 +69,285 @@ func New(db *pgxpool.Pool) *Repo {
 	return &Repo{db: db}
 }
 
-func (r *Repo) Upsert(ctx context.Context, idn string) (*Customer, error) {
-	row := r.db.QueryRow(ctx, `
-    INSERT INTO customers (id, idn)
-    VALUES (gen_random_uuid(), $1)
+// Upsert возвращает клиента по IDN, создавая его при необходимости.
+// created == true, если клиента вставил этот requestID (в том числе при повторе).
+// Вставка пишет CustomerUpserted в outbox в той же транзакции.
+func (r *Repo) Upsert(ctx context.Context, idn, customerType, requestID string) (c *Customer, created bool, err error) {
+	err = pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		row := tx.QueryRow(ctx, `
+      INSERT INTO customers (id, idn, customer_type, created_by_request)
+      VALUES (gen_random_uuid(), $1, $2, NULLIF($3, '')::uuid)
+      ON CONFLICT (idn)
+        DO UPDATE SET idn = EXCLUDED.idn
+      RETURNING `+customerColumns+`, xmax = 0,
+        COALESCE(xmax = 0 OR created_by_request = NULLIF($3, '')::uuid, false)
+    `, idn, customerType, requestID)
+
+		var inserted bool
+		if c, err = scanCustomer(row, &inserted, &created); err != nil {
+			return err
+		}
+		if !inserted {
+			return nil
+		}
+		return publishUpserted(ctx, tx, []Customer{*c}, []string{requestID})
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	return c, created, nil
+}
+
+// UpsertItem — клиент для UpsertMany; RequestID может быть пустым
+type UpsertItem struct {
+	IDN       string
+	Type      string
+	RequestID string
+}
+
+// UpsertMany — Upsert для нескольких IDN одним INSERT ... ON CONFLICT.
+// IDN в items должны быть уникальны: одна команда не может обновить строку
+// дважды. Результаты — в порядке items.
+func (r *Repo) UpsertMany(ctx context.Context, items []UpsertItem) (customers []Customer, created []bool, err error) {
+	err = pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		customers, created, err = upsertMany(ctx, tx, items)
+		return err
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return customers, created, nil
+}
+
+func upsertMany(ctx context.Context, tx pgx.Tx, items []UpsertItem) ([]Customer, []bool, error) {
+	idns := make([]string, len(items))
+	types := make([]string, len(items))
+	requestIDs := make([]string, len(items))
+	for i, it := range items {
+		idns[i], types[i], requestIDs[i] = it.IDN, it.Type, it.RequestID
+	}
+
+	// RETURNING не видит исходную строку, поэтому created вычисляется ниже
+	// по created_by_request
+	rows, err := tx.Query(ctx, `
+    INSERT INTO customers (id, idn, customer_type, created_by_request)
+    SELECT gen_random_uuid(), t.idn, t.customer_type, NULLIF(t.request_id, '')::uuid
+    FROM unnest($1::text[], $2::text[], $3::text[]) AS t(idn, customer_type, request_id)
     ON CONFLICT (idn)
       DO UPDATE SET idn = EXCLUDED.idn
-    RETURNING id, idn, created_at
+    RETURNING `+customerColumns+`, xmax = 0, COALESCE(created_by_request::text, '')
+  `, idns, types, requestIDs)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	type upserted struct {
+		c         *Customer
+		inserted  bool
+		createdBy string
+	}
+	byIDN := make(map[string]upserted, len(items))
+	for rows.Next() {
+		var u upserted
+		if u.c, err = scanCustomer(rows, &u.inserted, &u.createdBy); err != nil {
+			return nil, nil, err
+		}
+		byIDN[u.c.IDN] = u
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+	rows.Close()
+
+	var (
+		customers  = make([]Customer, len(items))
+		created    = make([]bool, len(items))
+		inserted   []Customer
+		insertedBy []string
+	)
+	for i, it := range items {
+		u, ok := byIDN[it.IDN]
+		if !ok {
+			return nil, nil, fmt.Errorf("customer %s missing from upsert result", it.IDN)
+		}
+		customers[i] = *u.c
+		created[i] = u.inserted || it.RequestID != "" && u.createdBy == it.RequestID
+		if u.inserted {
+			inserted = append(inserted, *u.c)
+			insertedBy = append(insertedBy, it.RequestID)
+		}
+	}
+	if err := publishUpserted(ctx, tx, inserted, insertedBy); err != nil {
+		return nil, nil, err
+	}
+	return customers, created, nil
+}
+
+func (r *Repo) Get(ctx context.Context, id string) (*Customer, error) {
+	row := r.db.QueryRow(ctx, `
+    SELECT `+customerColumns+`
+    FROM customers
+    WHERE id = $1
+  `, id)
+	return scanCustomer(row)
+}
+
+func (r *Repo) GetByIDN(ctx context.Context, idn string) (*Customer, error) {
+	row := r.db.QueryRow(ctx, `
+    SELECT `+customerColumns+`
+    FROM customers
+    WHERE idn = $1
   `, idn)
+	return scanCustomer(row)
+}
 
-	c := Customer{}
-	err := row.Scan(&c.ID, &c.IDN, &c.CreatedAt)
-	return &c, err
+// GetMany возвращает найденных клиентов в произвольном порядке
+func (r *Repo) GetMany(ctx context.Context, ids []string) ([]Customer, error) {
+	rows, err := r.db.Query(ctx, `
+    SELECT `+customerColumns+`
+    FROM customers
+    WHERE id = ANY($1::uuid[])
+  `, ids)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []Customer
+	for rows.Next() {
+		c, err := scanCustomer(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, *c)
+	}
+	return out, rows.Err()
+}
+
+// Update меняет только поля из fields (имена колонок Field*)
+func (r *Repo) Update(ctx context.Context, id string, p Profile, fields []string) (*Customer, error) {
+	values := map[string]string{
+		FieldName:         p.Name,
+		FieldPhone:        p.Phone,
+		FieldEmail:        p.Email,
+		FieldLegalAddress: p.LegalAddress,
+	}
+
+	args := []any{id}
+	set := []string{"updated_at = now()"}
+	for _, f := range fields {
+		v, ok := values[f]
+		if !ok {
+			return nil, fmt.Errorf("unknown customer field %q", f)
+		}
+		args = append(args, v)
+		set = append(set, fmt.Sprintf("%s = $%d", f, len(args)))
+	}
+
+	row := r.db.QueryRow(ctx, `
+    UPDATE customers
+    SET `+strings.Join(set, ", ")+`
+    WHERE id = $1
+    RETURNING `+customerColumns, args...)
+	return scanCustomer(row)
+}
+
+// ListCursor — позиция keyset-пагинации по (created_at, id)
+type ListCursor struct {
+	CreatedAt time.Time
+	ID        string
+}
+
+type ListFilter struct {
+	Type         string
+	NameContains string
+	After        *ListCursor
+	Limit        int
+}
+
+// List возвращает клиентов по фильтру в порядке (created_at, id)
+func (r *Repo) List(ctx context.Context, f ListFilter) ([]Customer, error) {
+	var (
+		where []string
+		args  []any
+	)
+	arg := func(v any) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	if f.Type != "" {
+		where = append(where, "customer_type = "+arg(f.Type))
+	}
+	if f.NameContains != "" {
+		where = append(where, "name ILIKE "+arg("%"+escapeLike(f.NameContains)+"%"))
+	}
+	if f.After != nil {
+		where = append(where, fmt.Sprintf("(created_at, id) > (%s, %s)", arg(f.After.CreatedAt), arg(f.After.ID)))
+	}
+
+	q := `
+    SELECT ` + customerColumns + `
+    FROM customers`
+	if len(where) > 0 {
+		q += "\n    WHERE " + strings.Join(where, " AND ")
+	}
+	q += "\n    ORDER BY created_at, id\n    LIMIT " + arg(f.Limit)
+
+	rows, err := r.db.Query(ctx, q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []Customer
+	for rows.Next() {
+		c, err := scanCustomer(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, *c)
+	}
+	return out, rows.Err()
+}
+
+// DeleteCreatedBy удаляет клиента, созданного requestID, и пишет CustomerDeleted
+// в outbox в той же транзакции. Если на клиента уже ссылаются отправления, он
+// считается используемым и остаётся (deleted == false).
+func (r *Repo) DeleteCreatedBy(ctx context.Context, requestID string) (deleted bool, err error) {
+	err = pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		rows, err := tx.Query(ctx, `
+      DELETE FROM customers
+      WHERE created_by_request = $1
+      RETURNING `+customerColumns, requestID)
+		if err != nil {
+			return err
+		}
+		var removed []Customer
+		for rows.Next() {
+			c, err := scanCustomer(rows)
+			if err != nil {
+				rows.Close()
+				return err
+			}
+			removed = append(removed, *c)
+		}
+		if err := rows.Err(); err != nil {
+			return err
+		}
+		deleted = len(removed) > 0
+		return publishDeleted(ctx, tx, removed, requestID)
+	})
+	if pgerr.IsForeignKeyViolation(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return deleted, nil
+}
+
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
 }
@@ -0,0 +1,65 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"transline.kz/internal/pgerr"
+)
+
+// Доменные ошибки; транспортный слой отображает их в свои коды
+var (
+	ErrInvalidArgument = errors.New("invalid argument")
+	ErrNotFound        = errors.New("customer not found")
+	ErrConflict        = errors.New("conflict")
+	// ErrFailedPrecondition — состояние не позволяет операцию (например, на
+	// клиента ещё ссылаются отправления)
+	ErrFailedPrecondition = errors.New("failed precondition")
+	ErrUnavailable        = errors.New("storage unavailable")
+)
+
+// FieldViolation — нарушение правила для конкретного поля запроса
+type FieldViolation struct {
+	Field       string
+	Description string
+}
+
+// ValidationError — ErrInvalidArgument с перечнем нарушений по полям
+type ValidationError struct {
+	Violations []FieldViolation
+}
+
+func (e *ValidationError) Error() string {
+	parts := make([]string, 0, len(e.Violations))
+	for _, v := range e.Violations {
+		parts = append(parts, v.Field+": "+v.Description)
+	}
+	return "invalid argument: " + strings.Join(parts, "; ")
+}
+
+func (e *ValidationError) Is(target error) bool {
+	return target == ErrInvalidArgument
+}
+
+func invalidField(field, format string, args ...any) error {
+	return &ValidationError{Violations: []FieldViolation{{
+		Field:       field,
+		Description: fmt.Sprintf(format, args...),
+	}}}
+}
+
+// storageError переводит ошибки pgx в доменные; неизвестные остаются как есть
+// и считаются внутренними. Текст доменной ошибки уходит клиенту в статусе,
+// поэтому сообщение Postgres (таблицы, ограничения) в него не попадает.
+func storageError(err error) error {
+	switch {
+	case pgerr.IsUniqueViolation(err):
+		return fmt.Errorf("%w: customer already exists", ErrConflict)
+	case pgerr.IsForeignKeyViolation(err):
+		return fmt.Errorf("%w: customer is referenced by other records", ErrFailedPrecondition)
+	case pgerr.Transient(err):
+		return fmt.Errorf("%w: %w", ErrUnavailable, err)
+	}
+	return err
+}
@@ -0,0 +1,42 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"transline.kz/internal/pgerr"
+)
+
+func TestStorageError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want error
+	}{
+		{"unique violation", &pgconn.PgError{Code: pgerr.UniqueViolation, Message: `duplicate key value violates unique constraint "customers_idn_key"`}, ErrConflict},
+		{"foreign key violation", &pgconn.PgError{Code: pgerr.ForeignKeyViolation, Message: `update or delete on table "customers" violates foreign key constraint`}, ErrFailedPrecondition},
+		{"wrapped foreign key violation", fmt.Errorf("delete: %w", &pgconn.PgError{Code: pgerr.ForeignKeyViolation, Message: `table "customers"`}), ErrFailedPrecondition},
+		{"serialization failure", &pgconn.PgError{Code: "40001"}, ErrUnavailable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := storageError(tt.err)
+			if !errors.Is(got, tt.want) {
+				t.Errorf("storageError(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+			// имена таблиц и ограничений клиенту не показываются
+			if tt.want != ErrUnavailable && strings.Contains(got.Error(), `"customers`) {
+				t.Errorf("storageError(%v) = %q leaks the Postgres message", tt.err, got)
+			}
+		})
+	}
+
+	other := errors.New("boom")
+	if got := storageError(other); got != other {
+		t.Errorf("storageError(%v) = %v, want it unchanged", other, got)
+	}
+}
@@ -0,0 +1,70 @@
+package service
+
+import (
+	"net/mail"
+	"strings"
+	"unicode/utf8"
+
+	"transline.kz/internal/customer/repo"
+)
+
+const (
+	maxNameLen    = 255
+	maxAddressLen = 1000
+)
+
+// normalizeProfile проверяет поля профиля и приводит телефон к E.164
+func normalizeProfile(p repo.Profile) (repo.Profile, error) {
+	p.Name = strings.TrimSpace(p.Name)
+	p.Email = strings.TrimSpace(p.Email)
+	p.LegalAddress = strings.TrimSpace(p.LegalAddress)
+
+	if utf8.RuneCountInString(p.Name) > maxNameLen {
+		return p, invalidField("name", "too long (max %d chars)", maxNameLen)
+	}
+	if utf8.RuneCountInString(p.LegalAddress) > maxAddressLen {
+		return p, invalidField("legal_address", "too long (max %d chars)", maxAddressLen)
+	}
+
+	if p.Email != "" {
+		addr, err := mail.ParseAddress(p.Email)
+		if err != nil || addr.Address != p.Email {
+			return p, invalidField("email", "not a valid email address")
+		}
+		p.Email = strings.ToLower(p.Email)
+	}
+
+	if p.Phone != "" {
+		phone, err := normalizePhone(p.Phone)
+		if err != nil {
+			return p, err
+		}
+		p.Phone = phone
+	}
+	return p, nil
+}
+
+// normalizePhone убирает разделители и приводит номер к виду +<код><номер>.
+// Казахстанский формат 8XXXXXXXXXX переводится в +7XXXXXXXXXX.
+func normalizePhone(s string) (string, error) {
+	var b strings.Builder
+	for i, r := range strings.TrimSpace(s) {
+		switch {
+		case r >= '0' && r <= '9':
+			b.WriteRune(r)
+		case r == '+' && i == 0:
+		case r == ' ' || r == '-' || r == '(' || r == ')':
+		default:
+			return "", invalidField("phone", "expected 10-15 digits, optionally prefixed with +")
+		}
+	}
+
+	digits := b.String()
+	if !strings.HasPrefix(strings.TrimSpace(s), "+") && len(digits) == 11 && digits[0] == '8' {
+		digits = "7" + digits[1:]
+	}
+	if len(digits) < 10 || len(digits) > 15 {
+		return "", invalidField("phone", "expected 10-15 digits, optionally prefixed with +")
+	}
+	return "+" + digits, nil
+}
@@ -2,8 +2,23 @@ package service
 
 import (
 	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
 
+	"github.com/google/uuid"
 	"transline.kz/internal/customer/repo"
+	"transline.kz/internal/idn"
+)
+
+const (
+	DefaultPageSize = 20
+	MaxPageSize     = 100
+	MaxBatchSize    = 100
 )
 
 type Service struct {
@@ -14,6 +29,276 @@ func New(repo *repo.Repo) *Service {
 	return &Service{repo: repo}
 }
 
-func (s *Service) UpsertCustomer(ctx context.Context, idn string) (*repo.Customer, error) {
-	return s.repo.Upsert(ctx, idn)
+// UpsertCustomer — тип клиента определяется по IDN
+func (s *Service) UpsertCustomer(ctx context.Context, idnStr, requestID string) (*repo.Customer, bool, error) {
+	info, err := idn.Parse(idnStr)
+	if err != nil {
+		return nil, false, invalidField("idn", "%v", err)
+	}
+	if requestID != "" {
+		if _, err := uuid.Parse(requestID); err != nil {
+			return nil, false, invalidField("request_id", "must be a UUID")
+		}
+	}
+
+	c, created, err := s.repo.Upsert(ctx, idnStr, customerType(info), requestID)
+	if err != nil {
+		return nil, false, storageError(err)
+	}
+	return c, created, nil
+}
+
+type UpsertRequest struct {
+	IDN       string
+	RequestID string
+}
+
+// UpsertResult — итог элемента BatchUpsertCustomers; Err — ValidationError
+// элемента, остальные поля тогда пусты
+type UpsertResult struct {
+	Customer *repo.Customer
+	Created  bool
+	Err      error
+}
+
+// BatchUpsertCustomers — UpsertCustomer для нескольких IDN одним запросом к
+// БД. Некорректные элементы не мешают остальным; если IDN повторяется,
+// клиента создаёт request_id первого вхождения.
+func (s *Service) BatchUpsertCustomers(ctx context.Context, reqs []UpsertRequest) ([]UpsertResult, error) {
+	if len(reqs) > MaxBatchSize {
+		return nil, invalidField("items", "at most %d items per request", MaxBatchSize)
+	}
+
+	results := make([]UpsertResult, len(reqs))
+	first := make(map[string]int, len(reqs))
+	var items []repo.UpsertItem
+	for i, req := range reqs {
+		info, err := idn.Parse(req.IDN)
+		if err != nil {
+			results[i].Err = invalidField("idn", "%v", err)
+			continue
+		}
+		if req.RequestID != "" {
+			if _, err := uuid.Parse(req.RequestID); err != nil {
+				results[i].Err = invalidField("request_id", "must be a UUID")
+				continue
+			}
+		}
+		if _, ok := first[req.IDN]; ok {
+			continue
+		}
+		first[req.IDN] = i
+		items = append(items, repo.UpsertItem{IDN: req.IDN, Type: customerType(info), RequestID: req.RequestID})
+	}
+	if len(items) == 0 {
+		return results, nil
+	}
+	// одинаковый порядок вставки у параллельных пакетов исключает взаимоблокировки
+	slices.SortFunc(items, func(a, b repo.UpsertItem) int { return strings.Compare(a.IDN, b.IDN) })
+
+	customers, created, err := s.repo.UpsertMany(ctx, items)
+	if err != nil {
+		return nil, storageError(err)
+	}
+	byIDN := make(map[string]int, len(items))
+	for i, it := range items {
+		byIDN[it.IDN] = i
+	}
+	for i, req := range reqs {
+		if results[i].Err != nil {
+			continue
+		}
+		j := byIDN[req.IDN]
+		results[i].Customer = &customers[j]
+		// повтор IDN клиента не создавал, если только это не тот же request_id
+		results[i].Created = created[j] && (first[req.IDN] == i || req.RequestID == items[j].RequestID)
+	}
+	return results, nil
+}
+
+// CompensateUpsert откатывает UpsertCustomer, выполненный операцией requestID
+func (s *Service) CompensateUpsert(ctx context.Context, requestID string) (bool, error) {
+	if _, err := uuid.Parse(requestID); err != nil {
+		return false, invalidField("request_id", "must be a UUID")
+	}
+	deleted, err := s.repo.DeleteCreatedBy(ctx, requestID)
+	return deleted, storageError(err)
+}
+
+func (s *Service) GetCustomer(ctx context.Context, id string) (*repo.Customer, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, invalidField("id", "must be a UUID")
+	}
+	c, err := s.repo.Get(ctx, id)
+	if errors.Is(err, repo.ErrNotFound) {
+		return nil, ErrNotFound
+	}
+	return c, storageError(err)
+}
+
+func (s *Service) GetCustomerByIDN(ctx context.Context, idnStr string) (*repo.Customer, error) {
+	if err := idn.Validate(idnStr); err != nil {
+		return nil, invalidField("idn", "%v", err)
+	}
+	c, err := s.repo.GetByIDN(ctx, idnStr)
+	if errors.Is(err, repo.ErrNotFound) {
+		return nil, ErrNotFound
+	}
+	return c, storageError(err)
+}
+
+// GetCustomers возвращает найденных клиентов в порядке первого упоминания
+// в ids и список id, которых нет
+func (s *Service) GetCustomers(ctx context.Context, ids []string) (found []repo.Customer, notFound []string, err error) {
+	if len(ids) > MaxBatchSize {
+		return nil, nil, invalidField("ids", "at most %d ids per request", MaxBatchSize)
+	}
+
+	unique := make([]string, 0, len(ids))
+	seen := make(map[string]bool, len(ids))
+	for i, id := range ids {
+		u, err := uuid.Parse(id)
+		if err != nil {
+			return nil, nil, invalidField(fmt.Sprintf("ids[%d]", i), "%q is not a UUID", id)
+		}
+		// приводим к каноничному виду, чтобы сопоставить с ответом БД
+		if key := u.String(); !seen[key] {
+			seen[key] = true
+			unique = append(unique, key)
+		}
+	}
+	if len(unique) == 0 {
+		return nil, nil, nil
+	}
+
+	rows, err := s.repo.GetMany(ctx, unique)
+	if err != nil {
+		return nil, nil, storageError(err)
+	}
+
+	byID := make(map[string]repo.Customer, len(rows))
+	for _, c := range rows {
+		byID[c.ID] = c
+	}
+	for _, id := range unique {
+		if c, ok := byID[id]; ok {
+			found = append(found, c)
+		} else {
+			notFound = append(notFound, id)
+		}
+	}
+	return found, notFound, nil
+}
+
+type UpdateCustomerInput struct {
+	ID      string
+	Profile repo.Profile
+	// Mask — какие поля профиля менять; пустой — все
+	Mask []string
+}
+
+var profileFields = []string{repo.FieldName, repo.FieldPhone, repo.FieldEmail, repo.FieldLegalAddress}
+
+func (s *Service) UpdateCustomer(ctx context.Context, in UpdateCustomerInput) (*repo.Customer, error) {
+	if _, err := uuid.Parse(in.ID); err != nil {
+		return nil, invalidField("id", "must be a UUID")
+	}
+
+	mask := in.Mask
+	if len(mask) == 0 {
+		mask = profileFields
+	}
+	for _, f := range mask {
+		if !contains(profileFields, f) {
+			return nil, invalidField("update_mask", "unknown field %q", f)
+		}
+	}
+
+	p, err := normalizeProfile(in.Profile)
+	if err != nil {
+		return nil, err
+	}
+
+	c, err := s.repo.Update(ctx, in.ID, p, mask)
+	if errors.Is(err, repo.ErrNotFound) {
+		return nil, ErrNotFound
+	}
+	return c, storageError(err)
+}
+
+type ListCustomersInput struct {
+	Type      string
+	Name      string
+	PageSize  int
+	PageToken string
+}
+
+type ListCustomersResult struct {
+	Customers []repo.Customer
+	// NextPageToken пустой на последней странице
+	NextPageToken string
+}
+
+type pageToken struct {
+	CreatedAt time.Time `json:"c"`
+	ID        string    `json:"i"`
+}
+
+func (s *Service) ListCustomers(ctx context.Context, in ListCustomersInput) (*ListCustomersResult, error) {
+	f := repo.ListFilter{
+		Type:         in.Type,
+		NameContains: strings.TrimSpace(in.Name),
+		Limit:        in.PageSize,
+	}
+
+	switch {
+	case f.Limit == 0:
+		f.Limit = DefaultPageSize
+	case f.Limit < 0 || f.Limit > MaxPageSize:
+		return nil, invalidField("page_size", "must be between 1 and %d", MaxPageSize)
+	}
+
+	if in.PageToken != "" {
+		var t pageToken
+		b, err := base64.RawURLEncoding.DecodeString(in.PageToken)
+		if err == nil {
+			err = json.Unmarshal(b, &t)
+		}
+		if err != nil {
+			return nil, invalidField("page_token", "malformed token")
+		}
+		f.After = &repo.ListCursor{CreatedAt: t.CreatedAt, ID: t.ID}
+	}
+
+	limit := f.Limit
+	f.Limit++
+	rows, err := s.repo.List(ctx, f)
+	if err != nil {
+		return nil, storageError(err)
+	}
+
+	res := &ListCustomersResult{Customers: rows}
+	if len(rows) > limit {
+		res.Customers = rows[:limit]
+		last := rows[limit-1]
+		b, _ := json.Marshal(pageToken{CreatedAt: last.CreatedAt, ID: last.ID})
+		res.NextPageToken = base64.RawURLEncoding.EncodeToString(b)
+	}
+	return res, nil
+}
+
+func customerType(info *idn.Info) string {
+	if info.IsLegalEntity() {
+		return repo.TypeLegalEntity
+	}
+	return repo.TypeIndividual
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
 }
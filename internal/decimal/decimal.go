@@ -0,0 +1,81 @@
+// Package decimal — десятичные числа с фиксированным числом знаков после
+// запятой, хранящиеся как int64 в наименьших единицах (тиыны, граммы).
+package decimal
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrFormat    = errors.New("must be a decimal number like 1234.50")
+	ErrPrecision = errors.New("has too many fractional digits")
+	ErrOverflow  = errors.New("is out of range")
+)
+
+// ParseFixed разбирает "-1234.5" в целое число единиц 10^-scale:
+// ParseFixed("12.5", 3) == 12500. Экспонента и разделители разрядов не допускаются,
+// лишние значащие дробные знаки — ErrPrecision (без округления).
+func ParseFixed(s string, scale int) (int64, error) {
+	s = strings.TrimSpace(s)
+	neg := false
+	if strings.HasPrefix(s, "-") || strings.HasPrefix(s, "+") {
+		neg = s[0] == '-'
+		s = s[1:]
+	}
+	intPart, frac, hasDot := strings.Cut(s, ".")
+	if intPart == "" || (hasDot && frac == "") || !digitsOnly(intPart) || !digitsOnly(frac) {
+		return 0, ErrFormat
+	}
+
+	frac = strings.TrimRight(frac, "0")
+	if len(frac) > scale {
+		return 0, ErrPrecision
+	}
+	frac += strings.Repeat("0", scale-len(frac))
+
+	v, err := strconv.ParseInt(intPart+frac, 10, 64)
+	if err != nil {
+		return 0, ErrOverflow
+	}
+	if neg {
+		v = -v
+	}
+	return v, nil
+}
+
+// FormatFixed — обратное к ParseFixed, ровно scale знаков после запятой
+func FormatFixed(v int64, scale int) string {
+	u := uint64(v)
+	sign := ""
+	if v < 0 {
+		sign = "-"
+		u = -u
+	}
+	digits := strconv.FormatUint(u, 10)
+	if scale == 0 {
+		return sign + digits
+	}
+	if len(digits) <= scale {
+		digits = strings.Repeat("0", scale-len(digits)+1) + digits
+	}
+	return sign + digits[:len(digits)-scale] + "." + digits[len(digits)-scale:]
+}
+
+// Trim убирает незначащие нули дробной части: "12.500" → "12.5", "3.000" → "3"
+func Trim(s string) string {
+	if !strings.Contains(s, ".") {
+		return s
+	}
+	return strings.TrimSuffix(strings.TrimRight(s, "0"), ".")
+}
+
+func digitsOnly(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
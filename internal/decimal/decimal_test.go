@@ -0,0 +1,134 @@
+package decimal
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestParseFixed(t *testing.T) {
+	tests := []struct {
+		in    string
+		scale int
+		want  int64
+	}{
+		{"12.5", 3, 12500},
+		{"1234.50", 2, 123450},
+		{"0", 2, 0},
+		{"0.05", 2, 5},
+		{"-0.05", 2, -5},
+		{"-1234.5", 2, -123450},
+		{"+7.25", 2, 725},
+		{"-0", 2, 0},
+		{" 12.50 ", 2, 1250},
+		{"007", 0, 7},
+		// незначащие нули сверх scale — не потеря точности
+		{"1.2300", 2, 123},
+		{"3.000", 0, 3},
+		{"9223372036854775807", 0, math.MaxInt64},
+		{"92233720368547758.07", 2, math.MaxInt64},
+		{"-92233720368547758.07", 2, -math.MaxInt64},
+	}
+	for _, tt := range tests {
+		got, err := ParseFixed(tt.in, tt.scale)
+		if err != nil || got != tt.want {
+			t.Errorf("ParseFixed(%q, %d) = %d, %v; want %d", tt.in, tt.scale, got, err, tt.want)
+		}
+	}
+}
+
+func TestParseFixedErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		in    string
+		scale int
+		want  error
+	}{
+		{"empty", "", 2, ErrFormat},
+		{"sign only", "-", 2, ErrFormat},
+		{"double sign", "--1", 2, ErrFormat},
+		{"no integer part", ".5", 2, ErrFormat},
+		{"no fraction after dot", "5.", 2, ErrFormat},
+		{"two dots", "1.2.3", 2, ErrFormat},
+		{"exponent", "1e3", 2, ErrFormat},
+		{"exponent with fraction", "1.5E2", 2, ErrFormat},
+		{"thousands separator", "1,000.00", 2, ErrFormat},
+		{"space inside", "1 000", 2, ErrFormat},
+		{"decimal comma", "12,50", 2, ErrFormat},
+		{"letters", "abc", 2, ErrFormat},
+		{"inf", "Inf", 2, ErrFormat},
+		// лишние значащие знаки не округляются
+		{"too many fractional digits", "1.005", 2, ErrPrecision},
+		{"negative with too many digits", "-0.001", 2, ErrPrecision},
+		{"fraction at scale 0", "1.5", 0, ErrPrecision},
+		{"int64 overflow", "9223372036854775808", 0, ErrOverflow},
+		{"negative int64 overflow", "-9223372036854775809", 0, ErrOverflow},
+		{"overflow after scaling", "92233720368547758.08", 2, ErrOverflow},
+		// "12" и 18 знаков дроби — 20 цифр, больше int64
+		{"scale overflow", "12.5", 18, ErrOverflow},
+		{"large scale", "1", 19, ErrOverflow},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, err := ParseFixed(tt.in, tt.scale); !errors.Is(err, tt.want) {
+				t.Errorf("ParseFixed(%q, %d) = %d, %v; want %v", tt.in, tt.scale, got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatFixed(t *testing.T) {
+	tests := []struct {
+		v     int64
+		scale int
+		want  string
+	}{
+		{0, 2, "0.00"},
+		{5, 2, "0.05"},
+		{-5, 2, "-0.05"},
+		{100, 2, "1.00"},
+		{123450, 2, "1234.50"},
+		{-123450, 2, "-1234.50"},
+		{12500, 3, "12.500"},
+		{123, 0, "123"},
+		{-123, 0, "-123"},
+		{math.MaxInt64, 2, "92233720368547758.07"},
+		// -MinInt64 в int64 не помещается
+		{math.MinInt64, 2, "-92233720368547758.08"},
+		{math.MinInt64, 0, "-9223372036854775808"},
+	}
+	for _, tt := range tests {
+		if got := FormatFixed(tt.v, tt.scale); got != tt.want {
+			t.Errorf("FormatFixed(%d, %d) = %q, want %q", tt.v, tt.scale, got, tt.want)
+		}
+	}
+}
+
+func TestFormatParseRoundTrip(t *testing.T) {
+	for _, v := range []int64{0, 1, -1, 99, -100, 123456789, math.MaxInt64, -math.MaxInt64} {
+		for _, scale := range []int{0, 2, 3} {
+			s := FormatFixed(v, scale)
+			if got, err := ParseFixed(s, scale); err != nil || got != v {
+				t.Errorf("ParseFixed(FormatFixed(%d, %d) = %q) = %d, %v", v, scale, s, got, err)
+			}
+		}
+	}
+}
+
+func TestTrim(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"12.500", "12.5"},
+		{"3.000", "3"},
+		{"-0.50", "-0.5"},
+		{"100", "100"},
+		{"0.00", "0"},
+	}
+	for _, tt := range tests {
+		if got := Trim(tt.in); got != tt.want {
+			t.Errorf("Trim(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,269 @@
+// Package idn разбирает и проверяет казахстанские идентификационные номера:
+// ИИН (физические лица) и БИН (юридические лица). Оба — 12 цифр, последняя
+// из которых контрольная.
+package idn
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	ErrFormat   = errors.New("idn must be exactly 12 digits")
+	ErrChecksum = errors.New("idn control digit mismatch")
+	ErrDate     = errors.New("idn contains an invalid date")
+	ErrType     = errors.New("idn contains an invalid type digit")
+)
+
+// Kind — чей это номер
+type Kind int
+
+const (
+	KindIndividual  Kind = iota + 1 // ИИН
+	KindLegalEntity                 // БИН
+)
+
+func (k Kind) String() string {
+	switch k {
+	case KindIndividual:
+		return "INDIVIDUAL"
+	case KindLegalEntity:
+		return "LEGAL_ENTITY"
+	}
+	return "UNKNOWN"
+}
+
+type Gender int
+
+const (
+	GenderUnknown Gender = iota
+	GenderMale
+	GenderFemale
+)
+
+func (g Gender) String() string {
+	switch g {
+	case GenderMale:
+		return "MALE"
+	case GenderFemale:
+		return "FEMALE"
+	}
+	return "UNKNOWN"
+}
+
+// EntityType — 5-я цифра БИН
+type EntityType int
+
+const (
+	EntityResident    EntityType = 4 // юридическое лицо-резидент
+	EntityNonResident EntityType = 5 // юридическое лицо-нерезидент
+	EntityJointIE     EntityType = 6 // ИП, осуществляющее совместное предпринимательство
+)
+
+func (t EntityType) String() string {
+	switch t {
+	case EntityResident:
+		return "RESIDENT"
+	case EntityNonResident:
+		return "NON_RESIDENT"
+	case EntityJointIE:
+		return "JOINT_ENTREPRENEUR"
+	}
+	return "UNKNOWN"
+}
+
+// Division — 6-я цифра БИН
+type Division int
+
+const (
+	DivisionHeadOffice     Division = 0 // головное подразделение
+	DivisionBranch         Division = 1 // филиал
+	DivisionRepresentative Division = 2 // представительство
+	DivisionPeasantFarm    Division = 3 // крестьянское (фермерское) хозяйство
+)
+
+func (d Division) String() string {
+	switch d {
+	case DivisionHeadOffice:
+		return "HEAD_OFFICE"
+	case DivisionBranch:
+		return "BRANCH"
+	case DivisionRepresentative:
+		return "REPRESENTATIVE_OFFICE"
+	case DivisionPeasantFarm:
+		return "PEASANT_FARM"
+	}
+	return "UNKNOWN"
+}
+
+// Info — разобранный ИИН или БИН. Поля заполняются в зависимости от Kind.
+type Info struct {
+	Number string
+	Kind   Kind
+
+	// ИИН
+	BirthDate time.Time
+	Gender    Gender
+
+	// БИН: год и месяц регистрации (день всегда 1)
+	RegisteredAt time.Time
+	EntityType   EntityType
+	Division     Division
+}
+
+func (i *Info) IsIndividual() bool  { return i.Kind == KindIndividual }
+func (i *Info) IsLegalEntity() bool { return i.Kind == KindLegalEntity }
+
+// Parse проверяет формат, контрольную цифру и даты и возвращает разобранный номер
+func Parse(s string) (*Info, error) {
+	d, err := digits(s)
+	if err != nil {
+		return nil, err
+	}
+	if c, ok := controlDigit(d); !ok || c != d[11] {
+		return nil, ErrChecksum
+	}
+
+	// У ИИН 5-я цифра — десятки дня рождения (0–3), у БИН — тип лица (4–6)
+	if d[4] >= 4 {
+		return parseBIN(s, d)
+	}
+	return parseIIN(s, d)
+}
+
+// Validate — то же, что Parse, когда разобранные данные не нужны
+func Validate(s string) error {
+	_, err := Parse(s)
+	return err
+}
+
+// ValidChecksum проверяет только формат и контрольную цифру
+func ValidChecksum(s string) bool {
+	d, err := digits(s)
+	if err != nil {
+		return false
+	}
+	c, ok := controlDigit(d)
+	return ok && c == d[11]
+}
+
+func digits(s string) ([12]int, error) {
+	var d [12]int
+	if len(s) != 12 {
+		return d, ErrFormat
+	}
+	for i := 0; i < 12; i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return d, ErrFormat
+		}
+		d[i] = int(s[i] - '0')
+	}
+	return d, nil
+}
+
+// controlDigit считает контрольную цифру: сумма первых 11 цифр с весами 1..11
+// по модулю 11; если получилось 10 — второй проход с весами 3..11,1,2; если
+// снова 10, номер недействителен (ok == false).
+func controlDigit(d [12]int) (int, bool) {
+	sum := 0
+	for i := 0; i < 11; i++ {
+		sum += d[i] * (i + 1)
+	}
+	if c := sum % 11; c != 10 {
+		return c, true
+	}
+
+	sum = 0
+	for i := 0; i < 11; i++ {
+		sum += d[i] * ((i+2)%11 + 1)
+	}
+	if c := sum % 11; c != 10 {
+		return c, true
+	}
+	return 0, false
+}
+
+func parseIIN(s string, d [12]int) (*Info, error) {
+	yy := d[0]*10 + d[1]
+	mm := d[2]*10 + d[3]
+	dd := d[4]*10 + d[5]
+
+	info := &Info{Number: s, Kind: KindIndividual}
+
+	// 7-я цифра: век рождения и пол (нечётные — мужской)
+	var century int
+	switch c := d[6]; c {
+	case 1, 2:
+		century = 1800
+	case 3, 4:
+		century = 1900
+	case 5, 6:
+		century = 2000
+	case 0:
+		// иностранные граждане — век и пол не указаны
+		century = guessCentury(yy)
+	default:
+		return nil, fmt.Errorf("%w: century/gender digit %d", ErrType, c)
+	}
+	switch {
+	case d[6] == 0:
+		info.Gender = GenderUnknown
+	case d[6]%2 == 1:
+		info.Gender = GenderMale
+	default:
+		info.Gender = GenderFemale
+	}
+
+	birth, ok := date(century+yy, mm, dd)
+	if !ok {
+		return nil, fmt.Errorf("%w: birth date %02d%02d%02d", ErrDate, yy, mm, dd)
+	}
+	if birth.After(time.Now()) {
+		return nil, fmt.Errorf("%w: birth date is in the future", ErrDate)
+	}
+	info.BirthDate = birth
+	return info, nil
+}
+
+func parseBIN(s string, d [12]int) (*Info, error) {
+	yy := d[0]*10 + d[1]
+	mm := d[2]*10 + d[3]
+
+	info := &Info{
+		Number:     s,
+		Kind:       KindLegalEntity,
+		EntityType: EntityType(d[4]),
+		Division:   Division(d[5]),
+	}
+	if info.EntityType > EntityJointIE {
+		return nil, fmt.Errorf("%w: entity type digit %d", ErrType, d[4])
+	}
+	if info.Division > DivisionPeasantFarm {
+		return nil, fmt.Errorf("%w: division digit %d", ErrType, d[5])
+	}
+
+	reg, ok := date(guessCentury(yy)+yy, mm, 1)
+	if !ok {
+		return nil, fmt.Errorf("%w: registration month %02d%02d", ErrDate, yy, mm)
+	}
+	info.RegisteredAt = reg
+	return info, nil
+}
+
+// guessCentury — для двузначного года без признака века: не позже текущего года
+func guessCentury(yy int) int {
+	if 2000+yy > time.Now().Year() {
+		return 1900
+	}
+	return 2000
+}
+
+func date(y, m, d int) (time.Time, bool) {
+	if m < 1 || m > 12 || d < 1 {
+		return time.Time{}, false
+	}
+	t := time.Date(y, time.Month(m), d, 0, 0, 0, 0, time.UTC)
+	// time.Date нормализует 31 февраля в март — такие даты отбрасываем
+	return t, t.Month() == time.Month(m) && t.Day() == d
+}
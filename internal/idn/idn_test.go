@@ -0,0 +1,141 @@
+package idn
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func day(y int, m time.Month, d int) time.Time {
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+func TestParseIIN(t *testing.T) {
+	tests := []struct {
+		name       string
+		in         string
+		wantBirth  time.Time
+		wantGender Gender
+	}{
+		{"male 20th century", "900101300126", day(1990, time.January, 1), GenderMale},
+		{"female 21st century", "050315600348", day(2005, time.March, 15), GenderFemale},
+		{"foreigner without century digit", "750720000010", day(1975, time.July, 20), GenderUnknown},
+		{"male 19th century", "881230100111", day(1888, time.December, 30), GenderMale},
+		// первый проход даёт 10, контрольная цифра — из второго прохода с весами 3..11,1,2
+		{"second weight pass", "900101300811", day(1990, time.January, 1), GenderMale},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info, err := Parse(tt.in)
+			if err != nil {
+				t.Fatalf("Parse(%q) error = %v", tt.in, err)
+			}
+			if info.Kind != KindIndividual {
+				t.Errorf("Kind = %v, want %v", info.Kind, KindIndividual)
+			}
+			if !info.BirthDate.Equal(tt.wantBirth) {
+				t.Errorf("BirthDate = %s, want %s", info.BirthDate, tt.wantBirth)
+			}
+			if info.Gender != tt.wantGender {
+				t.Errorf("Gender = %v, want %v", info.Gender, tt.wantGender)
+			}
+		})
+	}
+}
+
+func TestParseBIN(t *testing.T) {
+	tests := []struct {
+		name         string
+		in           string
+		wantReg      time.Time
+		wantEntity   EntityType
+		wantDivision Division
+	}{
+		{"resident head office", "040540000123", day(2004, time.May, 1), EntityResident, DivisionHeadOffice},
+		{"non-resident branch", "101251000456", day(2010, time.December, 1), EntityNonResident, DivisionBranch},
+		{"joint entrepreneur peasant farm", "150163000781", day(2015, time.January, 1), EntityJointIE, DivisionPeasantFarm},
+		{"registered last century", "881240000115", day(1988, time.December, 1), EntityResident, DivisionHeadOffice},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info, err := Parse(tt.in)
+			if err != nil {
+				t.Fatalf("Parse(%q) error = %v", tt.in, err)
+			}
+			if info.Kind != KindLegalEntity {
+				t.Errorf("Kind = %v, want %v", info.Kind, KindLegalEntity)
+			}
+			if !info.RegisteredAt.Equal(tt.wantReg) {
+				t.Errorf("RegisteredAt = %s, want %s", info.RegisteredAt, tt.wantReg)
+			}
+			if info.EntityType != tt.wantEntity {
+				t.Errorf("EntityType = %v, want %v", info.EntityType, tt.wantEntity)
+			}
+			if info.Division != tt.wantDivision {
+				t.Errorf("Division = %v, want %v", info.Division, tt.wantDivision)
+			}
+		})
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want error
+	}{
+		{"empty", "", ErrFormat},
+		{"too short", "90010130012", ErrFormat},
+		{"too long", "9001013001260", ErrFormat},
+		{"non-digit", "90010130012a", ErrFormat},
+		{"wrong control digit", "900101300127", ErrChecksum},
+		{"invalid century digit", "900101700101", ErrType},
+		{"february 30", "900230300108", ErrDate},
+		{"birth date in the future", "990101500105", ErrDate},
+		{"unknown entity type", "040570000008", ErrType},
+		{"unknown division", "040544000006", ErrType},
+		{"registration month 13", "041340000001", ErrDate},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Parse(tt.in); !errors.Is(err, tt.want) {
+				t.Errorf("Parse(%q) error = %v, want %v", tt.in, err, tt.want)
+			}
+		})
+	}
+}
+
+// Если оба прохода дают 10, номер недействителен при любой последней цифре
+func TestControlDigitTenRejected(t *testing.T) {
+	const prefix = "90010130080"
+	for c := 0; c <= 9; c++ {
+		s := prefix + strconv.Itoa(c)
+		if ValidChecksum(s) {
+			t.Errorf("ValidChecksum(%q) = true, want false", s)
+		}
+		if err := Validate(s); !errors.Is(err, ErrChecksum) {
+			t.Errorf("Validate(%q) error = %v, want %v", s, err, ErrChecksum)
+		}
+	}
+}
+
+func TestValidChecksum(t *testing.T) {
+	tests := []struct {
+		in   string
+		want bool
+	}{
+		{"900101300126", true},
+		{"900101300811", true},
+		// контрольная цифра верна, хотя даты 30 февраля нет
+		{"900230300108", true},
+		{"900101300127", false},
+		{"9001013001", false},
+		{"9001013001x6", false},
+	}
+	for _, tt := range tests {
+		if got := ValidChecksum(tt.in); got != tt.want {
+			t.Errorf("ValidChecksum(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,302 @@
+// Package location — встроенный справочник городов Казахстана: коды КАТО,
+// области, координаты и варианты написания (латиница, русский, казахский)
+// с нечётким поиском.
+package location
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+)
+
+var (
+	ErrNotFound  = errors.New("location not found")
+	ErrAmbiguous = errors.New("location is ambiguous")
+)
+
+//go:embed data/kz.json
+var embedded []byte
+
+type Oblast struct {
+	KATO   string `json:"kato"`
+	Name   string `json:"name"`
+	NameRU string `json:"nameRu"`
+	NameKK string `json:"nameKk"`
+}
+
+// City — населённый пункт справочника. Code — канонический код, который
+// хранится в shipment_stops.city_code.
+type City struct {
+	Code string `json:"code"`
+	KATO string `json:"kato"`
+	// Oblast — КАТО области; пустой у городов республиканского значения
+	Oblast    string   `json:"oblast,omitempty"`
+	Name      string   `json:"name"`
+	NameRU    string   `json:"nameRu"`
+	NameKK    string   `json:"nameKk"`
+	Latitude  float64  `json:"lat"`
+	Longitude float64  `json:"lon"`
+	Aliases   []string `json:"aliases,omitempty"`
+}
+
+// Match — результат поиска; Distance — расстояние редактирования между
+// нормализованным запросом и ближайшим вариантом названия (0 — точное совпадение)
+type Match struct {
+	City     *City
+	Distance int
+	// Variant — вариант названия, с которым совпал запрос
+	Variant string
+}
+
+type variant struct {
+	key  string
+	name string
+	city *City
+}
+
+// Directory — неизменяемый после загрузки справочник, безопасен для конкурентного чтения
+type Directory struct {
+	cities   []*City
+	oblasts  map[string]*Oblast
+	byCode   map[string]*City
+	byKATO   map[string]*City
+	byKey    map[string][]*City
+	variants []variant
+}
+
+var (
+	defaultOnce sync.Once
+	defaultDir  *Directory
+)
+
+// Default возвращает справочник из встроенных данных
+func Default() *Directory {
+	defaultOnce.Do(func() {
+		d, err := Load(bytes.NewReader(embedded))
+		if err != nil {
+			panic(fmt.Sprintf("location: embedded data: %v", err))
+		}
+		defaultDir = d
+	})
+	return defaultDir
+}
+
+// Load читает справочник в формате data/kz.json
+func Load(r io.Reader) (*Directory, error) {
+	var raw struct {
+		Oblasts []*Oblast `json:"oblasts"`
+		Cities  []*City   `json:"cities"`
+	}
+	if err := json.NewDecoder(r).Decode(&raw); err != nil {
+		return nil, err
+	}
+
+	d := &Directory{
+		cities:  raw.Cities,
+		oblasts: make(map[string]*Oblast, len(raw.Oblasts)),
+		byCode:  make(map[string]*City, len(raw.Cities)),
+		byKATO:  make(map[string]*City, len(raw.Cities)),
+		byKey:   make(map[string][]*City),
+	}
+	for _, o := range raw.Oblasts {
+		d.oblasts[o.KATO] = o
+	}
+	for _, c := range raw.Cities {
+		if _, dup := d.byCode[c.Code]; dup {
+			return nil, fmt.Errorf("duplicate city code %q", c.Code)
+		}
+		if c.Oblast != "" && d.oblasts[c.Oblast] == nil {
+			return nil, fmt.Errorf("city %s: unknown oblast %q", c.Code, c.Oblast)
+		}
+		d.byCode[c.Code] = c
+		d.byKATO[c.KATO] = c
+
+		seen := map[string]bool{}
+		for _, name := range append([]string{c.Code, c.Name, c.NameRU, c.NameKK}, c.Aliases...) {
+			key := Key(name)
+			if key == "" || seen[key] {
+				continue
+			}
+			seen[key] = true
+			d.byKey[key] = append(d.byKey[key], c)
+			d.variants = append(d.variants, variant{key: key, name: name, city: c})
+		}
+	}
+	sort.Slice(d.cities, func(i, j int) bool { return d.cities[i].Code < d.cities[j].Code })
+	return d, nil
+}
+
+// Cities — все города, отсортированные по коду
+func (d *Directory) Cities() []*City {
+	return d.cities
+}
+
+// City ищет город по каноническому коду
+func (d *Directory) City(code string) (*City, bool) {
+	c, ok := d.byCode[code]
+	return c, ok
+}
+
+// ByKATO ищет город по 9-значному коду КАТО
+func (d *Directory) ByKATO(kato string) (*City, bool) {
+	c, ok := d.byKATO[kato]
+	return c, ok
+}
+
+func (d *Directory) Oblast(kato string) (*Oblast, bool) {
+	o, ok := d.oblasts[kato]
+	return o, ok
+}
+
+// Resolve приводит произвольное написание ("Алматы", "ALMATY", "Almaty city",
+// "г. Алма-Ата", "750000000") к одному городу. Опечатки допускаются в пределах
+// maxDistance; если ближайших городов несколько — ErrAmbiguous.
+func (d *Directory) Resolve(query string) (Match, error) {
+	if c, ok := d.byKATO[strings.TrimSpace(query)]; ok {
+		return Match{City: c, Variant: c.KATO}, nil
+	}
+
+	key := Key(query)
+	if key == "" {
+		return Match{}, ErrNotFound
+	}
+	if cs := d.byKey[key]; len(cs) == 1 {
+		return Match{City: cs[0], Variant: query}, nil
+	} else if len(cs) > 1 {
+		return Match{}, ambiguous(query, cs)
+	}
+
+	limit := maxDistance(key)
+	var best []Match
+	for _, v := range d.variants {
+		dist := distance(key, v.key, limit)
+		if dist > limit {
+			continue
+		}
+		switch {
+		case len(best) == 0 || dist < best[0].Distance:
+			best = []Match{{City: v.city, Distance: dist, Variant: v.name}}
+		case dist == best[0].Distance && !containsCity(best, v.city):
+			best = append(best, Match{City: v.city, Distance: dist, Variant: v.name})
+		}
+	}
+	switch len(best) {
+	case 0:
+		return Match{}, ErrNotFound
+	case 1:
+		return best[0], nil
+	}
+	cs := make([]*City, len(best))
+	for i, m := range best {
+		cs[i] = m.City
+	}
+	return Match{}, ambiguous(query, cs)
+}
+
+// Search — подсказки для автодополнения: сначала точные совпадения и совпадения
+// по префиксу, затем нечёткие; не больше limit городов
+func (d *Directory) Search(query string, limit int) []Match {
+	key := Key(query)
+	if key == "" || limit <= 0 {
+		return nil
+	}
+
+	best := map[*City]Match{}
+	consider := func(m Match) {
+		if cur, ok := best[m.City]; !ok || m.Distance < cur.Distance {
+			best[m.City] = m
+		}
+	}
+	maxDist := maxDistance(key)
+	for _, v := range d.variants {
+		switch {
+		case v.key == key:
+			consider(Match{City: v.city, Distance: 0, Variant: v.name})
+		case strings.HasPrefix(v.key, key):
+			// префикс ранжируется сразу за точным совпадением
+			consider(Match{City: v.city, Distance: 1, Variant: v.name})
+		default:
+			if dist := distance(key, v.key, maxDist); dist <= maxDist {
+				consider(Match{City: v.city, Distance: dist + 1, Variant: v.name})
+			}
+		}
+	}
+
+	out := make([]Match, 0, len(best))
+	for _, m := range best {
+		out = append(out, m)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Distance != out[j].Distance {
+			return out[i].Distance < out[j].Distance
+		}
+		return out[i].City.Code < out[j].City.Code
+	})
+	if len(out) > limit {
+		out = out[:limit]
+	}
+	return out
+}
+
+// Nearest — ближайший к точке город справочника не дальше maxKm
+func (d *Directory) Nearest(lat, lon, maxKm float64) (*City, bool) {
+	p := &City{Latitude: lat, Longitude: lon}
+	var (
+		best *City
+		dist = maxKm
+	)
+	for _, c := range d.cities {
+		if km := DistanceKm(p, c); km <= dist {
+			best, dist = c, km
+		}
+	}
+	return best, best != nil
+}
+
+func containsCity(ms []Match, c *City) bool {
+	for _, m := range ms {
+		if m.City == c {
+			return true
+		}
+	}
+	return false
+}
+
+func ambiguous(query string, cs []*City) error {
+	codes := make([]string, len(cs))
+	for i, c := range cs {
+		codes[i] = c.Code
+	}
+	sort.Strings(codes)
+	return fmt.Errorf("%w: %q matches %s", ErrAmbiguous, query, strings.Join(codes, ", "))
+}
+
+// maxDistance — допустимое число опечаток в зависимости от длины запроса
+func maxDistance(key string) int {
+	switch n := len([]rune(key)); {
+	case n <= 4:
+		return 0
+	case n <= 7:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// DistanceKm — расстояние по большому кругу между городами
+func DistanceKm(a, b *City) float64 {
+	const earthRadiusKm = 6371.0
+	toRad := func(deg float64) float64 { return deg * math.Pi / 180 }
+	dLat := toRad(b.Latitude - a.Latitude)
+	dLon := toRad(b.Longitude - a.Longitude)
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(toRad(a.Latitude))*math.Cos(toRad(b.Latitude))*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadiusKm * math.Asin(math.Sqrt(h))
+}
@@ -0,0 +1,121 @@
+package location
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestResolve(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    string
+		want     string
+		wantDist int
+	}{
+		{"canonical code", "ALMATY", "ALMATY", 0},
+		{"KATO", "750000000", "ALMATY", 0},
+		{"KATO with spaces", " 351010000 ", "KARAGANDA", 0},
+		{"Russian", "Алматы", "ALMATY", 0},
+		{"Russian with prefix", "г. Алма-Ата", "ALMATY", 0},
+		{"Kazakh Cyrillic", "Қарағанды", "KARAGANDA", 0},
+		{"Kazakh Latin", "Aqtóbe", "AKTOBE", 0},
+		{"Kazakh Latin with ş", "Şymkent", "SHYMKENT", 0},
+		{"Russian name of a renamed city", "Усть-Каменогорск", "OSKEMEN", 0},
+		{"city suffix", "Almaty city", "ALMATY", 0},
+		{"Kazakh city suffix", "Алматы қаласы", "ALMATY", 0},
+		{"lower case", "astana", "ASTANA", 0},
+		// 5–7 символов: одна опечатка
+		{"one typo in a short name", "Aktobr", "AKTOBE", 1},
+		{"transposition counts as one", "Atkobe", "AKTOBE", 1},
+		// 8 и больше: две опечатки
+		{"two typos in a long name", "Pavlador", "PAVLODAR", 2},
+		{"two typos in Cyrillic", "Каранада", "KARAGANDA", 2},
+	}
+	d := Default()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := d.Resolve(tt.query)
+			if err != nil {
+				t.Fatalf("Resolve(%q) error = %v", tt.query, err)
+			}
+			if m.City.Code != tt.want || m.Distance != tt.wantDist {
+				t.Errorf("Resolve(%q) = %s (distance %d), want %s (distance %d)",
+					tt.query, m.City.Code, m.Distance, tt.want, tt.wantDist)
+			}
+		})
+	}
+}
+
+func TestResolveNotFound(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+	}{
+		{"empty", ""},
+		{"only stop words", "г. city"},
+		// до 4 символов опечатки не допускаются: "Алма" — не "Алматы"
+		{"short prefix", "Алма"},
+		{"one typo in a 4-letter name", "Aksy"},
+		{"two typos in a short name", "Aktoxx"},
+		{"three typos in a long name", "Pavladorr"},
+		// города нет в справочнике
+		{"missing town", "Shu"},
+		{"missing town in Russian", "Жаркент"},
+		{"unknown KATO", "999999999"},
+	}
+	d := Default()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if m, err := d.Resolve(tt.query); !errors.Is(err, ErrNotFound) {
+				t.Errorf("Resolve(%q) = %v, %v; want %v", tt.query, m.City, err, ErrNotFound)
+			}
+		})
+	}
+}
+
+func TestResolveAmbiguous(t *testing.T) {
+	// на расстоянии 1 и от "Aksu", и от "Aksay"
+	if _, err := Default().Resolve("Aksuy"); !errors.Is(err, ErrAmbiguous) {
+		t.Errorf("Resolve(%q) error = %v, want %v", "Aksuy", err, ErrAmbiguous)
+	}
+}
+
+func TestKey(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"Усть-Каменогорск", "ustkamenogorsk"},
+		{"г. Алматы", "almaty"},
+		{"Almaty city", "almaty"},
+		{"Қонаев", "konaev"},
+		{"Qaraǵandy", "karagandy"},
+		{"  ", ""},
+	}
+	for _, tt := range tests {
+		if got := Key(tt.in); got != tt.want {
+			t.Errorf("Key(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestDistance(t *testing.T) {
+	tests := []struct {
+		a, b  string
+		limit int
+		want  int
+	}{
+		{"almaty", "almaty", 2, 0},
+		{"almaty", "almaty", 0, 0},
+		{"aktobe", "atkobe", 2, 1},
+		{"pavlodar", "pavlador", 2, 2},
+		// превышение limit — limit+1 без полного подсчёта
+		{"pavlodar", "pavladorr", 2, 3},
+		{"shu", "shymkent", 1, 2},
+	}
+	for _, tt := range tests {
+		if got := distance(tt.a, tt.b, tt.limit); got != tt.want {
+			t.Errorf("distance(%q, %q, %d) = %d, want %d", tt.a, tt.b, tt.limit, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package location
+
+import (
+	"strings"
+	"unicode"
+
+	"transline.kz/internal/translit"
+)
+
+// kazakhLatin — буквы казахской латиницы (версии 2017–2021 годов) в
+// упрощённую ASCII-латиницу; вместе с translit.Cyrillic "Қарағанды",
+// "Qaraǵandy" и "Karagandy" дают один ключ
+var kazakhLatin = map[rune]string{
+	'ä': "a", 'á': "a", 'ö': "o", 'ó': "o", 'ü': "u", 'ú': "u", 'ū': "u",
+	'ğ': "g", 'ǵ': "g", 'ı': "i", 'ń': "n", 'ş': "sh", 'ç': "ch", 'q': "k",
+}
+
+// stopWords — слова, которые не относятся к названию: "г. Алматы", "Almaty city",
+// "Алматы қаласы"; сравнение после транслитерации
+var stopWords = map[string]bool{
+	"g":      true,
+	"gor":    true,
+	"gorod":  true,
+	"city":   true,
+	"kalasy": true,
+	"kala":   true,
+	"shahar": true,
+	"kz":     true,
+}
+
+// Key — ключ сравнения: нижний регистр, транслитерация, без служебных слов,
+// пробелов, дефисов и знаков препинания ("Усть-Каменогорск" → "ustkamenogorsk")
+func Key(s string) string {
+	var tokens []string
+	var b strings.Builder
+	flush := func() {
+		if b.Len() > 0 {
+			tokens = append(tokens, b.String())
+			b.Reset()
+		}
+	}
+	for _, r := range strings.ToLower(s) {
+		if t, ok := translit.Cyrillic[r]; ok {
+			b.WriteString(t)
+			continue
+		}
+		if t, ok := kazakhLatin[r]; ok {
+			b.WriteString(t)
+			continue
+		}
+		switch {
+		case r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)):
+			b.WriteRune(r)
+		default:
+			flush()
+		}
+	}
+	flush()
+
+	var out strings.Builder
+	for _, t := range tokens {
+		if !stopWords[t] {
+			out.WriteString(t)
+		}
+	}
+	return out.String()
+}
+
+// distance — расстояние Дамерау–Левенштейна (с транспозицией соседних
+// символов); при превышении limit возвращает limit+1 досрочно
+func distance(a, b string, limit int) int {
+	ra, rb := []rune(a), []rune(b)
+	if d := len(ra) - len(rb); d > limit || -d > limit {
+		return limit + 1
+	}
+
+	prev2 := make([]int, len(rb)+1)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		rowMin := cur[0]
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				cur[j] = min(cur[j], prev2[j-2]+1)
+			}
+			rowMin = min(rowMin, cur[j])
+		}
+		if rowMin > limit {
+			return limit + 1
+		}
+		prev2, prev, cur = prev, cur, prev2
+	}
+	return prev[len(rb)]
+}
@@ -0,0 +1,85 @@
+// Package measure — вес, объём и габариты груза в целых единицах (граммы, см³,
+// мм), чтобы килограммы и кубометры не проходили через float64.
+package measure
+
+import (
+	"fmt"
+
+	"transline.kz/internal/decimal"
+)
+
+// Weight — масса в граммах
+type Weight int64
+
+// Volume — объём в кубических сантиметрах
+type Volume int64
+
+// Length — длина в миллиметрах
+type Length int64
+
+const (
+	Gram     Weight = 1
+	Kilogram Weight = 1000
+
+	CubicCentimetre Volume = 1
+	CubicMetre      Volume = 1_000_000
+
+	Millimetre Length = 1
+	Centimetre Length = 10
+	Metre      Length = 1000
+)
+
+// ParseKg разбирает килограммы с точностью до грамма: "12.5" → 12500 г
+func ParseKg(s string) (Weight, error) {
+	v, err := decimal.ParseFixed(s, 3)
+	if err != nil {
+		return 0, fmt.Errorf("weight %w (kg, up to 3 decimals)", err)
+	}
+	return Weight(v), nil
+}
+
+// Kg — масса в килограммах без незначащих нулей: "12.5"
+func (w Weight) Kg() string {
+	return decimal.Trim(decimal.FormatFixed(int64(w), 3))
+}
+
+// ParseM3 разбирает кубометры с точностью до см³: "0.25" → 250000 см³
+func ParseM3(s string) (Volume, error) {
+	v, err := decimal.ParseFixed(s, 6)
+	if err != nil {
+		return 0, fmt.Errorf("volume %w (m3, up to 6 decimals)", err)
+	}
+	return Volume(v), nil
+}
+
+// M3 — объём в кубометрах без незначащих нулей: "0.25"
+func (v Volume) M3() string {
+	return decimal.Trim(decimal.FormatFixed(int64(v), 6))
+}
+
+// VolumetricWeight — объёмный вес при плотности kgPerM3 кг/м³
+// (округление вверх до грамма)
+func (v Volume) VolumetricWeight(kgPerM3 int64) Weight {
+	// см³ × (кг/м³) = г × 10^-3 → делим на 1000 с округлением вверх
+	return Weight((int64(v)*kgPerM3 + 999) / 1000)
+}
+
+// ParseCm разбирает сантиметры с точностью до миллиметра: "120.5" → 1205 мм
+func ParseCm(s string) (Length, error) {
+	v, err := decimal.ParseFixed(s, 1)
+	if err != nil {
+		return 0, fmt.Errorf("length %w (cm, up to 1 decimal)", err)
+	}
+	return Length(v), nil
+}
+
+// Cm — длина в сантиметрах без незначащих нулей: "120.5"
+func (l Length) Cm() string {
+	return decimal.Trim(decimal.FormatFixed(int64(l), 1))
+}
+
+// BoxVolume — объём параллелепипеда (округление вверх до см³)
+func BoxVolume(l, w, h Length) Volume {
+	// мм³ → см³
+	return Volume((int64(l)*int64(w)*int64(h) + 999) / 1000)
+}
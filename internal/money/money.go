@@ -0,0 +1,184 @@
+// Package money — денежные суммы без плавающей точки: целое число минорных
+// единиц валюты (тиынов, центов) и код валюты ISO 4217.
+package money
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strings"
+
+	"transline.kz/internal/decimal"
+)
+
+var (
+	ErrUnknownCurrency  = errors.New("unknown currency")
+	ErrAmountFormat     = errors.New("amount must be a decimal number like 1234.50")
+	ErrPrecision        = errors.New("amount has more fractional digits than the currency allows")
+	ErrOverflow         = errors.New("amount is out of range")
+	ErrCurrencyMismatch = errors.New("currency mismatch")
+)
+
+// Currency — трёхбуквенный код ISO 4217
+type Currency string
+
+const (
+	KZT Currency = "KZT"
+	RUB Currency = "RUB"
+	USD Currency = "USD"
+	EUR Currency = "EUR"
+	CNY Currency = "CNY"
+	KGS Currency = "KGS"
+	UZS Currency = "UZS"
+)
+
+// DefaultCurrency — валюта, если клиент её не указал
+const DefaultCurrency = KZT
+
+// exponents — число знаков после запятой (minor unit) по ISO 4217
+var exponents = map[Currency]int{
+	KZT: 2,
+	RUB: 2,
+	USD: 2,
+	EUR: 2,
+	CNY: 2,
+	KGS: 2,
+	UZS: 2,
+}
+
+// ParseCurrency принимает код в любом регистре; пустая строка — DefaultCurrency
+func ParseCurrency(s string) (Currency, error) {
+	s = strings.ToUpper(strings.TrimSpace(s))
+	if s == "" {
+		return DefaultCurrency, nil
+	}
+	c := Currency(s)
+	if _, ok := exponents[c]; !ok {
+		return "", fmt.Errorf("%w %q", ErrUnknownCurrency, s)
+	}
+	return c, nil
+}
+
+// Exponent — число знаков после запятой
+func (c Currency) Exponent() int {
+	return exponents[c]
+}
+
+// Money — сумма в минорных единицах; нулевое значение — 0 без валюты
+type Money struct {
+	minor    int64
+	currency Currency
+}
+
+// FromMinor создаёт сумму из минорных единиц (тиынов для KZT)
+func FromMinor(minor int64, c Currency) Money {
+	return Money{minor: minor, currency: c}
+}
+
+// Parse разбирает десятичную строку вида "-1234.5"; дробных знаков не
+// больше, чем у валюты, без экспоненты и разделителей разрядов
+func Parse(amount string, c Currency) (Money, error) {
+	exp, ok := exponents[c]
+	if !ok {
+		return Money{}, fmt.Errorf("%w %q", ErrUnknownCurrency, c)
+	}
+
+	minor, err := decimal.ParseFixed(amount, exp)
+	switch {
+	case errors.Is(err, decimal.ErrFormat):
+		return Money{}, ErrAmountFormat
+	case errors.Is(err, decimal.ErrPrecision):
+		return Money{}, ErrPrecision
+	case err != nil:
+		return Money{}, ErrOverflow
+	}
+	return Money{minor: minor, currency: c}, nil
+}
+
+// MustParse — Parse для констант; паникует при ошибке
+func MustParse(amount string, c Currency) Money {
+	m, err := Parse(amount, c)
+	if err != nil {
+		panic(fmt.Sprintf("money: %q %s: %v", amount, c, err))
+	}
+	return m
+}
+
+// Minor — сумма в минорных единицах
+func (m Money) Minor() int64 { return m.minor }
+
+func (m Money) Currency() Currency { return m.currency }
+
+func (m Money) IsZero() bool     { return m.minor == 0 }
+func (m Money) IsPositive() bool { return m.minor > 0 }
+func (m Money) IsNegative() bool { return m.minor < 0 }
+
+// Amount — десятичная запись с ровно Exponent() знаками после запятой
+func (m Money) Amount() string {
+	return decimal.FormatFixed(m.minor, m.currency.Exponent())
+}
+
+// String — "1234.50 KZT"
+func (m Money) String() string {
+	return m.Amount() + " " + string(m.currency)
+}
+
+// Cmp сравнивает суммы одной валюты: -1, 0, +1
+func (m Money) Cmp(o Money) (int, error) {
+	if m.currency != o.currency {
+		return 0, fmt.Errorf("%w: %s vs %s", ErrCurrencyMismatch, m.currency, o.currency)
+	}
+	switch {
+	case m.minor < o.minor:
+		return -1, nil
+	case m.minor > o.minor:
+		return 1, nil
+	}
+	return 0, nil
+}
+
+func (m Money) Add(o Money) (Money, error) {
+	if m.currency != o.currency {
+		return Money{}, fmt.Errorf("%w: %s vs %s", ErrCurrencyMismatch, m.currency, o.currency)
+	}
+	sum := m.minor + o.minor
+	if (o.minor > 0 && sum < m.minor) || (o.minor < 0 && sum > m.minor) {
+		return Money{}, ErrOverflow
+	}
+	return Money{minor: sum, currency: m.currency}, nil
+}
+
+func (m Money) Sub(o Money) (Money, error) {
+	if o.minor == math.MinInt64 {
+		return Money{}, ErrOverflow
+	}
+	return m.Add(Money{minor: -o.minor, currency: o.currency})
+}
+
+// MulRat умножает сумму на коэффициент r с округлением до минорной единицы
+// (половина — от нуля): 100.00 × 1/3 = 33.33, 0.05 × 1/2 = 0.03
+func (m Money) MulRat(r *big.Rat) (Money, error) {
+	v := new(big.Rat).Mul(new(big.Rat).SetInt64(m.minor), r)
+	q, rem := new(big.Int).QuoRem(v.Num(), v.Denom(), new(big.Int))
+	// |2·rem| ≥ denom → округляем от нуля
+	if rem.Abs(rem).Lsh(rem, 1).Cmp(v.Denom()) >= 0 {
+		q.Add(q, big.NewInt(int64(v.Sign())))
+	}
+	if !q.IsInt64() {
+		return Money{}, ErrOverflow
+	}
+	return Money{minor: q.Int64(), currency: m.currency}, nil
+}
+
+// Max возвращает большую из сумм одной валюты
+func Max(a, b Money) (Money, error) {
+	c, err := a.Cmp(b)
+	if err != nil {
+		return Money{}, err
+	}
+	if c < 0 {
+		return b, nil
+	}
+	return a, nil
+}
@@ -0,0 +1,157 @@
+package money
+
+import (
+	"errors"
+	"math"
+	"math/big"
+	"testing"
+)
+
+func TestParseCurrency(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Currency
+	}{
+		{"KZT", KZT},
+		{"usd", USD},
+		{" Eur ", EUR},
+		// пустая строка — валюта по умолчанию
+		{"", DefaultCurrency},
+		{"  ", DefaultCurrency},
+	}
+	for _, tt := range tests {
+		got, err := ParseCurrency(tt.in)
+		if err != nil || got != tt.want {
+			t.Errorf("ParseCurrency(%q) = %q, %v; want %q", tt.in, got, err, tt.want)
+		}
+	}
+	for _, in := range []string{"XYZ", "US", "KZTT", "₸", "398"} {
+		if _, err := ParseCurrency(in); !errors.Is(err, ErrUnknownCurrency) {
+			t.Errorf("ParseCurrency(%q) error = %v, want %v", in, err, ErrUnknownCurrency)
+		}
+	}
+}
+
+func TestExponent(t *testing.T) {
+	for _, c := range []Currency{KZT, RUB, USD, EUR, CNY, KGS, UZS} {
+		if got := c.Exponent(); got != 2 {
+			t.Errorf("%s.Exponent() = %d, want 2", c, got)
+		}
+	}
+	if got := Currency("XYZ").Exponent(); got != 0 {
+		t.Errorf("XYZ.Exponent() = %d, want 0", got)
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		amount    string
+		currency  Currency
+		wantMinor int64
+		wantStr   string
+	}{
+		{"1234.50", KZT, 123450, "1234.50 KZT"},
+		{"1234.5", KZT, 123450, "1234.50 KZT"},
+		{"1234", USD, 123400, "1234.00 USD"},
+		{"0.01", EUR, 1, "0.01 EUR"},
+		{"-15.75", RUB, -1575, "-15.75 RUB"},
+		{"-0.05", KZT, -5, "-0.05 KZT"},
+		// нули сверх минорной единицы валюты допустимы
+		{"10.500", KZT, 1050, "10.50 KZT"},
+		{"92233720368547758.07", KZT, math.MaxInt64, "92233720368547758.07 KZT"},
+	}
+	for _, tt := range tests {
+		m, err := Parse(tt.amount, tt.currency)
+		if err != nil {
+			t.Errorf("Parse(%q, %s) error = %v", tt.amount, tt.currency, err)
+			continue
+		}
+		if m.Minor() != tt.wantMinor || m.Currency() != tt.currency || m.String() != tt.wantStr {
+			t.Errorf("Parse(%q, %s) = %s (%d), want %s (%d)", tt.amount, tt.currency, m, m.Minor(), tt.wantStr, tt.wantMinor)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		amount   string
+		currency Currency
+		want     error
+	}{
+		{"unknown currency", "10.00", "XYZ", ErrUnknownCurrency},
+		{"empty currency", "10.00", "", ErrUnknownCurrency},
+		// тиынов два знака, третий значащий не округляется
+		{"below the minor unit", "10.005", KZT, ErrPrecision},
+		{"negative below the minor unit", "-0.001", USD, ErrPrecision},
+		{"exponent", "1e3", KZT, ErrAmountFormat},
+		{"fraction with exponent", "1.5e2", KZT, ErrAmountFormat},
+		{"thousands separator", "1 000.00", KZT, ErrAmountFormat},
+		{"decimal comma", "10,50", KZT, ErrAmountFormat},
+		{"empty", "", KZT, ErrAmountFormat},
+		{"overflow", "92233720368547758.08", KZT, ErrOverflow},
+		{"negative overflow", "-100000000000000000", KZT, ErrOverflow},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if m, err := Parse(tt.amount, tt.currency); !errors.Is(err, tt.want) {
+				t.Errorf("Parse(%q, %q) = %v, %v; want %v", tt.amount, tt.currency, m, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestMulRat(t *testing.T) {
+	tests := []struct {
+		amount string
+		r      *big.Rat
+		want   string
+	}{
+		{"100.00", big.NewRat(1, 3), "33.33"},
+		{"200.00", big.NewRat(1, 3), "66.67"},
+		// половина тиына — от нуля в обе стороны
+		{"0.05", big.NewRat(1, 2), "0.03"},
+		{"-0.05", big.NewRat(1, 2), "-0.03"},
+		{"0.05", big.NewRat(-1, 2), "-0.03"},
+		{"0.01", big.NewRat(1, 2), "0.01"},
+		{"0.01", big.NewRat(1, 3), "0.00"},
+		{"-0.01", big.NewRat(1, 3), "0.00"},
+		// 8000.00 × 12.5%
+		{"8000.00", big.NewRat(125, 1000), "1000.00"},
+		{"7207.13", big.NewRat(125, 1000), "900.89"},
+		{"1234.56", big.NewRat(0, 1), "0.00"},
+	}
+	for _, tt := range tests {
+		got, err := MustParse(tt.amount, KZT).MulRat(tt.r)
+		if err != nil || got != MustParse(tt.want, KZT) {
+			t.Errorf("%s × %s = %v, %v; want %s", tt.amount, tt.r.RatString(), got, err, tt.want)
+		}
+	}
+	if _, err := FromMinor(math.MaxInt64, KZT).MulRat(big.NewRat(2, 1)); !errors.Is(err, ErrOverflow) {
+		t.Errorf("MaxInt64 × 2 error = %v, want %v", err, ErrOverflow)
+	}
+}
+
+func TestAddSub(t *testing.T) {
+	a, b := MustParse("10.50", KZT), MustParse("0.75", KZT)
+	if got, err := a.Add(b); err != nil || got != MustParse("11.25", KZT) {
+		t.Errorf("%s + %s = %v, %v", a, b, got, err)
+	}
+	if got, err := b.Sub(a); err != nil || got != MustParse("-9.75", KZT) {
+		t.Errorf("%s - %s = %v, %v", b, a, got, err)
+	}
+	if _, err := a.Add(MustParse("1", USD)); !errors.Is(err, ErrCurrencyMismatch) {
+		t.Errorf("KZT + USD error = %v, want %v", err, ErrCurrencyMismatch)
+	}
+
+	one := FromMinor(1, KZT)
+	if _, err := FromMinor(math.MaxInt64, KZT).Add(one); !errors.Is(err, ErrOverflow) {
+		t.Errorf("MaxInt64 + 1 error = %v, want %v", err, ErrOverflow)
+	}
+	if _, err := FromMinor(math.MinInt64, KZT).Sub(one); !errors.Is(err, ErrOverflow) {
+		t.Errorf("MinInt64 - 1 error = %v, want %v", err, ErrOverflow)
+	}
+	if _, err := FromMinor(0, KZT).Sub(FromMinor(math.MinInt64, KZT)); !errors.Is(err, ErrOverflow) {
+		t.Errorf("0 - MinInt64 error = %v, want %v", err, ErrOverflow)
+	}
+}
@@ -0,0 +1,42 @@
+package outbox
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// Handler — подписчик Bus. Событие с ошибкой публикуется повторно всем
+// подписчикам, поэтому обработчики должны быть идемпотентны по Event.ID.
+type Handler func(ctx context.Context, ev Event) error
+
+// Bus — Publisher внутри процесса: вызывает подписчиков синхронно в
+// порядке подписки
+type Bus struct {
+	mu   sync.RWMutex
+	subs map[string][]Handler
+}
+
+func NewBus() *Bus {
+	return &Bus{subs: make(map[string][]Handler)}
+}
+
+// Subscribe подписывает h на события eventType; пустой eventType — на все
+func (b *Bus) Subscribe(eventType string, h Handler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.subs[eventType] = append(b.subs[eventType], h)
+}
+
+func (b *Bus) Publish(ctx context.Context, ev Event) error {
+	b.mu.RLock()
+	handlers := append(append([]Handler(nil), b.subs[ev.Type]...), b.subs[""]...)
+	b.mu.RUnlock()
+
+	for i, h := range handlers {
+		if err := h(ctx, ev); err != nil {
+			return fmt.Errorf("bus handler %d: %w", i, err)
+		}
+	}
+	return nil
+}
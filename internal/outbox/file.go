@@ -0,0 +1,38 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"sync"
+)
+
+// FileSink — Publisher, дописывающий конверты событий в файл по одному JSON
+// на строку; для тестов и локальной отладки
+type FileSink struct {
+	mu sync.Mutex
+	f  *os.File
+}
+
+func OpenFileSink(path string) (*FileSink, error) {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	return &FileSink{f: f}, nil
+}
+
+func (s *FileSink) Publish(_ context.Context, ev Event) error {
+	b, err := json.Marshal(ev)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err = s.f.Write(append(b, '\n'))
+	return err
+}
+
+func (s *FileSink) Close() error {
+	return s.f.Close()
+}
@@ -0,0 +1,201 @@
+package outbox
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const natsTimeout = 5 * time.Second
+
+// NATS — Publisher в брокер по текстовому протоколу NATS (сервер NATS или
+// совместимый мост к Kafka). Событие уходит в subject <prefix>.<Event.Type>;
+// если сервер поддерживает заголовки, Nats-Msg-Id = Event.ID позволяет
+// JetStream отбросить повтор. Publish ждёт PONG, то есть сервер принял
+// сообщение. Соединение открывается при первой публикации и после ошибки.
+type NATS struct {
+	addr   string
+	user   string
+	pass   string
+	token  string
+	prefix string
+
+	mu      sync.Mutex
+	conn    net.Conn
+	r       *bufio.Reader
+	headers bool
+}
+
+// NewNATS — rawURL вида nats://[user:pass@|token@]host[:4222]
+func NewNATS(rawURL, subjectPrefix string) (*NATS, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid NATS url: %w", err)
+	}
+	if u.Scheme != "nats" || u.Hostname() == "" {
+		return nil, fmt.Errorf("invalid NATS url %q: want nats://host[:port]", rawURL)
+	}
+	n := &NATS{addr: u.Host, prefix: strings.TrimSuffix(subjectPrefix, ".")}
+	if u.Port() == "" {
+		n.addr = net.JoinHostPort(u.Hostname(), "4222")
+	}
+	if u.User != nil {
+		if pass, ok := u.User.Password(); ok {
+			n.user, n.pass = u.User.Username(), pass
+		} else {
+			n.token = u.User.Username()
+		}
+	}
+	return n, nil
+}
+
+func (n *NATS) Publish(ctx context.Context, ev Event) error {
+	body, err := json.Marshal(ev)
+	if err != nil {
+		return err
+	}
+	subject := ev.Type
+	if n.prefix != "" {
+		subject = n.prefix + "." + ev.Type
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if err := n.publish(ctx, subject, ev.ID.String(), body); err != nil {
+		n.closeConn()
+		return fmt.Errorf("nats: %w", err)
+	}
+	return nil
+}
+
+func (n *NATS) publish(ctx context.Context, subject, msgID string, body []byte) error {
+	if n.conn == nil {
+		if err := n.connect(ctx); err != nil {
+			return err
+		}
+	}
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(natsTimeout)
+	}
+	if err := n.conn.SetDeadline(deadline); err != nil {
+		return err
+	}
+
+	var msg []byte
+	if n.headers {
+		hdr := "NATS/1.0\r\nNats-Msg-Id: " + msgID + "\r\n\r\n"
+		msg = fmt.Appendf(msg, "HPUB %s %d %d\r\n%s", subject, len(hdr), len(hdr)+len(body), hdr)
+	} else {
+		msg = fmt.Appendf(msg, "PUB %s %d\r\n", subject, len(body))
+	}
+	msg = append(msg, body...)
+	msg = append(msg, "\r\nPING\r\n"...)
+	if _, err := n.conn.Write(msg); err != nil {
+		return err
+	}
+	return n.awaitPong()
+}
+
+func (n *NATS) connect(ctx context.Context) error {
+	d := net.Dialer{Timeout: natsTimeout}
+	conn, err := d.DialContext(ctx, "tcp", n.addr)
+	if err != nil {
+		return err
+	}
+	n.conn, n.r = conn, bufio.NewReader(conn)
+	if err := conn.SetDeadline(time.Now().Add(natsTimeout)); err != nil {
+		return err
+	}
+
+	line, err := n.readLine()
+	if err != nil {
+		return err
+	}
+	info, ok := strings.CutPrefix(line, "INFO ")
+	if !ok {
+		return fmt.Errorf("unexpected greeting %q", line)
+	}
+	var server struct {
+		Headers     bool `json:"headers"`
+		TLSRequired bool `json:"tls_required"`
+	}
+	if err := json.Unmarshal([]byte(info), &server); err != nil {
+		return fmt.Errorf("malformed INFO: %w", err)
+	}
+	if server.TLSRequired {
+		return errors.New("server requires TLS, which is not supported")
+	}
+	n.headers = server.Headers
+
+	opts, _ := json.Marshal(struct {
+		Verbose   bool   `json:"verbose"`
+		Pedantic  bool   `json:"pedantic"`
+		Name      string `json:"name"`
+		Lang      string `json:"lang"`
+		Version   string `json:"version"`
+		Protocol  int    `json:"protocol"`
+		Headers   bool   `json:"headers"`
+		User      string `json:"user,omitempty"`
+		Pass      string `json:"pass,omitempty"`
+		AuthToken string `json:"auth_token,omitempty"`
+	}{
+		Name: "transline-outbox", Lang: "go", Version: "1.0", Protocol: 1,
+		Headers: server.Headers, User: n.user, Pass: n.pass, AuthToken: n.token,
+	})
+	if _, err := fmt.Fprintf(conn, "CONNECT %s\r\nPING\r\n", opts); err != nil {
+		return err
+	}
+	// PONG после CONNECT — сервер принял авторизацию
+	return n.awaitPong()
+}
+
+// awaitPong читает ответы сервера до PONG; -ERR — ошибка публикации
+func (n *NATS) awaitPong() error {
+	for {
+		line, err := n.readLine()
+		if err != nil {
+			return err
+		}
+		switch {
+		case line == "PONG":
+			return nil
+		case line == "PING":
+			if _, err := n.conn.Write([]byte("PONG\r\n")); err != nil {
+				return err
+			}
+		case strings.HasPrefix(line, "-ERR"):
+			return fmt.Errorf("server error: %s", strings.TrimSpace(strings.TrimPrefix(line, "-ERR")))
+		}
+		// +OK и повторные INFO пропускаются
+	}
+}
+
+func (n *NATS) readLine() (string, error) {
+	line, err := n.r.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+func (n *NATS) closeConn() {
+	if n.conn != nil {
+		n.conn.Close()
+		n.conn, n.r = nil, nil
+	}
+}
+
+func (n *NATS) Close() error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.closeConn()
+	return nil
+}
@@ -0,0 +1,95 @@
+// Package outbox — transactional outbox: события пишутся в таблицу outbox в
+// той же транзакции, что и изменение данных, а Relay доставляет их в Publisher.
+// Доставка «хотя бы один раз»: получатели отбрасывают повторы по Event.ID.
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// Типы агрегатов
+const (
+	AggregateShipment = "shipment"
+	AggregateCustomer = "customer"
+)
+
+// Типы событий
+const (
+	ShipmentCreated       = "ShipmentCreated"
+	ShipmentStatusChanged = "ShipmentStatusChanged"
+	CustomerUpserted      = "CustomerUpserted"
+	CustomerDeleted       = "CustomerDeleted"
+)
+
+// Event — событие outbox; JSON-представление — конверт, который получают
+// брокер, файл и подписчики
+type Event struct {
+	// Seq — id строки outbox, порядок публикации
+	Seq           int64           `json:"-"`
+	ID            uuid.UUID       `json:"id"`
+	Type          string          `json:"type"`
+	AggregateType string          `json:"aggregateType"`
+	AggregateID   uuid.UUID       `json:"aggregateId"`
+	OccurredAt    time.Time       `json:"occurredAt"`
+	Payload       json.RawMessage `json:"data"`
+}
+
+// Publisher доставляет событие получателям. Ошибка оставляет событие в
+// outbox: Relay повторит его (до RelayConfig.MaxAttempts раз) и все следующие
+// за ним.
+type Publisher interface {
+	Publish(ctx context.Context, ev Event) error
+}
+
+// NewEvent — событие с новым id; data сериализуется в Payload.
+// OccurredAt проставит БД при вставке.
+func NewEvent(eventType, aggregateType string, aggregateID uuid.UUID, data any) (Event, error) {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return Event{}, fmt.Errorf("marshal %s payload: %w", eventType, err)
+	}
+	return Event{
+		ID:            uuid.New(),
+		Type:          eventType,
+		AggregateType: aggregateType,
+		AggregateID:   aggregateID,
+		Payload:       payload,
+	}, nil
+}
+
+// Execer — pgx.Tx; outbox пишется только в транзакции вместе с изменением
+type Execer interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+}
+
+// Insert записывает события одной командой в порядке evs
+func Insert(ctx context.Context, tx Execer, evs ...Event) error {
+	if len(evs) == 0 {
+		return nil
+	}
+	var (
+		ids        = make([]uuid.UUID, len(evs))
+		aggTypes   = make([]string, len(evs))
+		aggIDs     = make([]uuid.UUID, len(evs))
+		eventTypes = make([]string, len(evs))
+		payloads   = make([]string, len(evs))
+	)
+	for i, ev := range evs {
+		ids[i], aggTypes[i], aggIDs[i], eventTypes[i] = ev.ID, ev.AggregateType, ev.AggregateID, ev.Type
+		payloads[i] = string(ev.Payload)
+	}
+	_, err := tx.Exec(ctx, `
+    INSERT INTO outbox (event_id, aggregate_type, aggregate_id, event_type, payload)
+    SELECT t.event_id, t.aggregate_type, t.aggregate_id, t.event_type, t.payload::jsonb
+    FROM unnest($1::uuid[], $2::text[], $3::uuid[], $4::text[], $5::text[])
+      WITH ORDINALITY AS t(event_id, aggregate_type, aggregate_id, event_type, payload, n)
+    ORDER BY t.n
+  `, ids, aggTypes, aggIDs, eventTypes, payloads)
+	return err
+}
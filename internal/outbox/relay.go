@@ -0,0 +1,257 @@
+package outbox
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Значения RelayConfig по умолчанию
+const (
+	DefaultBatchSize   = 100
+	DefaultMaxAttempts = 15
+	DefaultClaimLease  = time.Minute
+)
+
+// Задержка повтора после неудачной публикации: 1s, 2s, 4s … до 10 минут;
+// DefaultMaxAttempts попыток — около часа
+const (
+	retryBaseDelay = time.Second
+	retryMaxDelay  = 10 * time.Minute
+)
+
+// relayLockKey — ключ advisory-блокировки на время захвата пачки: два
+// экземпляра не заберут одни и те же события
+const relayLockKey = 0x6f7574626f78 // "outbox"
+
+type RelayConfig struct {
+	// BatchSize — сколько событий Relay забирает за один проход
+	BatchSize int
+	// MaxAttempts — после стольких неудачных публикаций событие откладывается
+	// (parked_at) и больше не задерживает следующие
+	MaxAttempts int
+	// ClaimLease — на сколько пачка закрепляется за Relay; публикация пачки
+	// должна в него укладываться
+	ClaimLease time.Duration
+}
+
+// claimed — событие пачки с числом прошлых неудачных попыток
+type claimed struct {
+	Event
+	attempts int
+}
+
+// relayStore — состояние outbox в БД; pgStore
+type relayStore interface {
+	claim(ctx context.Context, limit int, lease time.Duration) ([]claimed, error)
+	markPublished(ctx context.Context, seqs []int64) error
+	// markFailed записывает неудачу seq: повтор через retryIn или, если park,
+	// откладывание; release — непубликовавшийся остаток пачки, он снова доступен
+	markFailed(ctx context.Context, seq int64, cause string, retryIn time.Duration, park bool, release []int64) error
+}
+
+type Relay struct {
+	db    *pgxpool.Pool
+	store relayStore
+	pub   Publisher
+	cfg   RelayConfig
+}
+
+func NewRelay(db *pgxpool.Pool, pub Publisher, cfg RelayConfig) *Relay {
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = DefaultBatchSize
+	}
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = DefaultMaxAttempts
+	}
+	if cfg.ClaimLease <= 0 {
+		cfg.ClaimLease = DefaultClaimLease
+	}
+	return &Relay{db: db, store: pgStore{db: db}, pub: pub, cfg: cfg}
+}
+
+// RelayOnce публикует очередную пачку событий по порядку id и возвращает
+// число опубликованных. Пачка забирается короткой транзакцией, публикуется
+// вне её и затем отмечается опубликованной. На первой ошибке Publisher проход
+// останавливается: событие повторяется с нарастающей задержкой, а следующие
+// ждут его, пока оно не будет опубликовано или отложено.
+func (r *Relay) RelayOnce(ctx context.Context) (int64, error) {
+	batch, err := r.store.claim(ctx, r.cfg.BatchSize, r.cfg.ClaimLease)
+	if err != nil || len(batch) == 0 {
+		return 0, err
+	}
+
+	// после истечения аренды пачку может забрать другой экземпляр
+	pubCtx, cancel := context.WithTimeout(ctx, r.cfg.ClaimLease)
+	defer cancel()
+	// учёт пишется и при остановке сервиса: опубликованное не должно уйти повторно
+	bookCtx := context.WithoutCancel(ctx)
+
+	var (
+		done   []int64
+		pubErr error
+	)
+	for i, c := range batch {
+		err := r.pub.Publish(pubCtx, c.Event)
+		if err == nil {
+			done = append(done, c.Seq)
+			continue
+		}
+		if ctx.Err() != nil {
+			// остановка, а не сбой получателя: остаток уйдёт после аренды
+			pubErr = ctx.Err()
+			break
+		}
+
+		attempt := c.attempts + 1
+		park := attempt >= r.cfg.MaxAttempts
+		if park {
+			pubErr = fmt.Errorf("publish %s %s: %w; parked after %d attempts", c.Type, c.ID, err, attempt)
+		} else {
+			pubErr = fmt.Errorf("publish %s %s (attempt %d): %w", c.Type, c.ID, attempt, err)
+		}
+		rest := make([]int64, 0, len(batch)-i-1)
+		for _, c := range batch[i+1:] {
+			rest = append(rest, c.Seq)
+		}
+		if err := r.store.markFailed(bookCtx, c.Seq, err.Error(), retryDelay(attempt), park, rest); err != nil {
+			pubErr = errors.Join(pubErr, err)
+		}
+		break
+	}
+
+	if len(done) > 0 {
+		if err := r.store.markPublished(bookCtx, done); err != nil {
+			// события уйдут повторно после аренды; получатели отбросят их по id
+			return 0, errors.Join(pubErr, err)
+		}
+	}
+	return int64(len(done)), pubErr
+}
+
+// retryDelay — задержка после неудачной попытки n (с 1)
+func retryDelay(n int) time.Duration {
+	if n >= 20 {
+		return retryMaxDelay
+	}
+	return min(retryBaseDelay<<(n-1), retryMaxDelay)
+}
+
+type pgStore struct {
+	db *pgxpool.Pool
+}
+
+// claim закрепляет за вызывающим до limit первых неопубликованных событий на
+// lease. Ничего не забирает, если первое событие ещё ждёт повтора или
+// закреплено за другим экземпляром: иначе события ушли бы не по порядку.
+func (s pgStore) claim(ctx context.Context, limit int, lease time.Duration) ([]claimed, error) {
+	var out []claimed
+	err := pgx.BeginFunc(ctx, s.db, func(tx pgx.Tx) error {
+		var locked bool
+		if err := tx.QueryRow(ctx, `SELECT pg_try_advisory_xact_lock($1)`, relayLockKey).Scan(&locked); err != nil {
+			return err
+		}
+		if !locked {
+			return nil
+		}
+
+		var due bool
+		err := tx.QueryRow(ctx, `
+      SELECT next_attempt_at IS NULL OR next_attempt_at <= now()
+      FROM outbox
+      WHERE published_at IS NULL AND parked_at IS NULL
+      ORDER BY id
+      LIMIT 1
+    `).Scan(&due)
+		switch {
+		case errors.Is(err, pgx.ErrNoRows), err == nil && !due:
+			return nil
+		case err != nil:
+			return err
+		}
+
+		rows, err := tx.Query(ctx, `
+      UPDATE outbox
+      SET next_attempt_at = now() + $2::interval
+      WHERE id IN (
+        SELECT id
+        FROM outbox
+        WHERE published_at IS NULL AND parked_at IS NULL
+        ORDER BY id
+        LIMIT $1
+      )
+      RETURNING id, event_id, event_type, aggregate_type, aggregate_id, occurred_at, payload, attempts
+    `, limit, lease)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+		for rows.Next() {
+			var c claimed
+			if err := rows.Scan(&c.Seq, &c.ID, &c.Type, &c.AggregateType, &c.AggregateID, &c.OccurredAt, &c.Payload, &c.attempts); err != nil {
+				return err
+			}
+			c.OccurredAt = c.OccurredAt.UTC()
+			out = append(out, c)
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+	// RETURNING не гарантирует порядок
+	slices.SortFunc(out, func(a, b claimed) int { return cmp.Compare(a.Seq, b.Seq) })
+	return out, nil
+}
+
+func (s pgStore) markPublished(ctx context.Context, seqs []int64) error {
+	_, err := s.db.Exec(ctx, `
+    UPDATE outbox
+    SET published_at = now(), next_attempt_at = NULL, last_error = NULL
+    WHERE id = ANY($1)
+  `, seqs)
+	return err
+}
+
+func (s pgStore) markFailed(ctx context.Context, seq int64, cause string, retryIn time.Duration, park bool, release []int64) error {
+	return pgx.BeginFunc(ctx, s.db, func(tx pgx.Tx) error {
+		_, err := tx.Exec(ctx, `
+      UPDATE outbox
+      SET attempts = attempts + 1,
+          last_error = $2,
+          next_attempt_at = CASE WHEN $4 THEN NULL ELSE now() + $3::interval END,
+          parked_at = CASE WHEN $4 THEN now() END
+      WHERE id = $1
+    `, seq, cause, retryIn, park)
+		if err != nil || len(release) == 0 {
+			return err
+		}
+		_, err = tx.Exec(ctx, `
+      UPDATE outbox
+      SET next_attempt_at = NULL
+      WHERE id = ANY($1)
+    `, release)
+		return err
+	})
+}
+
+// PurgePublished удаляет события, опубликованные раньше чем olderThan назад
+func (r *Relay) PurgePublished(ctx context.Context, olderThan time.Duration) (int64, error) {
+	if olderThan <= 0 {
+		return 0, errors.New("outbox retention must be positive")
+	}
+	tag, err := r.db.Exec(ctx, `
+    DELETE FROM outbox
+    WHERE published_at < now() - $1::interval
+  `, olderThan)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
@@ -0,0 +1,216 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type failedCall struct {
+	seq     int64
+	retryIn time.Duration
+	park    bool
+	release []int64
+}
+
+// fakeStore — relayStore в памяти: отдаёт заранее заданную пачку и
+// запоминает, что Relay отметил
+type fakeStore struct {
+	batch      []claimed
+	claimErr   error
+	publishErr error
+
+	published []int64
+	failed    []failedCall
+}
+
+func (f *fakeStore) claim(context.Context, int, time.Duration) ([]claimed, error) {
+	return f.batch, f.claimErr
+}
+
+func (f *fakeStore) markPublished(ctx context.Context, seqs []int64) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	f.published = append(f.published, seqs...)
+	return f.publishErr
+}
+
+func (f *fakeStore) markFailed(ctx context.Context, seq int64, _ string, retryIn time.Duration, park bool, release []int64) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	f.failed = append(f.failed, failedCall{seq: seq, retryIn: retryIn, park: park, release: release})
+	return nil
+}
+
+// fakePublisher падает на событиях из failOn
+type fakePublisher struct {
+	failOn map[int64]error
+	got    []int64
+	// cancel вызывается перед публикацией события cancelAt
+	cancel   context.CancelFunc
+	cancelAt int64
+}
+
+func (p *fakePublisher) Publish(ctx context.Context, ev Event) error {
+	if p.cancel != nil && ev.Seq == p.cancelAt {
+		p.cancel()
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	p.got = append(p.got, ev.Seq)
+	return p.failOn[ev.Seq]
+}
+
+func batchOf(attempts map[int64]int, seqs ...int64) []claimed {
+	out := make([]claimed, 0, len(seqs))
+	for _, s := range seqs {
+		out = append(out, claimed{Event: Event{Seq: s, ID: uuid.New(), Type: ShipmentCreated}, attempts: attempts[s]})
+	}
+	return out
+}
+
+func newTestRelay(store relayStore, pub Publisher) *Relay {
+	r := NewRelay(nil, pub, RelayConfig{MaxAttempts: 3})
+	r.store = store
+	return r
+}
+
+func TestRelayOnce(t *testing.T) {
+	errBroker := errors.New("broker down")
+
+	tests := []struct {
+		name          string
+		batch         []claimed
+		failOn        map[int64]error
+		wantN         int64
+		wantErr       bool
+		wantPublished []int64
+		wantFailed    []failedCall
+	}{
+		{
+			name: "nothing claimed",
+		},
+		{
+			name:          "whole batch published in order",
+			batch:         batchOf(nil, 1, 2, 3),
+			wantN:         3,
+			wantPublished: []int64{1, 2, 3},
+		},
+		{
+			name:          "failure stops the batch and releases the rest",
+			batch:         batchOf(nil, 1, 2, 3, 4),
+			failOn:        map[int64]error{2: errBroker},
+			wantN:         1,
+			wantErr:       true,
+			wantPublished: []int64{1},
+			wantFailed:    []failedCall{{seq: 2, retryIn: time.Second, release: []int64{3, 4}}},
+		},
+		{
+			name:       "retry delay grows with attempts",
+			batch:      batchOf(map[int64]int{1: 1}, 1, 2),
+			failOn:     map[int64]error{1: errBroker},
+			wantErr:    true,
+			wantFailed: []failedCall{{seq: 1, retryIn: 2 * time.Second, release: []int64{2}}},
+		},
+		{
+			name:       "last attempt parks the event",
+			batch:      batchOf(map[int64]int{1: 2}, 1, 2),
+			failOn:     map[int64]error{1: errBroker},
+			wantErr:    true,
+			wantFailed: []failedCall{{seq: 1, retryIn: 4 * time.Second, park: true, release: []int64{2}}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := &fakeStore{batch: tt.batch}
+			pub := &fakePublisher{failOn: tt.failOn}
+			n, err := newTestRelay(store, pub).RelayOnce(context.Background())
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("RelayOnce() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if n != tt.wantN {
+				t.Errorf("RelayOnce() = %d, want %d", n, tt.wantN)
+			}
+			if !slices.Equal(store.published, tt.wantPublished) {
+				t.Errorf("published = %v, want %v", store.published, tt.wantPublished)
+			}
+			if len(store.failed) != len(tt.wantFailed) {
+				t.Fatalf("failed = %+v, want %+v", store.failed, tt.wantFailed)
+			}
+			for i, f := range store.failed {
+				w := tt.wantFailed[i]
+				if f.seq != w.seq || f.retryIn != w.retryIn || f.park != w.park || !slices.Equal(f.release, w.release) {
+					t.Errorf("failed[%d] = %+v, want %+v", i, f, w)
+				}
+			}
+		})
+	}
+}
+
+func TestRelayOnceClaimError(t *testing.T) {
+	errDB := errors.New("db down")
+	pub := &fakePublisher{}
+	n, err := newTestRelay(&fakeStore{claimErr: errDB}, pub).RelayOnce(context.Background())
+	if !errors.Is(err, errDB) || n != 0 {
+		t.Errorf("RelayOnce() = %d, %v; want 0, %v", n, err, errDB)
+	}
+	if len(pub.got) != 0 {
+		t.Errorf("published %v without a claim", pub.got)
+	}
+}
+
+// Ошибка отметки после публикации возвращается: события уйдут повторно
+func TestRelayOnceMarkPublishedError(t *testing.T) {
+	errDB := errors.New("db down")
+	store := &fakeStore{batch: batchOf(nil, 1, 2), publishErr: errDB}
+	n, err := newTestRelay(store, &fakePublisher{}).RelayOnce(context.Background())
+	if !errors.Is(err, errDB) || n != 0 {
+		t.Errorf("RelayOnce() = %d, %v; want 0, %v", n, err, errDB)
+	}
+}
+
+// Остановка сервиса — не неудача публикации: попытка не засчитывается,
+// а уже опубликованное всё равно отмечается
+func TestRelayOnceShutdown(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	store := &fakeStore{batch: batchOf(nil, 1, 2, 3)}
+	pub := &fakePublisher{cancel: cancel, cancelAt: 2}
+
+	n, err := newTestRelay(store, pub).RelayOnce(ctx)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("RelayOnce() error = %v, want %v", err, context.Canceled)
+	}
+	if n != 1 || !slices.Equal(store.published, []int64{1}) {
+		t.Errorf("RelayOnce() = %d, published %v; want 1, [1]", n, store.published)
+	}
+	if len(store.failed) != 0 {
+		t.Errorf("failed = %+v, want none", store.failed)
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	tests := []struct {
+		n    int
+		want time.Duration
+	}{
+		{1, time.Second},
+		{2, 2 * time.Second},
+		{10, 512 * time.Second},
+		{11, retryMaxDelay},
+		{64, retryMaxDelay},
+	}
+	for _, tt := range tests {
+		if got := retryDelay(tt.n); got != tt.want {
+			t.Errorf("retryDelay(%d) = %v, want %v", tt.n, got, tt.want)
+		}
+	}
+}
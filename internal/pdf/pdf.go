@@ -0,0 +1,232 @@
+// Package pdf — минимальный генератор PDF 1.4 для печатных форм: страницы
+// произвольного размера, текст стандартными шрифтами Helvetica, линии и
+// прямоугольники. Координаты — миллиметры от левого верхнего угла страницы.
+//
+// Стандартные шрифты PDF покрывают только латиницу (WinAnsiEncoding), поэтому
+// текст должен быть в ASCII; остальные символы заменяются на '?'.
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Font — один из встроенных в просмотрщики шрифтов (не внедряется в файл)
+type Font int
+
+const (
+	Regular Font = iota
+	Bold
+)
+
+const ptPerMm = 72 / 25.4
+
+// Document — PDF-документ из страниц; не безопасен для конкурентного использования
+type Document struct {
+	title string
+	pages []*Page
+}
+
+func New(title string) *Document {
+	return &Document{title: title}
+}
+
+// Page — страница; методы рисования дописывают операторы в поток содержимого
+type Page struct {
+	width, height float64 // мм
+	content       bytes.Buffer
+}
+
+// AddPage добавляет страницу размером width×height мм
+func (d *Document) AddPage(width, height float64) *Page {
+	p := &Page{width: width, height: height}
+	d.pages = append(d.pages, p)
+	return p
+}
+
+func (p *Page) Width() float64  { return p.width }
+func (p *Page) Height() float64 { return p.height }
+
+// Text пишет строку; (x, y) — левый конец базовой линии, size — кегль в пунктах
+func (p *Page) Text(x, y float64, f Font, size float64, s string) {
+	fmt.Fprintf(&p.content, "BT /F%d %s Tf %s %s Td (%s) Tj ET\n",
+		int(f)+1, num(size), num(x*ptPerMm), num((p.height-y)*ptPerMm), escape(s))
+}
+
+// TextRight — то же, что Text, но (x, y) — правый конец строки
+func (p *Page) TextRight(x, y float64, f Font, size float64, s string) {
+	p.Text(x-TextWidth(f, size, s), y, f, size, s)
+}
+
+// TextCenter — строка по центру относительно x
+func (p *Page) TextCenter(x, y float64, f Font, size float64, s string) {
+	p.Text(x-TextWidth(f, size, s)/2, y, f, size, s)
+}
+
+// FillRect — закрашенный прямоугольник; gray — 0 (чёрный) … 1 (белый)
+func (p *Page) FillRect(x, y, w, h, gray float64) {
+	fmt.Fprintf(&p.content, "%s g %s %s %s %s re f 0 g\n",
+		num(gray), num(x*ptPerMm), num((p.height-y-h)*ptPerMm), num(w*ptPerMm), num(h*ptPerMm))
+}
+
+// StrokeRect — рамка толщиной lineWidth мм
+func (p *Page) StrokeRect(x, y, w, h, lineWidth float64) {
+	fmt.Fprintf(&p.content, "%s w %s %s %s %s re S\n",
+		num(lineWidth*ptPerMm), num(x*ptPerMm), num((p.height-y-h)*ptPerMm), num(w*ptPerMm), num(h*ptPerMm))
+}
+
+// Line — отрезок толщиной lineWidth мм
+func (p *Page) Line(x1, y1, x2, y2, lineWidth float64) {
+	fmt.Fprintf(&p.content, "%s w %s %s m %s %s l S\n",
+		num(lineWidth*ptPerMm), num(x1*ptPerMm), num((p.height-y1)*ptPerMm),
+		num(x2*ptPerMm), num((p.height-y2)*ptPerMm))
+}
+
+// Dash включает пунктир (штрих и промежуток в мм) для последующих линий; 0 — сплошная
+func (p *Page) Dash(on, off float64) {
+	if on <= 0 {
+		p.content.WriteString("[] 0 d\n")
+		return
+	}
+	fmt.Fprintf(&p.content, "[%s %s] 0 d\n", num(on*ptPerMm), num(off*ptPerMm))
+}
+
+// Bytes собирает документ
+func (d *Document) Bytes() ([]byte, error) {
+	var buf bytes.Buffer
+	if _, err := d.WriteTo(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// WriteTo пишет документ: каталог, дерево страниц, шрифты, страницы с
+// потоками содержимого (Flate) и таблицу перекрёстных ссылок
+func (d *Document) WriteTo(w io.Writer) (int64, error) {
+	if len(d.pages) == 0 {
+		return 0, fmt.Errorf("pdf: document has no pages")
+	}
+	cw := &countingWriter{w: w}
+	var offsets []int64
+	obj := func(body string) {
+		offsets = append(offsets, cw.n)
+		fmt.Fprintf(cw, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	cw.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+
+	// 1 — каталог, 2 — страницы, 3/4 — шрифты, 5 — сведения, далее по два объекта на страницу
+	const firstPage = 6
+	kids := make([]string, len(d.pages))
+	for i := range d.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", firstPage+2*i)
+	}
+	obj("<< /Type /Catalog /Pages 2 0 R >>")
+	obj(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(d.pages)))
+	obj("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	obj("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>")
+	obj(fmt.Sprintf("<< /Title (%s) /Producer (transline) >>", escape(d.title)))
+
+	for i, p := range d.pages {
+		obj(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %s %s] "+
+			"/Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>",
+			num(p.width*ptPerMm), num(p.height*ptPerMm), firstPage+2*i+1))
+
+		var z bytes.Buffer
+		zw := zlib.NewWriter(&z)
+		zw.Write(p.content.Bytes())
+		if err := zw.Close(); err != nil {
+			return cw.n, err
+		}
+		obj(fmt.Sprintf("<< /Length %d /Filter /FlateDecode >>\nstream\n%s\nendstream", z.Len(), z.Bytes()))
+	}
+
+	xref := cw.n
+	fmt.Fprintf(cw, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(cw, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(cw, "trailer\n<< /Size %d /Root 1 0 R /Info 5 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+	return cw.n, cw.err
+}
+
+// TextWidth — ширина строки в мм по метрикам Helvetica
+func TextWidth(f Font, size float64, s string) float64 {
+	widths := &helvetica
+	if f == Bold {
+		widths = &helveticaBold
+	}
+	total := 0
+	// по символам, а не байтам: escape заменяет каждый не-ASCII символ одним '?'
+	for _, c := range s {
+		if c < 32 || c > 126 {
+			c = '?'
+		}
+		total += widths[c-32]
+	}
+	return float64(total) * size / 1000 / ptPerMm
+}
+
+// escape экранирует строку PDF и заменяет символы вне ASCII
+func escape(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r < 32 || r > 126:
+			b.WriteByte('?')
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+func num(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+type countingWriter struct {
+	w   io.Writer
+	n   int64
+	err error
+}
+
+func (c *countingWriter) Write(b []byte) (int, error) {
+	if c.err != nil {
+		return 0, c.err
+	}
+	n, err := c.w.Write(b)
+	c.n += int64(n)
+	c.err = err
+	return n, err
+}
+
+func (c *countingWriter) WriteString(s string) {
+	c.Write([]byte(s))
+}
+
+// Ширины символов 32..126 в тысячных долях кегля (Adobe Font Metrics)
+var helvetica = [95]int{
+	278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556,
+	1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778,
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556,
+	333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556,
+	556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584,
+}
+
+var helveticaBold = [95]int{
+	278, 333, 474, 556, 556, 889, 722, 238, 333, 333, 389, 584, 278, 333, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 333, 333, 584, 584, 584, 611,
+	975, 722, 722, 722, 722, 667, 611, 778, 722, 278, 556, 722, 611, 833, 722, 778,
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 333, 278, 333, 584, 556,
+	333, 556, 611, 556, 611, 556, 333, 611, 611, 278, 278, 556, 278, 889, 611, 611,
+	611, 611, 389, 556, 333, 611, 556, 778, 556, 556, 500, 389, 280, 389, 584,
+}
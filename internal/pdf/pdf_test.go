@@ -0,0 +1,196 @@
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"math"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func testDocument(t *testing.T) []byte {
+	t.Helper()
+	d := New("Waybill (TL1)")
+	p := d.AddPage(25.4, 50.8)
+	p.Text(2.54, 25.4, Bold, 12, "TL(1)")
+	p.FillRect(0, 0, 25.4, 25.4, 0.5)
+	p.Line(0, 50.8, 25.4, 50.8, 0.254)
+	b, err := d.Bytes()
+	if err != nil {
+		t.Fatalf("Bytes() error = %v", err)
+	}
+	return b
+}
+
+var (
+	startxrefRe = regexp.MustCompile(`startxref\n(\d+)\n%%EOF\n$`)
+	xrefEntryRe = regexp.MustCompile(`^(\d{10}) (\d{5}) ([fn]) \n$`)
+)
+
+// parseXref возвращает смещения объектов 1..n из таблицы перекрёстных ссылок
+func parseXref(t *testing.T, doc []byte) []int {
+	t.Helper()
+	m := startxrefRe.FindSubmatch(doc)
+	if m == nil {
+		t.Fatal("startxref trailer not found")
+	}
+	xref, _ := strconv.Atoi(string(m[1]))
+	rest := string(doc[xref:])
+	if !strings.HasPrefix(rest, "xref\n") {
+		t.Fatalf("startxref %d does not point at the xref table", xref)
+	}
+
+	lines := strings.SplitAfter(rest, "\n")
+	var first, count int
+	if _, err := fmt.Sscanf(lines[1], "%d %d\n", &first, &count); err != nil || first != 0 {
+		t.Fatalf("bad xref subsection header %q", lines[1])
+	}
+	var offsets []int
+	for i, line := range lines[2 : 2+count] {
+		// записи ровно по 20 байт: 10 цифр смещения, 5 цифр поколения, тип, пробел и \n
+		e := xrefEntryRe.FindStringSubmatch(line)
+		if e == nil {
+			t.Fatalf("xref entry %d = %q is not 20 bytes", i, line)
+		}
+		if i == 0 {
+			if e[1] != "0000000000" || e[2] != "65535" || e[3] != "f" {
+				t.Errorf("xref entry 0 = %q, want the free list head", line)
+			}
+			continue
+		}
+		off, _ := strconv.Atoi(e[1])
+		offsets = append(offsets, off)
+	}
+	if !strings.Contains(rest, fmt.Sprintf("/Size %d ", count)) {
+		t.Errorf("trailer /Size does not match %d xref entries", count)
+	}
+	return offsets
+}
+
+func TestXrefOffsets(t *testing.T) {
+	doc := testDocument(t)
+	offsets := parseXref(t, doc)
+
+	// каталог, страницы, два шрифта, сведения и страница — строки фиксированные
+	if want := []int{15, 64, 121, 218, 320, 388, 553}; !slices.Equal(offsets, want) {
+		t.Errorf("offsets = %v, want %v", offsets, want)
+	}
+	for i, off := range offsets {
+		if want := fmt.Sprintf("%d 0 obj\n", i+1); !bytes.HasPrefix(doc[off:], []byte(want)) {
+			t.Errorf("object %d: offset %d points at %q", i+1, off, doc[off:min(off+len(want), len(doc))])
+		}
+	}
+}
+
+func TestXrefOffsetsManyPages(t *testing.T) {
+	d := New("labels")
+	for i := range 3 {
+		d.AddPage(100, 150).Text(10, 10, Regular, 10, fmt.Sprintf("page %d", i+1))
+	}
+	doc, err := d.Bytes()
+	if err != nil {
+		t.Fatalf("Bytes() error = %v", err)
+	}
+	offsets := parseXref(t, doc)
+	if len(offsets) != 5+2*3 {
+		t.Fatalf("%d objects, want %d", len(offsets), 5+2*3)
+	}
+	for i, off := range offsets {
+		if want := fmt.Sprintf("%d 0 obj\n", i+1); !bytes.HasPrefix(doc[off:], []byte(want)) {
+			t.Errorf("object %d: offset %d points at %q", i+1, off, doc[off:min(off+len(want), len(doc))])
+		}
+	}
+	if !bytes.Contains(doc, []byte("/Kids [6 0 R 8 0 R 10 0 R] /Count 3")) {
+		t.Error("page tree does not list the three pages")
+	}
+}
+
+func TestContentStream(t *testing.T) {
+	doc := testDocument(t)
+
+	m := regexp.MustCompile(`(?s)<< /Length (\d+) /Filter /FlateDecode >>\nstream\n(.*)\nendstream`).FindSubmatch(doc)
+	if m == nil {
+		t.Fatal("content stream not found")
+	}
+	if n, _ := strconv.Atoi(string(m[1])); n != len(m[2]) {
+		t.Fatalf("/Length %d, stream has %d bytes", n, len(m[2]))
+	}
+	zr, err := zlib.NewReader(bytes.NewReader(m[2]))
+	if err != nil {
+		t.Fatalf("zlib: %v", err)
+	}
+	content, err := io.ReadAll(zr)
+	if err != nil {
+		t.Fatalf("zlib: %v", err)
+	}
+
+	ops := strings.Split(strings.TrimSuffix(string(content), "\n"), "\n")
+	if len(ops) != 3 {
+		t.Fatalf("content = %q, want 3 operators", content)
+	}
+	// 1 дюйм = 25,4 мм = 72 pt; y отсчитывается от нижнего края
+	want := []struct {
+		op   string
+		nums []float64
+	}{
+		{"BT /F2 12 Tf %f %f Td (TL\\(1\\)) Tj ET", []float64{7.2, 72}},
+		{"0.5 g %f %f %f %f re f 0 g", []float64{0, 72, 72, 72}},
+		{"%f w %f %f m %f %f l S", []float64{0.72, 0, 0, 72, 0}},
+	}
+	for i, w := range want {
+		got := make([]float64, len(w.nums))
+		ptrs := make([]any, len(got))
+		for j := range got {
+			ptrs[j] = &got[j]
+		}
+		if _, err := fmt.Sscanf(ops[i], w.op, ptrs...); err != nil {
+			t.Errorf("operator %d = %q, want %q: %v", i, ops[i], w.op, err)
+			continue
+		}
+		for j := range got {
+			if math.Abs(got[j]-w.nums[j]) > 1e-9 {
+				t.Errorf("operator %d = %q: operand %d = %v, want %v", i, ops[i], j, got[j], w.nums[j])
+			}
+		}
+	}
+}
+
+func TestNoPages(t *testing.T) {
+	if _, err := New("empty").Bytes(); err == nil {
+		t.Error("Bytes() of a document without pages must fail")
+	}
+}
+
+func TestTextWidth(t *testing.T) {
+	tests := []struct {
+		f    Font
+		s    string
+		want float64 // в тысячных долях кегля по AFM
+	}{
+		{Regular, "Hello", 722 + 556 + 222 + 222 + 556},
+		{Bold, "Hello", 722 + 556 + 278 + 278 + 611},
+		{Regular, "", 0},
+		// вне ASCII считается как '?'
+		{Regular, "Ж", 556},
+	}
+	for _, tt := range tests {
+		want := tt.want * 10 / 1000 / ptPerMm
+		if got := TextWidth(tt.f, 10, tt.s); math.Abs(got-want) > 1e-9 {
+			t.Errorf("TextWidth(%d, 10, %q) = %v, want %v", tt.f, tt.s, got, want)
+		}
+	}
+}
+
+func TestEscape(t *testing.T) {
+	if got, want := escape(`a(b)c\d`), `a\(b\)c\\d`; got != want {
+		t.Errorf("escape = %q, want %q", got, want)
+	}
+	if got, want := escape("Алматы\n1"), "???????1"; got != want {
+		t.Errorf("escape = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,64 @@
+// Package pgerr — классификация ошибок pgx, общая для сервисов: какие ошибки
+// временные (стоит повторить позже), а какие — нарушения ограничений.
+package pgerr
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// Коды SQLSTATE нарушений ограничений
+const (
+	ForeignKeyViolation = "23503"
+	UniqueViolation     = "23505"
+)
+
+// Transient сообщает, что ошибка вызвана недоступностью или перегрузкой БД,
+// а не самим запросом: конфликт сериализации, дедлок, обрыв соединения,
+// нехватка ресурсов, остановка сервера. Отмена контекста временной не считается.
+func Transient(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch {
+		case pgErr.Code == "40001", pgErr.Code == "40P01", // serialization / deadlock
+			strings.HasPrefix(pgErr.Code, "08"), // connection exception
+			strings.HasPrefix(pgErr.Code, "53"), // insufficient resources
+			strings.HasPrefix(pgErr.Code, "57"): // operator intervention
+			return true
+		}
+		return false
+	}
+
+	var (
+		connErr *pgconn.ConnectError
+		netErr  net.Error
+	)
+	return errors.As(err, &connErr) || errors.As(err, &netErr) || pgconn.SafeToRetry(err)
+}
+
+// Code возвращает SQLSTATE ошибки Postgres или "", если это не *pgconn.PgError
+func Code(err error) string {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return pgErr.Code
+	}
+	return ""
+}
+
+// IsForeignKeyViolation — ссылка на несуществующую строку или удаление используемой
+func IsForeignKeyViolation(err error) bool {
+	return Code(err) == ForeignKeyViolation
+}
+
+// IsUniqueViolation — дубликат по уникальному ключу
+func IsUniqueViolation(err error) bool {
+	return Code(err) == UniqueViolation
+}
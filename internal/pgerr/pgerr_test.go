@@ -0,0 +1,55 @@
+package pgerr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+func TestTransient(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil", nil, false},
+		{"serialization failure", &pgconn.PgError{Code: "40001"}, true},
+		{"deadlock", &pgconn.PgError{Code: "40P01"}, true},
+		{"connection failure", &pgconn.PgError{Code: "08006"}, true},
+		{"too many connections", &pgconn.PgError{Code: "53300"}, true},
+		{"admin shutdown", &pgconn.PgError{Code: "57P01"}, true},
+		{"wrapped admin shutdown", fmt.Errorf("query: %w", &pgconn.PgError{Code: "57P01"}), true},
+		{"unique violation", &pgconn.PgError{Code: UniqueViolation}, false},
+		{"syntax error", &pgconn.PgError{Code: "42601"}, false},
+		{"net error", &net.OpError{Op: "dial", Err: errors.New("connection refused")}, true},
+		{"context canceled", context.Canceled, false},
+		{"deadline exceeded", fmt.Errorf("query: %w", context.DeadlineExceeded), false},
+		{"other", io.ErrUnexpectedEOF, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Transient(tt.err); got != tt.want {
+				t.Errorf("Transient(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConstraintViolations(t *testing.T) {
+	fk := fmt.Errorf("insert: %w", &pgconn.PgError{Code: ForeignKeyViolation})
+	if !IsForeignKeyViolation(fk) || IsUniqueViolation(fk) {
+		t.Errorf("foreign key violation misclassified: %v", fk)
+	}
+	uniq := &pgconn.PgError{Code: UniqueViolation}
+	if !IsUniqueViolation(uniq) || IsForeignKeyViolation(uniq) {
+		t.Errorf("unique violation misclassified: %v", uniq)
+	}
+	if Code(errors.New("plain")) != "" {
+		t.Error("Code of a non-Postgres error must be empty")
+	}
+}
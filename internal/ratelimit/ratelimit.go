@@ -0,0 +1,78 @@
+// Package ratelimit — ограничение частоты запросов по ключу (обычно IP клиента)
+// алгоритмом token bucket в памяти процесса.
+package ratelimit
+
+import (
+	"sync"
+	"time"
+)
+
+// Limiter разрешает до limit запросов за window на ключ с равномерным
+// восполнением; безопасен для конкурентного использования
+type Limiter struct {
+	limit    float64
+	perToken time.Duration
+
+	mu      sync.Mutex
+	buckets map[string]*bucket
+	now     func() time.Time
+}
+
+type bucket struct {
+	tokens float64
+	seen   time.Time
+}
+
+func New(limit int, window time.Duration) *Limiter {
+	return &Limiter{
+		limit:    float64(limit),
+		perToken: window / time.Duration(limit),
+		buckets:  make(map[string]*bucket),
+		now:      time.Now,
+	}
+}
+
+// Limit — размер корзины (запросов за окно)
+func (l *Limiter) Limit() int {
+	return int(l.limit)
+}
+
+// Allow списывает токен ключа; если токенов нет, возвращает false и время
+// до появления следующего
+func (l *Limiter) Allow(key string) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: l.limit, seen: now}
+		l.buckets[key] = b
+	}
+	b.tokens = min(l.limit, b.tokens+float64(now.Sub(b.seen))/float64(l.perToken))
+	b.seen = now
+
+	if b.tokens < 1 {
+		return false, time.Duration((1 - b.tokens) * float64(l.perToken))
+	}
+	b.tokens--
+	return true, 0
+}
+
+// Prune удаляет корзины, которые успели наполниться полностью — они ничем не
+// отличаются от новых; возвращает число удалённых
+func (l *Limiter) Prune() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	full := time.Duration(l.limit) * l.perToken
+	n := 0
+	for key, b := range l.buckets {
+		if now.Sub(b.seen) >= full {
+			delete(l.buckets, key)
+			n++
+		}
+	}
+	return n
+}
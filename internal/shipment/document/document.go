@@ -0,0 +1,132 @@
+// Package document — печатные формы отправления в PDF: транспортная
+// этикетка и накладная. Данные приходят уже отформатированными строками;
+// кириллица транслитерируется, так как стандартные шрифты PDF её не содержат.
+package document
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var ErrUnknownFormat = errors.New("unknown document format")
+
+// Format — формат печати
+type Format string
+
+const (
+	// FormatA4 — лист A4 для офисного принтера
+	FormatA4 Format = "A4"
+	// FormatThermal — термоэтикетка 100×150 мм (4×6")
+	FormatThermal Format = "THERMAL_100X150"
+)
+
+// ParseFormat принимает формат в любом регистре; "thermal" — синоним 100×150
+func ParseFormat(s string) (Format, error) {
+	switch f := Format(strings.ToUpper(strings.TrimSpace(s))); f {
+	case FormatA4, FormatThermal:
+		return f, nil
+	case "THERMAL", "100X150":
+		return FormatThermal, nil
+	}
+	return "", fmt.Errorf("%w %q (A4 or THERMAL_100X150)", ErrUnknownFormat, s)
+}
+
+// pageSize — ширина и высота страницы формата, мм
+func (f Format) pageSize() (float64, float64) {
+	if f == FormatThermal {
+		return 100, 150
+	}
+	return 210, 297
+}
+
+// Party — точка маршрута с адресом и контактом
+type Party struct {
+	City    string
+	Address string
+	Contact string
+	Phone   string
+}
+
+// Item — строка груза; числа уже в виде строк
+type Item struct {
+	Description   string
+	Packaging     string
+	Quantity      int
+	WeightKg      string
+	DimensionsCm  string
+	VolumeM3      string
+	DeclaredValue string
+	HSCode        string
+}
+
+// Customer — плательщик
+type Customer struct {
+	IDN     string
+	Name    string
+	Phone   string
+	Address string
+}
+
+// Shipment — всё, что печатается на этикетке и в накладной
+type Shipment struct {
+	TrackingNumber string
+	ID             string
+	CreatedAt      time.Time
+	Origin         Party
+	Destination    Party
+	Waypoints      []string
+	Items          []Item
+	Pieces         int
+	WeightKg       string
+	VolumeM3       string
+	ChargeableKg   string
+	DeclaredValue  string
+	Price          string
+	Currency       string
+	Customer       Customer
+	// TrackURL — содержимое QR-кода (ссылка на отслеживание или сам трек-номер)
+	TrackURL string
+}
+
+// wrap разбивает текст на строки не шире width мм, не больше maxLines;
+// не поместившееся обрезается многоточием
+func wrap(text string, width float64, measure func(string) float64, maxLines int) []string {
+	var lines []string
+	line := ""
+	for _, word := range strings.Fields(text) {
+		next := word
+		if line != "" {
+			next = line + " " + word
+		}
+		if measure(next) <= width || line == "" {
+			line = next
+			continue
+		}
+		lines = append(lines, line)
+		line = word
+	}
+	if line != "" {
+		lines = append(lines, line)
+	}
+	for i := range lines {
+		lines[i] = truncate(lines[i], width, measure)
+	}
+	if len(lines) > maxLines {
+		lines = lines[:maxLines]
+		lines[maxLines-1] = truncate(lines[maxLines-1]+"...", width, measure)
+	}
+	return lines
+}
+
+// truncate обрезает строку до width мм с многоточием
+func truncate(s string, width float64, measure func(string) float64) string {
+	if measure(s) <= width {
+		return s
+	}
+	for len(s) > 0 && measure(s+"...") > width {
+		s = s[:len(s)-1]
+	}
+	return s + "..."
+}
@@ -0,0 +1,60 @@
+package document
+
+import (
+	"transline.kz/internal/barcode"
+	"transline.kz/internal/pdf"
+)
+
+// drawCode128 рисует штрихкод шириной до width мм (с тихими зонами по 10
+// модулей) и высотой height; возвращает фактическую ширину
+func drawCode128(p *pdf.Page, x, y, width, height float64, data string) (float64, error) {
+	modules, err := barcode.Code128(data)
+	if err != nil {
+		return 0, err
+	}
+	module := width / float64(len(modules)+20)
+	x += 10 * module
+	for i := 0; i < len(modules); {
+		if !modules[i] {
+			i++
+			continue
+		}
+		j := i
+		for j < len(modules) && modules[j] {
+			j++
+		}
+		p.FillRect(x+float64(i)*module, y, float64(j-i)*module, height, 0)
+		i = j
+	}
+	return float64(len(modules)+20) * module, nil
+}
+
+// drawQR рисует QR-код в квадрат size×size мм, включая тихую зону 4 модуля
+func drawQR(p *pdf.Page, x, y, size float64, data string) error {
+	qr, err := barcode.EncodeQR([]byte(data))
+	if err != nil {
+		return err
+	}
+	module := size / float64(qr.Size+8)
+	x, y = x+4*module, y+4*module
+	for row := range qr.Size {
+		for col := 0; col < qr.Size; {
+			if !qr.Dark(col, row) {
+				col++
+				continue
+			}
+			end := col
+			for end < qr.Size && qr.Dark(end, row) {
+				end++
+			}
+			// небольшой нахлёст по вертикали убирает просветы между рядами в просмотрщиках
+			p.FillRect(x+float64(col)*module, y+float64(row)*module, float64(end-col)*module, module*1.02, 0)
+			col = end
+		}
+	}
+	return nil
+}
+
+func measurer(f pdf.Font, size float64) func(string) float64 {
+	return func(s string) float64 { return pdf.TextWidth(f, size, s) }
+}
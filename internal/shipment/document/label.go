@@ -0,0 +1,141 @@
+package document
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"transline.kz/internal/pdf"
+)
+
+// Размер этикетки; на A4 она печатается по центру с линией отреза
+const (
+	labelWidth  = 100.0
+	labelHeight = 150.0
+)
+
+// Label — транспортная этикетка: трек-номер штрихкодом Code 128 и QR-кодом,
+// города и адреса отправления и назначения, вес, объём и число мест
+func Label(s Shipment, f Format) ([]byte, error) {
+	doc := pdf.New("Label " + s.TrackingNumber)
+	pw, ph := f.pageSize()
+	p := doc.AddPage(pw, ph)
+
+	x, y := 0.0, 0.0
+	if f == FormatA4 {
+		x, y = (pw-labelWidth)/2, 20
+		p.Text(x, y-4, pdf.Regular, 7, "Cut along the dashed line and attach to the largest side of the cargo")
+		p.Dash(2, 1.5)
+		p.StrokeRect(x-2, y-2, labelWidth+4, labelHeight+4, 0.2)
+		p.Dash(0, 0)
+	}
+	if err := drawLabel(p, x, y, s); err != nil {
+		return nil, err
+	}
+	return doc.Bytes()
+}
+
+func drawLabel(p *pdf.Page, x, y float64, s Shipment) error {
+	const pad = 4.0
+	inner := labelWidth - 2*pad
+	left := x + pad
+
+	p.StrokeRect(x+1, y+1, labelWidth-2, labelHeight-2, 0.4)
+
+	// шапка
+	p.Text(left, y+9, pdf.Bold, 16, "TRANSLINE")
+	p.TextRight(x+labelWidth-pad, y+9, pdf.Regular, 8, s.CreatedAt.Format("2006-01-02"))
+	p.Line(x+1, y+12, x+labelWidth-1, y+12, 0.3)
+
+	// отправитель
+	p.Text(left, y+16, pdf.Bold, 7, "FROM")
+	p.Text(left, y+21, pdf.Bold, 11, truncate(Latin(s.Origin.City), inner, measurer(pdf.Bold, 11)))
+	ly := y + 25
+	for _, line := range wrap(Latin(s.Origin.Address), inner, measurer(pdf.Regular, 8), 2) {
+		p.Text(left, ly, pdf.Regular, 8, line)
+		ly += 3.5
+	}
+	if c := contactLine(s.Origin); c != "" {
+		p.Text(left, y+35, pdf.Regular, 8, truncate(c, inner, measurer(pdf.Regular, 8)))
+	}
+	p.Line(x+1, y+38, x+labelWidth-1, y+38, 0.3)
+
+	// получатель — крупно, это читают при сортировке
+	p.Text(left, y+42, pdf.Bold, 7, "TO")
+	p.Text(left, y+51, pdf.Bold, 20, truncate(Latin(s.Destination.City), inner, measurer(pdf.Bold, 20)))
+	ly = y + 57
+	for _, line := range wrap(Latin(s.Destination.Address), inner, measurer(pdf.Regular, 9), 2) {
+		p.Text(left, ly, pdf.Regular, 9, line)
+		ly += 4
+	}
+	if c := contactLine(s.Destination); c != "" {
+		p.Text(left, y+68, pdf.Regular, 9, truncate(c, inner, measurer(pdf.Regular, 9)))
+	}
+	p.Line(x+1, y+72, x+labelWidth-1, y+72, 0.3)
+
+	// груз
+	col := inner / 3
+	for i, kv := range [][2]string{
+		{"WEIGHT", orDash(s.WeightKg, " kg")},
+		{"PIECES", orDash(pieces(s.Pieces), "")},
+		{"VOLUME", orDash(s.VolumeM3, " m3")},
+	} {
+		p.Text(left+float64(i)*col, y+76, pdf.Bold, 7, kv[0])
+		p.Text(left+float64(i)*col, y+83, pdf.Bold, 12, kv[1])
+	}
+	if len(s.Waypoints) > 0 {
+		via := "VIA " + Latin(strings.Join(s.Waypoints, ", "))
+		p.Text(left, y+88, pdf.Regular, 7, truncate(via, inner, measurer(pdf.Regular, 7)))
+	}
+	p.Line(x+1, y+90, x+labelWidth-1, y+90, 0.3)
+
+	// трек-номер
+	bw, err := drawCode128(p, left, y+93, inner, 22, s.TrackingNumber)
+	if err != nil {
+		return fmt.Errorf("label barcode: %w", err)
+	}
+	p.TextCenter(left+bw/2, y+120, pdf.Bold, 12, s.TrackingNumber)
+	p.Line(x+1, y+124, x+labelWidth-1, y+124, 0.3)
+
+	// QR и подсказка для получателя
+	if err := drawQR(p, left-1, y+125, 24, s.TrackURL); err != nil {
+		return fmt.Errorf("label qr: %w", err)
+	}
+	tx := left + 26
+	p.Text(tx, y+132, pdf.Bold, 9, "Scan to track")
+	ty := y + 136
+	for _, line := range wrap(s.TrackURL, inner-26, measurer(pdf.Regular, 7), 2) {
+		p.Text(tx, ty, pdf.Regular, 7, line)
+		ty += 3
+	}
+	p.Text(tx, y+144, pdf.Regular, 6, "ID "+s.ID)
+	return nil
+}
+
+func contactLine(pt Party) string {
+	return Latin(strings.TrimSpace(strings.Join(nonEmpty(pt.Contact, pt.Phone), ", ")))
+}
+
+func nonEmpty(vs ...string) []string {
+	out := vs[:0:0]
+	for _, v := range vs {
+		if v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+func orDash(v, unit string) string {
+	if v == "" {
+		return "-"
+	}
+	return v + unit
+}
+
+func pieces(n int) string {
+	if n == 0 {
+		return ""
+	}
+	return strconv.Itoa(n)
+}
@@ -0,0 +1,39 @@
+package document
+
+import (
+	"strings"
+	"unicode"
+
+	"transline.kz/internal/translit"
+)
+
+// punct — типографские знаки, у которых есть ASCII-замена
+var punct = map[rune]string{
+	'№': "No", '→': ">", '«': `"`, '»': `"`, '“': `"`, '”': `"`, '„': `"`,
+	'‘': "'", '’': "'", '–': "-", '—': "-", '…': "...", ' ': " ",
+}
+
+// Latin транслитерирует текст для стандартных шрифтов PDF:
+// "Алматы, ул. Абая 1" → "Almaty, ul. Abaya 1". Регистр сохраняется.
+func Latin(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if r < unicode.MaxASCII {
+			b.WriteRune(r)
+			continue
+		}
+		if t, ok := translit.Cyrillic[unicode.ToLower(r)]; ok {
+			if unicode.IsUpper(r) && t != "" {
+				t = strings.ToUpper(t[:1]) + t[1:]
+			}
+			b.WriteString(t)
+			continue
+		}
+		if t, ok := punct[r]; ok {
+			b.WriteString(t)
+			continue
+		}
+		b.WriteByte('?')
+	}
+	return b.String()
+}
@@ -0,0 +1,334 @@
+package document
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"transline.kz/internal/pdf"
+)
+
+// waybillLayout — геометрия накладной для формата страницы
+type waybillLayout struct {
+	margin  float64
+	font    float64 // кегль основного текста
+	row     float64 // высота строки таблицы
+	compact bool    // термоформат: груз списком, а не таблицей
+}
+
+func layoutFor(f Format) waybillLayout {
+	if f == FormatThermal {
+		return waybillLayout{margin: 4, font: 7, row: 3.4, compact: true}
+	}
+	return waybillLayout{margin: 15, font: 8, row: 6}
+}
+
+// waybillColumn — колонка таблицы груза (A4)
+type waybillColumn struct {
+	title string
+	width float64
+	right bool
+	value func(i int, it Item) string
+}
+
+var waybillColumns = []waybillColumn{
+	{"No", 8, false, func(i int, _ Item) string { return strconv.Itoa(i + 1) }},
+	{"Description", 52, false, func(_ int, it Item) string {
+		if it.HSCode != "" {
+			return Latin(it.Description) + " (HS " + it.HSCode + ")"
+		}
+		return Latin(it.Description)
+	}},
+	{"Packaging", 18, false, func(_ int, it Item) string { return it.Packaging }},
+	{"Qty", 12, true, func(_ int, it Item) string { return strconv.Itoa(it.Quantity) }},
+	{"Kg/pc", 16, true, func(_ int, it Item) string { return it.WeightKg }},
+	{"L x W x H, cm", 28, false, func(_ int, it Item) string { return it.DimensionsCm }},
+	{"m3/pc", 16, true, func(_ int, it Item) string { return it.VolumeM3 }},
+	{"Value", 30, true, func(_ int, it Item) string { return it.DeclaredValue }},
+}
+
+// waybill — построчная вёрстка накладной с переносом на новые страницы
+type waybill struct {
+	doc    *pdf.Document
+	format Format
+	l      waybillLayout
+	s      Shipment
+	page   *pdf.Page
+	pages  []*pdf.Page
+	y      float64
+}
+
+// Waybill — транспортная накладная: стороны, маршрут, груз по строкам,
+// итоги, стоимость перевозки и места для подписей
+func Waybill(s Shipment, f Format) ([]byte, error) {
+	w := &waybill{doc: pdf.New("Waybill " + s.TrackingNumber), format: f, l: layoutFor(f), s: s}
+	if err := w.newPage(); err != nil {
+		return nil, err
+	}
+	w.parties()
+	if w.l.compact {
+		w.itemsList()
+	} else {
+		w.itemsTable()
+	}
+	w.totals()
+	w.signatures()
+	w.footers()
+	return w.doc.Bytes()
+}
+
+func (w *waybill) width() float64 {
+	return w.page.Width() - 2*w.l.margin
+}
+
+func (w *waybill) bottom() float64 {
+	return w.page.Height() - w.l.margin
+}
+
+// newPage начинает страницу с шапкой: заголовок, номер, дата и штрихкод
+func (w *waybill) newPage() error {
+	pw, ph := w.format.pageSize()
+	w.page = w.doc.AddPage(pw, ph)
+	w.pages = append(w.pages, w.page)
+	m := w.l.margin
+	title := "CONSIGNMENT NOTE"
+
+	if w.l.compact {
+		w.page.Text(m, m+5, pdf.Bold, 11, title)
+		w.page.Text(m, m+9, pdf.Regular, 7, "No "+w.s.TrackingNumber+"  "+w.s.CreatedAt.Format("2006-01-02"))
+		if _, err := drawCode128(w.page, m, m+11, w.width(), 10, w.s.TrackingNumber); err != nil {
+			return fmt.Errorf("waybill barcode: %w", err)
+		}
+		w.y = m + 26
+		return nil
+	}
+
+	w.page.Text(m, m+8, pdf.Bold, 16, title)
+	w.page.Text(m, m+14, pdf.Regular, 9, "No "+w.s.TrackingNumber)
+	w.page.Text(m, m+19, pdf.Regular, 9, "Date "+w.s.CreatedAt.Format("2006-01-02 15:04 MST"))
+	bw, err := drawCode128(w.page, m+w.width()-70, m, 70, 14, w.s.TrackingNumber)
+	if err != nil {
+		return fmt.Errorf("waybill barcode: %w", err)
+	}
+	w.page.TextCenter(m+w.width()-70+bw/2, m+18, pdf.Regular, 8, w.s.TrackingNumber)
+	w.y = m + 26
+	return nil
+}
+
+// footers нумерует страницы, когда их число уже известно
+func (w *waybill) footers() {
+	size := w.l.font - 1
+	for i, p := range w.pages {
+		text := fmt.Sprintf("%s  Page %d of %d", w.s.TrackingNumber, i+1, len(w.pages))
+		p.TextRight(p.Width()-w.l.margin, p.Height()-w.l.margin/2, pdf.Regular, size, text)
+	}
+}
+
+// ensure переносит вёрстку на новую страницу, если по высоте не хватает h мм
+func (w *waybill) ensure(h float64) {
+	if w.y+h <= w.bottom() {
+		return
+	}
+	// ошибка штрихкода уже проверена на первой странице
+	_ = w.newPage()
+}
+
+// box — рамка с заголовком и строками текста
+func (w *waybill) box(x, y, width float64, title string, lines []string) float64 {
+	size := w.l.font
+	lh := size * 0.45
+	h := 2 + lh*float64(len(lines)+1) + 1.5
+	w.page.FillRect(x, y, width, lh+1.5, 0.9)
+	w.page.StrokeRect(x, y, width, h, 0.2)
+	w.page.Text(x+1.5, y+lh, pdf.Bold, size-1, title)
+	ly := y + 2*lh + 1
+	for _, line := range lines {
+		w.page.Text(x+1.5, ly, pdf.Regular, size, truncate(line, width-3, measurer(pdf.Regular, size)))
+		ly += lh
+	}
+	return h
+}
+
+func partyLines(pt Party, width float64, measure func(string) float64) []string {
+	lines := []string{Latin(pt.City)}
+	lines = append(lines, wrap(Latin(pt.Address), width, measure, 2)...)
+	if c := contactLine(pt); c != "" {
+		lines = append(lines, c)
+	}
+	return lines
+}
+
+func (w *waybill) parties() {
+	measure := measurer(pdf.Regular, w.l.font)
+	route := make([]string, 0, len(w.s.Waypoints)+2)
+	route = append(route, w.s.Origin.City)
+	route = append(route, w.s.Waypoints...)
+	route = append(route, w.s.Destination.City)
+
+	idn := ""
+	if w.s.Customer.IDN != "" {
+		idn = "IDN/BIN " + w.s.Customer.IDN
+	}
+	payer := nonEmpty(Latin(w.s.Customer.Name), idn, Latin(w.s.Customer.Phone))
+	payer = append(payer, wrap(Latin(w.s.Customer.Address), w.width(), measure, 2)...)
+	carrier := []string{"Transline", "Route: " + Latin(strings.Join(route, " > "))}
+
+	m := w.l.margin
+	if w.l.compact {
+		for _, b := range []struct {
+			title string
+			lines []string
+		}{
+			{"SHIPPER", partyLines(w.s.Origin, w.width()-3, measure)},
+			{"CONSIGNEE", partyLines(w.s.Destination, w.width()-3, measure)},
+			{"PAYER", payer},
+			{"CARRIER", carrier},
+		} {
+			w.ensure(12)
+			w.y += w.box(m, w.y, w.width(), b.title, b.lines) + 1.5
+		}
+		return
+	}
+
+	half := (w.width() - 4) / 2
+	h1 := w.box(m, w.y, half, "SHIPPER", partyLines(w.s.Origin, half-3, measure))
+	h2 := w.box(m+half+4, w.y, half, "CONSIGNEE", partyLines(w.s.Destination, half-3, measure))
+	w.y += max(h1, h2) + 4
+	h1 = w.box(m, w.y, half, "PAYER", payer)
+	h2 = w.box(m+half+4, w.y, half, "CARRIER", carrier)
+	w.y += max(h1, h2) + 6
+}
+
+func (w *waybill) itemsTable() {
+	m := w.l.margin
+	header := func() {
+		w.page.FillRect(m, w.y, w.width(), w.l.row, 0.9)
+		x := m
+		for _, c := range waybillColumns {
+			w.cell(x, c, c.title, pdf.Bold)
+			x += c.width
+		}
+		w.page.StrokeRect(m, w.y, w.width(), w.l.row, 0.2)
+		w.y += w.l.row
+	}
+
+	w.page.Text(m, w.y, pdf.Bold, w.l.font+1, "CARGO")
+	w.y += 2
+	if len(w.s.Items) == 0 {
+		w.y += w.l.row
+		w.page.Text(m, w.y, pdf.Regular, w.l.font, "Cargo items were not specified")
+		w.y += w.l.row
+		return
+	}
+
+	header()
+	for i, it := range w.s.Items {
+		if w.y+w.l.row > w.bottom() {
+			_ = w.newPage()
+			header()
+		}
+		x := m
+		for _, c := range waybillColumns {
+			w.cell(x, c, c.value(i, it), pdf.Regular)
+			x += c.width
+		}
+		w.page.StrokeRect(m, w.y, w.width(), w.l.row, 0.1)
+		w.y += w.l.row
+	}
+	w.y += 4
+}
+
+func (w *waybill) cell(x float64, c waybillColumn, text string, f pdf.Font) {
+	text = truncate(text, c.width-2, measurer(f, w.l.font))
+	baseline := w.y + w.l.row*0.7
+	if c.right {
+		w.page.TextRight(x+c.width-1, baseline, f, w.l.font, text)
+		return
+	}
+	w.page.Text(x+1, baseline, f, w.l.font, text)
+}
+
+func (w *waybill) itemsList() {
+	m := w.l.margin
+	measure := measurer(pdf.Regular, w.l.font)
+	w.ensure(2 * w.l.row)
+	w.y += w.l.row
+	w.page.Text(m, w.y, pdf.Bold, w.l.font+1, "CARGO")
+	for i, it := range w.s.Items {
+		w.ensure(2 * w.l.row)
+		w.y += w.l.row
+		line := fmt.Sprintf("%d. %s", i+1, Latin(it.Description))
+		w.page.Text(m, w.y, pdf.Regular, w.l.font, truncate(line, w.width(), measure))
+		w.y += w.l.row
+		detail := strings.Join(nonEmpty(
+			fmt.Sprintf("%d x %s kg", it.Quantity, it.WeightKg),
+			it.Packaging, it.DimensionsCm, hsCode(it.HSCode), it.DeclaredValue,
+		), ", ")
+		w.page.Text(m+3, w.y, pdf.Regular, w.l.font-1, truncate(detail, w.width()-3, measure))
+	}
+	if len(w.s.Items) == 0 {
+		w.y += w.l.row
+		w.page.Text(m, w.y, pdf.Regular, w.l.font, "Cargo items were not specified")
+	}
+	w.y += w.l.row
+}
+
+func hsCode(c string) string {
+	if c == "" {
+		return ""
+	}
+	return "HS " + c
+}
+
+func (w *waybill) totals() {
+	m := w.l.margin
+	lines := [][2]string{
+		{"Pieces", orDash(pieces(w.s.Pieces), "")},
+		{"Gross weight", orDash(w.s.WeightKg, " kg")},
+		{"Volume", orDash(w.s.VolumeM3, " m3")},
+		{"Chargeable weight", orDash(w.s.ChargeableKg, " kg")},
+		{"Declared value", orDash(w.s.DeclaredValue, " "+w.s.Currency)},
+		{"Freight charge", w.s.Price + " " + w.s.Currency},
+	}
+	w.ensure(float64(len(lines)+1) * w.l.row)
+	labelWidth := 35.0
+	if w.l.compact {
+		labelWidth = 28
+	}
+	for _, kv := range lines {
+		w.y += w.l.row * 0.8
+		w.page.Text(m, w.y, pdf.Bold, w.l.font, kv[0])
+		w.page.Text(m+labelWidth, w.y, pdf.Regular, w.l.font, kv[1])
+	}
+	w.y += w.l.row
+}
+
+// signatures — подписи отправителя, перевозчика и получателя в одну строку
+// (A4) или друг под другом (термоформат)
+func (w *waybill) signatures() {
+	m := w.l.margin
+	roles := []string{"Shipper", "Carrier", "Consignee"}
+	if w.l.compact {
+		for _, r := range roles {
+			w.ensure(9)
+			w.y += 7
+			w.page.Line(m+22, w.y, m+w.width(), w.y, 0.2)
+			w.page.Text(m, w.y, pdf.Regular, w.l.font, r)
+		}
+		return
+	}
+
+	const height = 28
+	w.ensure(height + 4)
+	// подписи прижимаются к низу последней страницы
+	top := w.bottom() - height
+	colWidth := (w.width() - 8) / 3
+	for i, r := range roles {
+		x := m + float64(i)*(colWidth+4)
+		w.page.StrokeRect(x, top, colWidth, height, 0.2)
+		w.page.Text(x+2, top+5, pdf.Bold, w.l.font, r)
+		w.page.Line(x+2, top+height-8, x+colWidth-2, top+height-8, 0.2)
+		w.page.Text(x+2, top+height-4, pdf.Regular, w.l.font-1, "Signature, stamp, date")
+	}
+	w.y = w.bottom()
+}
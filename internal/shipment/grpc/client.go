@@ -2,6 +2,9 @@ package grpc
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"io"
 
 	pb "transline.kz/api/proto/customerpb"
 )
@@ -14,6 +17,88 @@ func New(client pb.CustomerServiceClient) *Client {
 	return &Client{client: client}
 }
 
-func (c *Client) UpsertCustomer(ctx context.Context, idn string) (*pb.CustomerResponse, error) {
-	return c.client.UpsertCustomer(ctx, &pb.UpsertCustomerRequest{Idn: idn})
+// UpsertCustomer — requestID идентифицирует операцию для последующей компенсации
+func (c *Client) UpsertCustomer(ctx context.Context, idn, requestID string) (*pb.CustomerResponse, error) {
+	return c.client.UpsertCustomer(ctx, &pb.UpsertCustomerRequest{Idn: idn, RequestId: requestID})
+}
+
+// maxUpsertBatch — предел элементов BatchUpsertCustomers в customer-service
+const maxUpsertBatch = 100
+
+// BatchUpsertCustomers — UpsertCustomer для пакета; результаты в порядке items.
+// До maxUpsertBatch элементов — один unary-вызов, больше — частями по
+// UpsertCustomersStream в одном потоке.
+func (c *Client) BatchUpsertCustomers(ctx context.Context, items []*pb.UpsertCustomerRequest) ([]*pb.UpsertCustomerResult, error) {
+	if len(items) <= maxUpsertBatch {
+		resp, err := c.client.BatchUpsertCustomers(ctx, &pb.BatchUpsertCustomersRequest{Items: items})
+		if err != nil {
+			return nil, err
+		}
+		if len(resp.Results) != len(items) {
+			return nil, fmt.Errorf("batch upsert returned %d results for %d items", len(resp.Results), len(items))
+		}
+		return resp.Results, nil
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stream, err := c.client.UpsertCustomersStream(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// customer-service отвечает на каждое сообщение по порядку, так что
+	// отправка и приём идут параллельно
+	sendErr := make(chan error, 1)
+	go func() {
+		for start := 0; start < len(items); start += maxUpsertBatch {
+			end := min(start+maxUpsertBatch, len(items))
+			if err := stream.Send(&pb.BatchUpsertCustomersRequest{Items: items[start:end]}); err != nil {
+				// настоящая причина придёт из Recv
+				sendErr <- nil
+				return
+			}
+		}
+		sendErr <- stream.CloseSend()
+	}()
+
+	results := make([]*pb.UpsertCustomerResult, 0, len(items))
+	for {
+		resp, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, resp.Results...)
+	}
+	if err := <-sendErr; err != nil {
+		return nil, err
+	}
+	if len(results) != len(items) {
+		return nil, fmt.Errorf("batch upsert returned %d results for %d items", len(results), len(items))
+	}
+	return results, nil
+}
+
+func (c *Client) CompensateUpsertCustomer(ctx context.Context, requestID string) (bool, error) {
+	resp, err := c.client.CompensateUpsertCustomer(ctx, &pb.CompensateUpsertCustomerRequest{RequestId: requestID})
+	if err != nil {
+		return false, err
+	}
+	return resp.Deleted, nil
+}
+
+// GetCustomerByIdn — без побочных эффектов; если клиента нет, код NotFound
+func (c *Client) GetCustomerByIdn(ctx context.Context, idn string) (*pb.CustomerResponse, error) {
+	return c.client.GetCustomerByIdn(ctx, &pb.GetCustomerByIdnRequest{Idn: idn})
+}
+
+func (c *Client) GetCustomerById(ctx context.Context, id string) (*pb.CustomerResponse, error) {
+	return c.client.GetCustomerById(ctx, &pb.GetCustomerByIdRequest{Id: id})
+}
+
+func (c *Client) GetCustomers(ctx context.Context, ids []string) (*pb.GetCustomersResponse, error) {
+	return c.client.GetCustomers(ctx, &pb.GetCustomersRequest{Ids: ids})
 }
@@ -0,0 +1,436 @@
+package http
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+
+	shservice "transline.kz/internal/shipment/service"
+)
+
+// maxBatchBody — предел тела пакетного запроса; с запасом на MaxBatchSize строк
+const maxBatchBody = 4 << 20
+
+type batchRowResponse struct {
+	// Index — номер строки в пакете с нуля; Line — строка CSV-файла (только для импорта)
+	Index          int            `json:"index"`
+	Line           int            `json:"line,omitempty"`
+	Status         string         `json:"status"`
+	ID             *uuid.UUID     `json:"id,omitempty"`
+	TrackingNumber string         `json:"trackingNumber,omitempty"`
+	CustomerID     *uuid.UUID     `json:"customerId,omitempty"`
+	Code           string         `json:"code,omitempty"`
+	Message        string         `json:"message,omitempty"`
+	Errors         []problemField `json:"errors,omitempty"`
+}
+
+type batchResponse struct {
+	Mode    string             `json:"mode"`
+	Total   int                `json:"total"`
+	Created int                `json:"created"`
+	Failed  int                `json:"failed"`
+	Skipped int                `json:"skipped"`
+	Rows    []batchRowResponse `json:"rows"`
+}
+
+// Batch — POST /api/v1/shipments:batch?mode=ALL_OR_NOTHING|BEST_EFFORT,
+// тело — JSON-массив запросов как у POST /api/v1/shipments
+func (h *Handler) Batch(w http.ResponseWriter, r *http.Request) {
+	mode, err := shservice.ParseBatchMode(r.URL.Query().Get("mode"))
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	var reqs []createShipmentRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBatchBody)).Decode(&reqs); err != nil {
+		var mbe *http.MaxBytesError
+		if errors.As(err, &mbe) {
+			writeProblem(w, newProblem(r, http.StatusRequestEntityTooLarge, codeRequestTooLarge, "Request body is too large"))
+			return
+		}
+		badRequest(w, r, codeMalformedRequest, "body must be a JSON array of shipments")
+		return
+	}
+
+	in := shservice.BatchInput{Mode: mode, Rows: make([]shservice.CreateShipmentInput, len(reqs))}
+	for i, req := range reqs {
+		in.Rows[i] = req.toService()
+	}
+	h.createBatch(w, r, in, nil)
+}
+
+// Import — POST /api/v1/shipments/import?mode=…&map=…, тело — text/csv:
+// строка заголовков и по строке на отправление
+func (h *Handler) Import(w http.ResponseWriter, r *http.Request) {
+	if mt, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mt != "text/csv" && mt != "application/csv" {
+		p := newProblem(r, http.StatusUnsupportedMediaType, codeUnsupportedMediaType, "Unsupported media type")
+		p.Detail = "use Content-Type: text/csv"
+		writeProblem(w, p)
+		return
+	}
+	q := r.URL.Query()
+	mode, err := shservice.ParseBatchMode(q.Get("mode"))
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	mapping, err := parseColumnMap(q.Get("map"))
+	if err != nil {
+		writeParamError(w, r, err)
+		return
+	}
+
+	rows, rowErrs, lines, err := readShipmentsCSV(http.MaxBytesReader(w, r.Body, maxBatchBody), mapping)
+	if err != nil {
+		var mbe *http.MaxBytesError
+		if errors.As(err, &mbe) {
+			writeProblem(w, newProblem(r, http.StatusRequestEntityTooLarge, codeRequestTooLarge, "Request body is too large"))
+			return
+		}
+		badRequest(w, r, codeMalformedRequest, err.Error())
+		return
+	}
+	h.createBatch(w, r, shservice.BatchInput{Mode: mode, Rows: rows, Errors: rowErrs}, lines)
+}
+
+// createBatch создаёт пакет и отдаёт отчёт по строкам: 201 — созданы все
+// строки, 200 — часть (BEST_EFFORT), 422 — ни одной
+func (h *Handler) createBatch(w http.ResponseWriter, r *http.Request, in shservice.BatchInput, lines []int) {
+	res, err := h.service.CreateShipments(r.Context(), in)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	resp := batchResponse{
+		Mode:    string(res.Mode),
+		Total:   len(res.Rows),
+		Created: res.Created,
+		Failed:  res.Failed,
+		Skipped: len(res.Rows) - res.Created - res.Failed,
+		Rows:    make([]batchRowResponse, len(res.Rows)),
+	}
+	for i, row := range res.Rows {
+		rr := batchRowResponse{Index: row.Index, Status: string(row.Status)}
+		if lines != nil {
+			rr.Line = lines[row.Index]
+		}
+		if row.Status == shservice.BatchRowCreated {
+			rr.ID, rr.CustomerID = &row.ID, &row.CustomerID
+			rr.TrackingNumber = row.TrackingNumber
+		}
+		if row.Err != nil {
+			rr.Code, rr.Message, rr.Errors = rowError(r, row.Err)
+		}
+		resp.Rows[i] = rr
+	}
+
+	status := http.StatusOK
+	switch res.Created {
+	case len(res.Rows):
+		status = http.StatusCreated
+	case 0:
+		status = http.StatusUnprocessableEntity
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		slog.Error("error encoding response", "err", err)
+	}
+}
+
+// rowError — ошибка строки пакета в виде code/message/errors по errorMappings
+func rowError(r *http.Request, err error) (string, string, []problemField) {
+	var cerr *cellError
+	if errors.As(err, &cerr) {
+		return codeValidationFailed, "Validation failed", []problemField{{Field: cerr.column, Message: cerr.msg}}
+	}
+	for _, m := range errorMappings {
+		if !errors.Is(err, m.target) {
+			continue
+		}
+		var verr *shservice.ValidationError
+		if errors.As(err, &verr) {
+			fields := make([]problemField, len(verr.Fields))
+			for i, f := range verr.Fields {
+				fields[i] = problemField{Field: f.Field, Message: f.Message}
+			}
+			return m.code, m.title, fields
+		}
+		if !m.expose {
+			slog.Error(m.title, "err", err, "path", r.URL.Path)
+			return m.code, m.title, nil
+		}
+		return m.code, err.Error(), nil
+	}
+	slog.Error("internal error", "err", err, "path", r.URL.Path)
+	return codeInternal, "Internal server error", nil
+}
+
+// Колонки CSV-импорта. Заголовки сравниваются без учёта регистра, пробелов,
+// "_" и "-", так что "origin_address", "Origin Address" и "originAddress"
+// равнозначны.
+const (
+	colOrigin             = "origin"
+	colOriginAddress      = "origin_address"
+	colOriginContact      = "origin_contact"
+	colOriginPhone        = "origin_phone"
+	colWaypoints          = "waypoints"
+	colDestination        = "destination"
+	colDestinationAddress = "destination_address"
+	colDestinationContact = "destination_contact"
+	colDestinationPhone   = "destination_phone"
+	colPrice              = "price"
+	colCurrency           = "currency"
+	colQuoteID            = "quote_id"
+	colIDN                = "idn"
+	colDescription        = "description"
+	colQuantity           = "quantity"
+	colWeight             = "weight_kg"
+	colLength             = "length_cm"
+	colWidth              = "width_cm"
+	colHeight             = "height_cm"
+	colDeclaredValue      = "declared_value"
+	colPackaging          = "packaging"
+	colHSCode             = "hs_code"
+)
+
+// csvColumns — колонка и её синонимы в заголовке
+var csvColumns = map[string][]string{
+	colOrigin:             {"origin", "origin_city", "from"},
+	colOriginAddress:      {"origin_address", "from_address"},
+	colOriginContact:      {"origin_contact", "origin_contact_name", "sender"},
+	colOriginPhone:        {"origin_phone", "origin_contact_phone", "sender_phone"},
+	colWaypoints:          {"waypoints", "via"},
+	colDestination:        {"destination", "destination_city", "to"},
+	colDestinationAddress: {"destination_address", "to_address"},
+	colDestinationContact: {"destination_contact", "destination_contact_name", "recipient"},
+	colDestinationPhone:   {"destination_phone", "destination_contact_phone", "recipient_phone"},
+	colPrice:              {"price", "amount"},
+	colCurrency:           {"currency"},
+	colQuoteID:            {"quote_id", "quote"},
+	colIDN:                {"idn", "bin", "iin", "customer_idn"},
+	colDescription:        {"description", "item", "item_description"},
+	colQuantity:           {"quantity", "qty", "pieces"},
+	colWeight:             {"weight_kg", "weight", "gross_weight_kg"},
+	colLength:             {"length_cm", "length"},
+	colWidth:              {"width_cm", "width"},
+	colHeight:             {"height_cm", "height"},
+	colDeclaredValue:      {"declared_value"},
+	colPackaging:          {"packaging"},
+	colHSCode:             {"hs_code", "hs"},
+}
+
+// waypointSeparator разделяет промежуточные города в колонке waypoints
+const waypointSeparator = "|"
+
+func normalizeHeader(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '_', '-':
+			return -1
+		}
+		return r
+	}, strings.ToLower(strings.TrimSpace(s)))
+}
+
+// parseColumnMap разбирает ?map=колонка:Заголовок,… — явное соответствие
+// для заголовков, которых нет среди синонимов
+func parseColumnMap(s string) (map[string]string, error) {
+	mapping := make(map[string]string)
+	if s == "" {
+		return mapping, nil
+	}
+	for pair := range strings.SplitSeq(s, ",") {
+		name, header, ok := strings.Cut(pair, ":")
+		col := normalizeHeader(name)
+		for c := range csvColumns {
+			if normalizeHeader(c) == col {
+				col = c
+			}
+		}
+		if _, known := csvColumns[col]; !ok || !known || strings.TrimSpace(header) == "" {
+			return nil, &paramError{param: "map", msg: fmt.Sprintf("expected column:Header pairs with a known column, got %q", pair)}
+		}
+		mapping[normalizeHeader(header)] = col
+	}
+	return mapping, nil
+}
+
+// readShipmentsCSV читает отправления из CSV: разделитель "," или ";"
+// определяется по заголовку, BOM в начале пропускается. Каждая строка —
+// одно отправление с не более чем одним грузовым местом. errs — ошибки
+// разбора ячеек по строкам, lines — номер строки файла для каждого отправления.
+func readShipmentsCSV(body io.Reader, mapping map[string]string) (rows []shservice.CreateShipmentInput, errs []error, lines []int, err error) {
+	br := bufio.NewReader(body)
+	if bom, _ := br.Peek(3); bytes.Equal(bom, []byte("\xef\xbb\xbf")) {
+		_, _ = br.Discard(3)
+	}
+	first, err := br.Peek(br.Size())
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, bufio.ErrBufferFull) {
+		return nil, nil, nil, err
+	}
+	if nl := bytes.IndexByte(first, '\n'); nl >= 0 {
+		first = first[:nl]
+	}
+
+	cr := csv.NewReader(br)
+	cr.TrimLeadingSpace = true
+	if bytes.Count(first, []byte(";")) > bytes.Count(first, []byte(",")) {
+		cr.Comma = ';'
+	}
+
+	header, err := cr.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, nil, nil, errors.New("csv is empty")
+	}
+	if err != nil {
+		return nil, nil, nil, csvError(err)
+	}
+	columns, err := mapColumns(header, mapping)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	for {
+		rec, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, nil, nil, csvError(err)
+		}
+		if len(rows) == shservice.MaxBatchSize {
+			return nil, nil, nil, fmt.Errorf("too many rows (max %d)", shservice.MaxBatchSize)
+		}
+		line, _ := cr.FieldPos(0)
+		row, err := csvRow(rec, columns)
+		rows = append(rows, row)
+		errs = append(errs, err)
+		lines = append(lines, line)
+	}
+	if len(rows) == 0 {
+		return nil, nil, nil, errors.New("csv has no shipment rows")
+	}
+	return rows, errs, lines, nil
+}
+
+// csvError скрывает внутренние детали encoding/csv, оставляя позицию
+func csvError(err error) error {
+	var pe *csv.ParseError
+	if errors.As(err, &pe) {
+		return fmt.Errorf("invalid csv at line %d: %v", pe.Line, pe.Err)
+	}
+	return err
+}
+
+// mapColumns сопоставляет колонки заголовка известным полям
+func mapColumns(header []string, mapping map[string]string) (map[string]int, error) {
+	byAlias := make(map[string]string)
+	for col, aliases := range csvColumns {
+		for _, a := range aliases {
+			byAlias[normalizeHeader(a)] = col
+		}
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, h := range header {
+		key := normalizeHeader(h)
+		col, ok := mapping[key]
+		if !ok {
+			col, ok = byAlias[key]
+		}
+		if !ok {
+			return nil, fmt.Errorf("unknown column %q (use ?map=column:Header)", h)
+		}
+		if _, dup := columns[col]; dup {
+			return nil, fmt.Errorf("column %q is mapped twice", col)
+		}
+		columns[col] = i
+	}
+	return columns, nil
+}
+
+// cellError — значение ячейки CSV не удалось разобрать
+type cellError struct {
+	column string
+	msg    string
+}
+
+func (e *cellError) Error() string {
+	return e.column + ": " + e.msg
+}
+
+// csvRow собирает запрос создания из строки CSV
+func csvRow(rec []string, columns map[string]int) (shservice.CreateShipmentInput, error) {
+	get := func(col string) string {
+		if i, ok := columns[col]; ok {
+			return strings.TrimSpace(rec[i])
+		}
+		return ""
+	}
+	stop := func(city, address, contact, phone string) shservice.Stop {
+		return shservice.Stop{
+			CityCode: get(city),
+			Address:  get(address),
+			Contact:  shservice.Contact{Name: get(contact), Phone: get(phone)},
+		}
+	}
+
+	in := shservice.CreateShipmentInput{
+		Route: shservice.Route{
+			Origin:      stop(colOrigin, colOriginAddress, colOriginContact, colOriginPhone),
+			Destination: stop(colDestination, colDestinationAddress, colDestinationContact, colDestinationPhone),
+		},
+		Price:    get(colPrice),
+		Currency: get(colCurrency),
+		IDN:      get(colIDN),
+	}
+	if wp := get(colWaypoints); wp != "" {
+		for city := range strings.SplitSeq(wp, waypointSeparator) {
+			in.Route.Waypoints = append(in.Route.Waypoints, shservice.Stop{CityCode: strings.TrimSpace(city)})
+		}
+	}
+	if q := get(colQuoteID); q != "" {
+		id, err := uuid.Parse(q)
+		if err != nil {
+			return in, &cellError{column: colQuoteID, msg: "must be a UUID"}
+		}
+		in.QuoteID = &id
+	}
+
+	item := shservice.ItemInput{
+		Description:   get(colDescription),
+		Weight:        get(colWeight),
+		Length:        get(colLength),
+		Width:         get(colWidth),
+		Height:        get(colHeight),
+		DeclaredValue: get(colDeclaredValue),
+		Packaging:     get(colPackaging),
+		HSCode:        get(colHSCode),
+	}
+	qty := get(colQuantity)
+	if qty != "" {
+		n, err := strconv.Atoi(qty)
+		if err != nil {
+			return in, &cellError{column: colQuantity, msg: "must be an integer"}
+		}
+		item.Quantity = n
+	}
+	if qty != "" || item != (shservice.ItemInput{}) {
+		in.Items = []shservice.ItemInput{item}
+	}
+	return in, nil
+}
@@ -0,0 +1,63 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	shservice "transline.kz/internal/shipment/service"
+)
+
+type cancelRequest struct {
+	Reason string `json:"reason"`
+	Note   string `json:"note,omitempty"`
+	Actor  string `json:"actor,omitempty"`
+}
+
+type cancellationResponse struct {
+	Reason      string    `json:"reason"`
+	Note        string    `json:"note,omitempty"`
+	Fee         string    `json:"fee"`
+	Currency    string    `json:"currency"`
+	CancelledAt time.Time `json:"cancelledAt"`
+}
+
+func toCancellationResponse(c *shservice.Cancellation) *cancellationResponse {
+	if c == nil {
+		return nil
+	}
+	return &cancellationResponse{
+		Reason:      string(c.Reason),
+		Note:        c.Note,
+		Fee:         c.Fee.Amount(),
+		Currency:    string(c.Fee.Currency()),
+		CancelledAt: c.CancelledAt,
+	}
+}
+
+// Cancel — POST /api/v1/shipments/{id}/cancel
+func (h *Handler) Cancel(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.shipmentID(w, r)
+	if !ok {
+		return
+	}
+
+	var req cancelRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		badRequest(w, r, codeMalformedRequest, "invalid json body")
+		return
+	}
+
+	sh, err := h.service.Cancel(r.Context(), shservice.CancelInput{
+		ShipmentID: id,
+		Reason:     req.Reason,
+		Note:       req.Note,
+		Actor:      req.Actor,
+	})
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeShipment(w, sh)
+}
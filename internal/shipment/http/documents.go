@@ -0,0 +1,49 @@
+package http
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	shservice "transline.kz/internal/shipment/service"
+)
+
+// Label — GET /api/v1/shipments/{id}/label?format=THERMAL_100X150|A4
+func (h *Handler) Label(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.shipmentID(w, r)
+	if !ok {
+		return
+	}
+	doc, err := h.service.ShipmentLabel(r.Context(), id, r.URL.Query().Get("format"))
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	writePDF(w, doc, "label")
+}
+
+// Waybill — GET /api/v1/shipments/{id}/waybill?format=A4|THERMAL_100X150
+func (h *Handler) Waybill(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.shipmentID(w, r)
+	if !ok {
+		return
+	}
+	doc, err := h.service.ShipmentWaybill(r.Context(), id, r.URL.Query().Get("format"))
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	writePDF(w, doc, "waybill")
+}
+
+// writePDF отдаёт документ для просмотра в браузере; имя файла — трек-номер и вид
+func writePDF(w http.ResponseWriter, doc *shservice.Document, kind string) {
+	w.Header().Set("Content-Type", "application/pdf")
+	w.Header().Set("Content-Length", strconv.Itoa(len(doc.PDF)))
+	w.Header().Set("Content-Disposition", fmt.Sprintf("inline; filename=%q", doc.TrackingNumber+"-"+kind+".pdf"))
+	w.Header().Set("Cache-Control", "no-store")
+	if _, err := w.Write(doc.PDF); err != nil {
+		slog.Error("error writing document", "err", err)
+	}
+}
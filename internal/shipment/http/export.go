@@ -0,0 +1,226 @@
+package http
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	shservice "transline.kz/internal/shipment/service"
+)
+
+// Форматы выгрузки
+const (
+	exportCSV    = "csv"
+	exportNDJSON = "ndjson"
+)
+
+// exportColumn — колонка выгрузки; пустое значение в NDJSON — null
+type exportColumn struct {
+	name  string
+	value func(*shservice.ExportRow) string
+}
+
+// exportColumns — схема выгрузки. Порядок и имена — часть контракта для
+// бухгалтерии: новые колонки добавляются только в конец.
+var exportColumns = []exportColumn{
+	{"id", func(r *shservice.ExportRow) string { return r.ID.String() }},
+	{"trackingNumber", func(r *shservice.ExportRow) string { return r.TrackingNumber }},
+	{"status", func(r *shservice.ExportRow) string { return string(r.Status) }},
+	{"route", func(r *shservice.ExportRow) string { return r.RouteText }},
+	{"originCity", func(r *shservice.ExportRow) string { return r.OriginCity }},
+	{"destinationCity", func(r *shservice.ExportRow) string { return r.DestinationCity }},
+	{"customerId", func(r *shservice.ExportRow) string { return r.CustomerID.String() }},
+	{"price", func(r *shservice.ExportRow) string { return r.Price.Amount() }},
+	{"currency", func(r *shservice.ExportRow) string { return string(r.Price.Currency()) }},
+	{"pieces", func(r *shservice.ExportRow) string { return strconv.Itoa(r.Pieces) }},
+	{"grossWeightKg", func(r *shservice.ExportRow) string { return r.Weight.Kg() }},
+	{"createdAt", func(r *shservice.ExportRow) string { return r.CreatedAt.UTC().Format(time.RFC3339) }},
+	{"cancelReason", func(r *shservice.ExportRow) string {
+		if r.Cancellation == nil {
+			return ""
+		}
+		return string(r.Cancellation.Reason)
+	}},
+	{"cancellationFee", func(r *shservice.ExportRow) string {
+		if r.Cancellation == nil {
+			return ""
+		}
+		return r.Cancellation.Fee.Amount()
+	}},
+	{"cancelledAt", func(r *shservice.ExportRow) string {
+		if r.Cancellation == nil {
+			return ""
+		}
+		return r.Cancellation.CancelledAt.UTC().Format(time.RFC3339)
+	}},
+	{"version", func(r *shservice.ExportRow) string { return strconv.FormatInt(r.Version, 10) }},
+}
+
+// parseExportColumns разбирает ?columns=a,b; пусто — все колонки схемы
+func parseExportColumns(s string) ([]exportColumn, error) {
+	if strings.TrimSpace(s) == "" {
+		return exportColumns, nil
+	}
+	var (
+		out  []exportColumn
+		seen = make(map[string]bool)
+	)
+	for name := range strings.SplitSeq(s, ",") {
+		name = strings.TrimSpace(name)
+		i := -1
+		for j, c := range exportColumns {
+			if c.name == name {
+				i = j
+			}
+		}
+		switch {
+		case i < 0:
+			return nil, &paramError{param: "columns", msg: fmt.Sprintf("unknown column %q", name)}
+		case seen[name]:
+			return nil, &paramError{param: "columns", msg: fmt.Sprintf("column %q is listed twice", name)}
+		}
+		seen[name] = true
+		out = append(out, exportColumns[i])
+	}
+	return out, nil
+}
+
+// Export — GET /api/v1/shipments/export?format=csv|ndjson&columns=…, фильтры
+// и sort как у листинга; limit и cursor не применяются. Строки пишутся по мере
+// чтения из БД. Если выгрузка прервалась после начала ответа, соединение
+// обрывается, чтобы клиент не принял неполный файл за целый.
+func (h *Handler) Export(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	format := strings.ToLower(q.Get("format"))
+	if format == "" {
+		format = exportCSV
+	}
+	if format != exportCSV && format != exportNDJSON {
+		writeParamError(w, r, &paramError{param: "format", msg: "must be csv or ndjson"})
+		return
+	}
+	columns, err := parseExportColumns(q.Get("columns"))
+	if err != nil {
+		writeParamError(w, r, err)
+		return
+	}
+	q.Del("limit")
+	q.Del("cursor")
+	in, err := parseListQuery(q)
+	if err != nil {
+		writeParamError(w, r, err)
+		return
+	}
+
+	out := &exportWriter{w: w, format: format, columns: columns}
+	err = h.service.ExportShipments(r.Context(), in, out.write)
+	if err == nil {
+		err = out.close()
+	}
+	if err == nil {
+		return
+	}
+	if !out.started {
+		writeError(w, r, err)
+		return
+	}
+	slog.Error("shipment export aborted", "err", err, "rows", out.rows)
+	panic(http.ErrAbortHandler)
+}
+
+// exportWriter пишет заголовки ответа с первой строкой, чтобы ошибку до
+// неё ещё можно было отдать как problem+json
+type exportWriter struct {
+	w       http.ResponseWriter
+	format  string
+	columns []exportColumn
+
+	started bool
+	rows    int
+	buf     *bufio.Writer
+	csv     *csv.Writer
+	values  []string
+}
+
+func (e *exportWriter) start() error {
+	e.started = true
+	name := "shipments-" + time.Now().UTC().Format("20060102")
+	h := e.w.Header()
+	if e.format == exportCSV {
+		h.Set("Content-Type", "text/csv; charset=utf-8")
+		h.Set("Content-Disposition", `attachment; filename="`+name+`.csv"`)
+	} else {
+		h.Set("Content-Type", "application/x-ndjson")
+		h.Set("Content-Disposition", `attachment; filename="`+name+`.ndjson"`)
+	}
+	h.Set("Cache-Control", "no-store")
+	e.w.WriteHeader(http.StatusOK)
+
+	e.buf = bufio.NewWriterSize(e.w, 32<<10)
+	e.values = make([]string, len(e.columns))
+	if e.format != exportCSV {
+		return nil
+	}
+	e.csv = csv.NewWriter(e.buf)
+	for i, c := range e.columns {
+		e.values[i] = c.name
+	}
+	return e.csv.Write(e.values)
+}
+
+func (e *exportWriter) write(row *shservice.ExportRow) error {
+	if !e.started {
+		if err := e.start(); err != nil {
+			return err
+		}
+	}
+	e.rows++
+	for i, c := range e.columns {
+		e.values[i] = c.value(row)
+	}
+	if e.csv != nil {
+		return e.csv.Write(e.values)
+	}
+
+	// объект собирается вручную: encoding/json сортирует ключи map, а
+	// порядок колонок должен совпадать с ?columns
+	b := []byte{'{'}
+	for i, c := range e.columns {
+		if i > 0 {
+			b = append(b, ',')
+		}
+		b = strconv.AppendQuote(b, c.name)
+		b = append(b, ':')
+		if e.values[i] == "" {
+			b = append(b, "null"...)
+			continue
+		}
+		v, _ := json.Marshal(e.values[i])
+		b = append(b, v...)
+	}
+	b = append(b, '}', '\n')
+	_, err := e.buf.Write(b)
+	return err
+}
+
+// close дописывает буфер; пустая выгрузка — только заголовок CSV
+func (e *exportWriter) close() error {
+	if !e.started {
+		if err := e.start(); err != nil {
+			return err
+		}
+	}
+	if e.csv != nil {
+		e.csv.Flush()
+		if err := e.csv.Error(); err != nil {
+			return err
+		}
+	}
+	return e.buf.Flush()
+}
@@ -2,7 +2,9 @@ package http
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
+	"time"
 
 	"log/slog"
 
@@ -23,55 +25,491 @@ func New(service *shservice.Service) *Handler {
 // ===== DTO =====
 
 type createShipmentRequest struct {
-	Route    string  `json:"route"`
-	Price    float64 `json:"price"`
+	Route routeInput `json:"route"`
+	// Price принимается и строкой "1500.50", и JSON-числом; json.Number
+	// сохраняет исходную запись без округления через float64
+	Price    json.Number `json:"price"`
+	Currency string      `json:"currency,omitempty"`
+	// QuoteID — котировка из POST /api/v1/quotes; цена берётся из неё
+	QuoteID  *uuid.UUID `json:"quoteId,omitempty"`
+	Items    []itemDTO  `json:"items,omitempty"`
 	Customer struct {
 		IDN string `json:"idn"`
 	} `json:"customer"`
 }
 
+func (req createShipmentRequest) toService() shservice.CreateShipmentInput {
+	in := shservice.CreateShipmentInput{
+		Price:    req.Price.String(),
+		Currency: req.Currency,
+		IDN:      req.Customer.IDN,
+		QuoteID:  req.QuoteID,
+		Items:    toItemInputs(req.Items),
+	}
+	req.Route.toService(&in)
+	return in
+}
+
 type createShipmentResponse struct {
-	ID         uuid.UUID `json:"id"`
-	Status     string    `json:"status"`
-	CustomerID uuid.UUID `json:"customerId"`
+	ID             uuid.UUID `json:"id"`
+	TrackingNumber string    `json:"trackingNumber"`
+	Status         string    `json:"status"`
+	CustomerID     uuid.UUID `json:"customerId"`
+}
+
+type coordinatesDTO struct {
+	Lat float64 `json:"lat"`
+	Lon float64 `json:"lon"`
+}
+
+type eventDetailsDTO struct {
+	Actor       string          `json:"actor,omitempty"`
+	Location    string          `json:"location,omitempty"`
+	Coordinates *coordinatesDTO `json:"coordinates,omitempty"`
+	Note        string          `json:"note,omitempty"`
+}
+
+func (d eventDetailsDTO) toService() shservice.EventDetails {
+	out := shservice.EventDetails{
+		Actor:    d.Actor,
+		Location: d.Location,
+		Note:     d.Note,
+	}
+	if d.Coordinates != nil {
+		out.Coordinates = &shservice.Coordinates{
+			Latitude:  d.Coordinates.Lat,
+			Longitude: d.Coordinates.Lon,
+		}
+	}
+	return out
+}
+
+type contactDTO struct {
+	Name  string `json:"name,omitempty"`
+	Phone string `json:"phone,omitempty"`
+}
+
+type stopDTO struct {
+	City        string          `json:"city"`
+	Address     string          `json:"address,omitempty"`
+	Coordinates *coordinatesDTO `json:"coordinates,omitempty"`
+	Contact     *contactDTO     `json:"contact,omitempty"`
+	// Unresolved — города нет в справочнике (только в ответах)
+	Unresolved bool `json:"unresolved,omitempty"`
+}
+
+func (d stopDTO) toService() shservice.Stop {
+	st := shservice.Stop{
+		CityCode: d.City,
+		Address:  d.Address,
+	}
+	if d.Coordinates != nil {
+		st.Coordinates = &shservice.Coordinates{
+			Latitude:  d.Coordinates.Lat,
+			Longitude: d.Coordinates.Lon,
+		}
+	}
+	if d.Contact != nil {
+		st.Contact = shservice.Contact{Name: d.Contact.Name, Phone: d.Contact.Phone}
+	}
+	return st
+}
+
+func toStopDTO(st shservice.Stop) stopDTO {
+	d := stopDTO{
+		City:       st.CityCode,
+		Address:    st.Address,
+		Unresolved: st.Unresolved,
+	}
+	if c := st.Coordinates; c != nil {
+		d.Coordinates = &coordinatesDTO{Lat: c.Latitude, Lon: c.Longitude}
+	}
+	if st.Contact != (shservice.Contact{}) {
+		d.Contact = &contactDTO{Name: st.Contact.Name, Phone: st.Contact.Phone}
+	}
+	return d
+}
+
+type routeDTO struct {
+	Origin      stopDTO   `json:"origin"`
+	Waypoints   []stopDTO `json:"waypoints,omitempty"`
+	Destination stopDTO   `json:"destination"`
+}
+
+// routeInput — поле "route" запроса: строка старого формата "ALMATY→ASTANA"
+// или объект routeDTO
+type routeInput struct {
+	text       string
+	structured *routeDTO
+}
+
+func (ri *routeInput) UnmarshalJSON(b []byte) error {
+	if len(b) > 0 && b[0] == '"' {
+		return json.Unmarshal(b, &ri.text)
+	}
+	return json.Unmarshal(b, &ri.structured)
+}
+
+func (ri routeInput) toService(in *shservice.CreateShipmentInput) {
+	in.RouteText = ri.text
+	if d := ri.structured; d != nil {
+		in.Route.Origin = d.Origin.toService()
+		for _, w := range d.Waypoints {
+			in.Route.Waypoints = append(in.Route.Waypoints, w.toService())
+		}
+		in.Route.Destination = d.Destination.toService()
+	}
+}
+
+// itemDTO — грузовое место; числа принимаются строкой или JSON-числом
+type itemDTO struct {
+	Description   string      `json:"description"`
+	Quantity      int         `json:"quantity"`
+	GrossWeightKg json.Number `json:"grossWeightKg"`
+	LengthCm      json.Number `json:"lengthCm,omitempty"`
+	WidthCm       json.Number `json:"widthCm,omitempty"`
+	HeightCm      json.Number `json:"heightCm,omitempty"`
+	DeclaredValue json.Number `json:"declaredValue,omitempty"`
+	Packaging     string      `json:"packaging,omitempty"`
+	HSCode        string      `json:"hsCode,omitempty"`
+}
+
+func toItemInputs(items []itemDTO) []shservice.ItemInput {
+	out := make([]shservice.ItemInput, len(items))
+	for i, d := range items {
+		out[i] = shservice.ItemInput{
+			Description:   d.Description,
+			Quantity:      d.Quantity,
+			Weight:        d.GrossWeightKg.String(),
+			Length:        d.LengthCm.String(),
+			Width:         d.WidthCm.String(),
+			Height:        d.HeightCm.String(),
+			DeclaredValue: d.DeclaredValue.String(),
+			Packaging:     d.Packaging,
+			HSCode:        d.HSCode,
+		}
+	}
+	return out
+}
+
+type itemResponse struct {
+	Description   string `json:"description"`
+	Quantity      int    `json:"quantity"`
+	GrossWeightKg string `json:"grossWeightKg"`
+	LengthCm      string `json:"lengthCm,omitempty"`
+	WidthCm       string `json:"widthCm,omitempty"`
+	HeightCm      string `json:"heightCm,omitempty"`
+	VolumeM3      string `json:"volumeM3,omitempty"`
+	DeclaredValue string `json:"declaredValue,omitempty"`
+	Packaging     string `json:"packaging"`
+	HSCode        string `json:"hsCode,omitempty"`
+}
+
+type cargoResponse struct {
+	Items              []itemResponse `json:"items"`
+	TotalQuantity      int            `json:"totalQuantity"`
+	TotalWeightKg      string         `json:"totalWeightKg"`
+	TotalVolumeM3      string         `json:"totalVolumeM3"`
+	VolumetricWeightKg string         `json:"volumetricWeightKg"`
+	ChargeableWeightKg string         `json:"chargeableWeightKg"`
+	TotalDeclaredValue string         `json:"totalDeclaredValue,omitempty"`
+}
+
+func toCargoResponse(c shservice.Cargo) *cargoResponse {
+	if len(c.Items) == 0 {
+		return nil
+	}
+	resp := &cargoResponse{
+		Items:              make([]itemResponse, 0, len(c.Items)),
+		TotalQuantity:      c.TotalQuantity,
+		TotalWeightKg:      c.TotalWeight.Kg(),
+		TotalVolumeM3:      c.TotalVolume.M3(),
+		VolumetricWeightKg: c.VolumetricWeight.Kg(),
+		ChargeableWeightKg: c.ChargeableWeight.Kg(),
+	}
+	if c.DeclaredValue != nil {
+		resp.TotalDeclaredValue = c.DeclaredValue.Amount()
+	}
+	for _, it := range c.Items {
+		item := itemResponse{
+			Description:   it.Description,
+			Quantity:      it.Quantity,
+			GrossWeightKg: it.Weight.Kg(),
+			Packaging:     string(it.Packaging),
+			HSCode:        it.HSCode,
+		}
+		if d := it.Dimensions; d != nil {
+			item.LengthCm, item.WidthCm, item.HeightCm = d.Length.Cm(), d.Width.Cm(), d.Height.Cm()
+			item.VolumeM3 = it.Volume().M3()
+		}
+		if it.DeclaredValue != nil {
+			item.DeclaredValue = it.DeclaredValue.Amount()
+		}
+		resp.Items = append(resp.Items, item)
+	}
+	return resp
+}
+
+type transitionRequest struct {
+	Status string `json:"status"`
+	eventDetailsDTO
+}
+
+type addEventRequest struct {
+	Type string `json:"type"`
+	eventDetailsDTO
+}
+
+type eventResponse struct {
+	ID         int64     `json:"id"`
+	Type       string    `json:"type"`
+	StatusFrom string    `json:"statusFrom,omitempty"`
+	StatusTo   string    `json:"statusTo,omitempty"`
+	OccurredAt time.Time `json:"occurredAt"`
+	eventDetailsDTO
+}
+
+func toEventResponse(e *shservice.Event) eventResponse {
+	resp := eventResponse{
+		ID:         e.ID,
+		Type:       string(e.Type),
+		StatusFrom: string(e.StatusFrom),
+		StatusTo:   string(e.StatusTo),
+		OccurredAt: e.OccurredAt,
+		eventDetailsDTO: eventDetailsDTO{
+			Actor:    e.Actor,
+			Location: e.Location,
+			Note:     e.Note,
+		},
+	}
+	if e.Coordinates != nil {
+		resp.Coordinates = &coordinatesDTO{
+			Lat: e.Coordinates.Latitude,
+			Lon: e.Coordinates.Longitude,
+		}
+	}
+	return resp
+}
+
+type shipmentResponse struct {
+	ID             uuid.UUID      `json:"id"`
+	TrackingNumber string         `json:"trackingNumber"`
+	Route          string         `json:"route"`
+	Origin         *stopDTO       `json:"origin,omitempty"`
+	Waypoints      []stopDTO      `json:"waypoints,omitempty"`
+	Destination    *stopDTO       `json:"destination,omitempty"`
+	Cargo          *cargoResponse `json:"cargo,omitempty"`
+	Price          string         `json:"price"`
+	Currency       string         `json:"currency"`
+	Status         string         `json:"status"`
+	CustomerID     uuid.UUID      `json:"customerId"`
+	CreatedAt      time.Time      `json:"createdAt"`
+	// Cancellation — только у отменённых через /cancel
+	Cancellation *cancellationResponse `json:"cancellation,omitempty"`
+}
+
+type listShipmentsResponse struct {
+	Items      []shipmentResponse `json:"items"`
+	NextCursor string             `json:"nextCursor,omitempty"`
+}
+
+func toShipmentResponse(sh *shservice.Shipment) shipmentResponse {
+	resp := shipmentResponse{
+		ID:             sh.ID,
+		TrackingNumber: sh.TrackingNumber,
+		Route:          sh.RouteText,
+		Cargo:          toCargoResponse(sh.Cargo),
+		Price:          sh.Price.Amount(),
+		Currency:       string(sh.Price.Currency()),
+		Status:         string(sh.Status),
+		CustomerID:     sh.CustomerID,
+		CreatedAt:      sh.CreatedAt,
+		Cancellation:   toCancellationResponse(sh.Cancellation),
+	}
+	if sh.Route.Origin.CityCode != "" {
+		origin, destination := toStopDTO(sh.Route.Origin), toStopDTO(sh.Route.Destination)
+		resp.Origin, resp.Destination = &origin, &destination
+		for _, w := range sh.Route.Waypoints {
+			resp.Waypoints = append(resp.Waypoints, toStopDTO(w))
+		}
+	}
+	return resp
 }
 
 // ===== Handlers =====
 
+// shipmentID разбирает {id} пути: UUID или трек-номер. При ошибке ответ уже записан.
+func (h *Handler) shipmentID(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	ref := r.PathValue("id")
+	if id, err := uuid.Parse(ref); err == nil {
+		return id, true
+	}
+	id, err := h.service.ShipmentIDByTrackingNumber(r.Context(), ref)
+	if errors.Is(err, shservice.ErrInvalidTrackingNumber) {
+		badRequest(w, r, codeMalformedRequest, "invalid shipment id",
+			problemField{Field: "id", Message: "must be a UUID or a tracking number"})
+		return uuid.Nil, false
+	}
+	if err != nil {
+		writeError(w, r, err)
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
 // Create — POST /api/v1/shipments
 func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPost {
-		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	var req createShipmentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		badRequest(w, r, codeMalformedRequest, "invalid json body")
 		return
 	}
 
-	var req createShipmentRequest
+	result, err := h.service.CreateShipment(r.Context(), req.toService())
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	resp := createShipmentResponse{
+		ID:             result.ID,
+		TrackingNumber: result.TrackingNumber,
+		Status:         result.Status,
+		CustomerID:     result.CustomerID,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		slog.Error("error encoding response", "err", err)
+	}
+}
+
+// Transition — POST /api/v1/shipments/{id}/transitions
+func (h *Handler) Transition(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.shipmentID(w, r)
+	if !ok {
+		return
+	}
+
+	var req transitionRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "invalid json body", http.StatusBadRequest)
+		badRequest(w, r, codeMalformedRequest, "invalid json body")
 		return
 	}
 
-	result, err := h.service.CreateShipment(
+	sh, err := h.service.Transition(
 		r.Context(),
-		shservice.CreateShipmentInput{
-			Route: req.Route,
-			Price: req.Price,
-			IDN:   req.Customer.IDN,
+		shservice.TransitionInput{
+			ShipmentID:   id,
+			To:           shservice.Status(req.Status),
+			EventDetails: req.toService(),
 		},
 	)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeError(w, r, err)
 		return
 	}
 
-	resp := createShipmentResponse{
-		ID:         result.ID,
-		Status:     result.Status,
-		CustomerID: result.CustomerID,
+	writeShipment(w, sh)
+}
+
+// Get — GET /api/v1/shipments/{id}
+func (h *Handler) Get(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.shipmentID(w, r)
+	if !ok {
+		return
+	}
+
+	sh, err := h.service.GetShipment(r.Context(), id)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeShipment(w, sh)
+}
+
+// List — GET /api/v1/shipments
+func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
+	in, err := parseListQuery(r.URL.Query())
+	if err != nil {
+		writeParamError(w, r, err)
+		return
+	}
+
+	result, err := h.service.ListShipments(r.Context(), in)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	resp := listShipmentsResponse{
+		Items:      make([]shipmentResponse, 0, len(result.Items)),
+		NextCursor: result.NextCursor,
+	}
+	for _, sh := range result.Items {
+		resp.Items = append(resp.Items, toShipmentResponse(sh))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		slog.Error("error encoding response", "err", err)
+	}
+}
+
+// AddEvent — POST /api/v1/shipments/{id}/events
+func (h *Handler) AddEvent(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.shipmentID(w, r)
+	if !ok {
+		return
+	}
+
+	var req addEventRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		badRequest(w, r, codeMalformedRequest, "invalid json body")
+		return
+	}
+
+	e, err := h.service.AddEvent(
+		r.Context(),
+		shservice.AddEventInput{
+			ShipmentID:   id,
+			Type:         shservice.EventType(req.Type),
+			EventDetails: req.toService(),
+		},
+	)
+	if err != nil {
+		writeError(w, r, err)
+		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(toEventResponse(e)); err != nil {
+		slog.Error("error encoding response", "err", err)
+	}
+}
+
+// Events — GET /api/v1/shipments/{id}/events
+func (h *Handler) Events(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.shipmentID(w, r)
+	if !ok {
+		return
+	}
+
+	events, err := h.service.ListEvents(r.Context(), id)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	resp := make([]eventResponse, 0, len(events))
+	for _, e := range events {
+		resp = append(resp, toEventResponse(e))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
 	if err := json.NewEncoder(w).Encode(resp); err != nil {
 		slog.Error("error encoding response", "err", err)
 	}
@@ -0,0 +1,134 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+
+	shservice "transline.kz/internal/shipment/service"
+)
+
+const (
+	idempotencyKeyHeader = "Idempotency-Key"
+	maxIdempotencyKeyLen = 255
+	// maxIdempotentBody — не меньше maxBatchBody, чтобы пакетные запросы тоже были идемпотентны
+	maxIdempotentBody = maxBatchBody
+)
+
+// responseRecorder пишет ответ клиенту и параллельно копит его для сохранения
+type responseRecorder struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (rec *responseRecorder) WriteHeader(code int) {
+	if rec.status == 0 {
+		rec.status = code
+	}
+	rec.ResponseWriter.WriteHeader(code)
+}
+
+func (rec *responseRecorder) Write(b []byte) (int, error) {
+	if rec.status == 0 {
+		rec.status = http.StatusOK
+	}
+	rec.body.Write(b)
+	return rec.ResponseWriter.Write(b)
+}
+
+// Idempotent оборачивает handler поддержкой заголовка Idempotency-Key:
+// повтор с тем же телом получает сохранённый ответ, повтор с другим телом — 409.
+// Ответы 5xx не сохраняются, чтобы клиент мог повторить запрос.
+func (h *Handler) Idempotent(ttl time.Duration, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get(idempotencyKeyHeader)
+		if key == "" {
+			next(w, r)
+			return
+		}
+		if len(key) > maxIdempotencyKeyLen {
+			badRequest(w, r, codeMalformedRequest, "idempotency key is too long",
+				problemField{Field: idempotencyKeyHeader, Message: "max 255 chars"})
+			return
+		}
+
+		body, err := io.ReadAll(io.LimitReader(r.Body, maxIdempotentBody+1))
+		if err != nil {
+			badRequest(w, r, codeMalformedRequest, "failed to read request body")
+			return
+		}
+		if len(body) > maxIdempotentBody {
+			p := newProblem(r, http.StatusRequestEntityTooLarge, codeRequestTooLarge, "Request body is too large")
+			writeProblem(w, p)
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		scope := r.Method + " " + r.URL.Path
+		// query (например, mode пакета) меняет смысл запроса и входит в хеш;
+		// без query хеш прежний, чтобы не сломать уже сохранённые ключи
+		prefix := scope + "\n"
+		if r.URL.RawQuery != "" {
+			prefix = scope + "?" + r.URL.RawQuery + "\n"
+		}
+		sum := sha256.Sum256(append([]byte(prefix), body...))
+		hash := hex.EncodeToString(sum[:])
+
+		claim, stored, err := h.service.BeginIdempotent(r.Context(), scope, key, hash, ttl)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+
+		if stored != nil {
+			if stored.ContentType != "" {
+				w.Header().Set("Content-Type", stored.ContentType)
+			}
+			w.Header().Set("Idempotent-Replayed", "true")
+			w.WriteHeader(stored.StatusCode)
+			if _, err := w.Write(stored.Body); err != nil {
+				slog.Error("error writing replayed response", "err", err)
+			}
+			return
+		}
+
+		rec := &responseRecorder{ResponseWriter: w}
+		// ключ освобождается и при панике, иначе повторы получали бы 409 до lockTimeout
+		completed := false
+		ctx := context.WithoutCancel(r.Context())
+		defer func() {
+			if completed {
+				return
+			}
+			if err := h.service.AbortIdempotent(ctx, claim); err != nil {
+				slog.Error("error releasing idempotency key", "err", err)
+			}
+		}()
+
+		// обработчик ограничен по времени: после lockTimeout ключ может занять
+		// повтор, и к этому моменту первый запрос уже не должен ничего записывать
+		hctx, cancel := context.WithTimeout(r.Context(), shservice.IdempotentHandlerTimeout)
+		defer cancel()
+		next(rec, r.WithContext(hctx))
+
+		if rec.status == 0 || rec.status >= 500 {
+			return
+		}
+		err = h.service.CompleteIdempotent(ctx, claim, shservice.StoredResponse{
+			StatusCode:  rec.status,
+			ContentType: rec.Header().Get("Content-Type"),
+			Body:        rec.body.Bytes(),
+		})
+		if err != nil {
+			slog.Error("error storing idempotent response", "err", err)
+			return
+		}
+		completed = true
+	}
+}
@@ -0,0 +1,90 @@
+package http
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"transline.kz/internal/location"
+)
+
+type oblastDTO struct {
+	KATO   string `json:"kato"`
+	Name   string `json:"name"`
+	NameRU string `json:"nameRu"`
+	NameKK string `json:"nameKk"`
+}
+
+type locationResponse struct {
+	Code   string     `json:"code"`
+	KATO   string     `json:"kato"`
+	Name   string     `json:"name"`
+	NameRU string     `json:"nameRu"`
+	NameKK string     `json:"nameKk"`
+	Oblast *oblastDTO `json:"oblast,omitempty"`
+	Lat    float64    `json:"lat"`
+	Lon    float64    `json:"lon"`
+	// MatchedName — вариант названия, совпавший с запросом (только в поиске)
+	MatchedName string `json:"matchedName,omitempty"`
+}
+
+type searchLocationsResponse struct {
+	Items []locationResponse `json:"items"`
+}
+
+func (h *Handler) toLocationResponse(c *location.City) locationResponse {
+	resp := locationResponse{
+		Code:   c.Code,
+		KATO:   c.KATO,
+		Name:   c.Name,
+		NameRU: c.NameRU,
+		NameKK: c.NameKK,
+		Lat:    c.Latitude,
+		Lon:    c.Longitude,
+	}
+	if o := h.service.LocationOblast(c); o != nil {
+		resp.Oblast = &oblastDTO{KATO: o.KATO, Name: o.Name, NameRU: o.NameRU, NameKK: o.NameKK}
+	}
+	return resp
+}
+
+// SearchLocations — GET /api/v1/locations?q=алма&limit=5
+func (h *Handler) SearchLocations(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	var limit int
+	if v := q.Get("limit"); v != "" {
+		var err error
+		if limit, err = strconv.Atoi(v); err != nil {
+			writeParamError(w, r, &paramError{param: "limit", msg: "must be an integer"})
+			return
+		}
+	}
+
+	matches := h.service.SearchLocations(q.Get("q"), limit)
+	resp := searchLocationsResponse{Items: make([]locationResponse, 0, len(matches))}
+	for _, m := range matches {
+		item := h.toLocationResponse(m.City)
+		item.MatchedName = m.Variant
+		resp.Items = append(resp.Items, item)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		slog.Error("error encoding response", "err", err)
+	}
+}
+
+// GetLocation — GET /api/v1/locations/{code}; code — канонический код или КАТО
+func (h *Handler) GetLocation(w http.ResponseWriter, r *http.Request) {
+	c, err := h.service.GetLocation(r.PathValue("code"))
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(h.toLocationResponse(c)); err != nil {
+		slog.Error("error encoding response", "err", err)
+	}
+}
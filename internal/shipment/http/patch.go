@@ -0,0 +1,254 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"mime"
+	"net/http"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	shservice "transline.kz/internal/shipment/service"
+)
+
+const mergePatchContentType = "application/merge-patch+json"
+
+// maxPatchBody — предел тела PATCH; с запасом на 100 мест и 8 точек
+const maxPatchBody = 1 << 20
+
+// etag — сильный ETag представления отправления: меняется вместе с версией
+func etag(version int64) string {
+	return `"` + strconv.FormatInt(version, 10) + `"`
+}
+
+// parseETag разбирает значение If-Match; слабые ETag (W/"…") и списки не
+// подходят для сравнения версий
+func parseETag(s string) (int64, bool) {
+	s = strings.TrimSpace(s)
+	if len(s) < 2 || s[0] != '"' || s[len(s)-1] != '"' {
+		return 0, false
+	}
+	v, err := strconv.ParseInt(s[1:len(s)-1], 10, 64)
+	return v, err == nil && v > 0
+}
+
+// writeShipment отдаёт отправление с ETag текущей версии
+func writeShipment(w http.ResponseWriter, sh *shservice.Shipment) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("ETag", etag(sh.Version))
+	if err := json.NewEncoder(w).Encode(toShipmentResponse(sh)); err != nil {
+		slog.Error("error encoding response", "err", err)
+	}
+}
+
+// editableFields — поля представления, которые меняет PATCH; остальные только для чтения
+var editableFields = map[string]bool{
+	"origin":      true,
+	"waypoints":   true,
+	"destination": true,
+	"price":       true,
+	"currency":    true,
+	"cargo":       true,
+}
+
+// Patch — PATCH /api/v1/shipments/{id}, JSON Merge Patch (RFC 7386) к
+// представлению из GET. Требует If-Match с ETag; при несовпадении — 412.
+func (h *Handler) Patch(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.shipmentID(w, r)
+	if !ok {
+		return
+	}
+
+	if mt, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mt != mergePatchContentType && mt != "application/json" {
+		p := newProblem(r, http.StatusUnsupportedMediaType, codeUnsupportedMediaType, "Unsupported media type")
+		p.Detail = "use Content-Type: " + mergePatchContentType
+		w.Header().Set("Accept-Patch", mergePatchContentType)
+		writeProblem(w, p)
+		return
+	}
+
+	ifMatch := r.Header.Get("If-Match")
+	if ifMatch == "" {
+		p := newProblem(r, http.StatusPreconditionRequired, codePreconditionRequired, "Precondition required")
+		p.Detail = "If-Match with the shipment ETag is required"
+		writeProblem(w, p)
+		return
+	}
+	version, ok := parseETag(ifMatch)
+	if !ok {
+		p := newProblem(r, http.StatusPreconditionFailed, codePreconditionFailed, "Precondition failed")
+		p.Detail = "If-Match must be a single strong ETag from GET"
+		writeProblem(w, p)
+		return
+	}
+
+	var patch map[string]any
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxPatchBody))
+	dec.UseNumber()
+	if err := dec.Decode(&patch); err != nil || patch == nil {
+		badRequest(w, r, codeMalformedRequest, "body must be a JSON object")
+		return
+	}
+
+	current, err := h.service.GetShipment(r.Context(), id)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	if current.Version != version {
+		writeError(w, r, fmt.Errorf("%w: version is %d, not %d", shservice.ErrVersionMismatch, current.Version, version))
+		return
+	}
+
+	in, fields, err := applyShipmentPatch(current, patch)
+	if err != nil {
+		badRequest(w, r, codeMalformedRequest, err.Error())
+		return
+	}
+	if len(fields) > 0 {
+		p := newProblem(r, http.StatusUnprocessableEntity, codeValidationFailed, "Validation failed")
+		p.Detail = "read-only fields cannot be changed"
+		p.Errors = fields
+		writeProblem(w, p)
+		return
+	}
+	in.ShipmentID, in.Version = id, version
+
+	sh, err := h.service.UpdateShipment(r.Context(), in)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	writeShipment(w, sh)
+}
+
+// applyShipmentPatch применяет merge patch к текущему представлению и
+// собирает изменения редактируемых полей; fields — попытки изменить поля
+// только для чтения
+func applyShipmentPatch(current *shservice.Shipment, patch map[string]any) (shservice.UpdateShipmentInput, []problemField, error) {
+	var in shservice.UpdateShipmentInput
+	before, err := toDocument(toShipmentResponse(current))
+	if err != nil {
+		return in, nil, err
+	}
+	// mergePatch меняет цель на месте — патчим копию
+	target, err := toDocument(toShipmentResponse(current))
+	if err != nil {
+		return in, nil, err
+	}
+	after := mergePatch(target, patch).(map[string]any)
+
+	var fields []problemField
+	changed := func(key string) bool { return !reflect.DeepEqual(before[key], after[key]) }
+	for _, key := range slices.Sorted(maps.Keys(patch)) {
+		if editableFields[key] || !changed(key) {
+			continue
+		}
+		msg := "is read-only"
+		if key == "status" {
+			msg = "is changed via /transitions or /cancel"
+		}
+		fields = append(fields, problemField{Field: key, Message: msg})
+	}
+	if len(fields) > 0 {
+		return in, fields, nil
+	}
+
+	var doc struct {
+		Origin      *stopDTO    `json:"origin"`
+		Waypoints   []stopDTO   `json:"waypoints"`
+		Destination *stopDTO    `json:"destination"`
+		Price       json.Number `json:"price"`
+		Currency    string      `json:"currency"`
+		Cargo       *struct {
+			Items []itemDTO `json:"items"`
+		} `json:"cargo"`
+	}
+	b, _ := json.Marshal(after)
+	if err := json.Unmarshal(b, &doc); err != nil {
+		var te *json.UnmarshalTypeError
+		if errors.As(err, &te) {
+			return in, nil, fmt.Errorf("invalid patch: %s must be %s", te.Field, te.Type)
+		}
+		return in, nil, errors.New("invalid patch")
+	}
+
+	if changed("origin") || changed("waypoints") || changed("destination") {
+		var route shservice.Route
+		if doc.Origin != nil {
+			route.Origin = doc.Origin.toService()
+		}
+		for _, w := range doc.Waypoints {
+			route.Waypoints = append(route.Waypoints, w.toService())
+		}
+		if doc.Destination != nil {
+			route.Destination = doc.Destination.toService()
+		}
+		in.Route = &route
+	}
+	if changed("price") || changed("currency") {
+		price := doc.Price.String()
+		in.Price, in.Currency = &price, &doc.Currency
+	}
+	if changed("cargo") {
+		// итоги в cargo вычисляются; меняются только места
+		bc, _ := before["cargo"].(map[string]any)
+		ac, _ := after["cargo"].(map[string]any)
+		for key, v := range ac {
+			if key != "items" && !reflect.DeepEqual(bc[key], v) {
+				return in, []problemField{{Field: "cargo." + key, Message: "is computed from items"}}, nil
+			}
+		}
+		var items []shservice.ItemInput
+		if doc.Cargo != nil {
+			items = toItemInputs(doc.Cargo.Items)
+		}
+		in.Items = &items
+	}
+	return in, nil, nil
+}
+
+// toDocument — представление как JSON-объект с числами json.Number
+func toDocument(v any) (map[string]any, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	var doc map[string]any
+	if err := dec.Decode(&doc); err != nil {
+		return nil, err
+	}
+	if doc == nil {
+		return nil, errors.New("representation is not an object")
+	}
+	return doc, nil
+}
+
+// mergePatch — RFC 7386: null удаляет ключ, объекты сливаются рекурсивно,
+// всё остальное (включая массивы) заменяется целиком
+func mergePatch(target, patch any) any {
+	p, ok := patch.(map[string]any)
+	if !ok {
+		return patch
+	}
+	t, ok := target.(map[string]any)
+	if !ok {
+		t = map[string]any{}
+	}
+	for k, v := range p {
+		if v == nil {
+			delete(t, k)
+			continue
+		}
+		t[k] = mergePatch(t[k], v)
+	}
+	return t
+}
@@ -0,0 +1,152 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"go.opentelemetry.io/otel/trace"
+	shservice "transline.kz/internal/shipment/service"
+)
+
+// Ошибки отдаются как RFC 7807 application/problem+json
+
+const problemContentType = "application/problem+json"
+
+// Машиночитаемые коды ошибок (поле code)
+const (
+	codeMalformedRequest           = "MALFORMED_REQUEST"
+	codeInvalidQuery               = "INVALID_QUERY"
+	codeValidationFailed           = "VALIDATION_FAILED"
+	codeShipmentNotFound           = "SHIPMENT_NOT_FOUND"
+	codeLocationNotFound           = "LOCATION_NOT_FOUND"
+	codeQuoteNotFound              = "QUOTE_NOT_FOUND"
+	codeWebhookNotFound            = "WEBHOOK_NOT_FOUND"
+	codeDeliveryNotFound           = "DELIVERY_NOT_FOUND"
+	codeInvalidTrackingNumber      = "INVALID_TRACKING_NUMBER"
+	codeRateLimited                = "RATE_LIMITED"
+	codeIllegalTransition          = "ILLEGAL_TRANSITION"
+	codeConcurrentUpdate           = "CONCURRENT_UPDATE"
+	codePreconditionFailed         = "PRECONDITION_FAILED"
+	codePreconditionRequired       = "PRECONDITION_REQUIRED"
+	codeUnsupportedMediaType       = "UNSUPPORTED_MEDIA_TYPE"
+	codeIdempotencyKeyReused       = "IDEMPOTENCY_KEY_REUSED"
+	codeIdempotencyKeyInFlight     = "IDEMPOTENCY_KEY_IN_FLIGHT"
+	codeCustomerServiceUnavailable = "CUSTOMER_SERVICE_UNAVAILABLE"
+	codeStorageUnavailable         = "STORAGE_UNAVAILABLE"
+	codeRequestTooLarge            = "REQUEST_TOO_LARGE"
+	codeInternal                   = "INTERNAL_ERROR"
+)
+
+type problemField struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+type problem struct {
+	Type     string         `json:"type"`
+	Title    string         `json:"title"`
+	Status   int            `json:"status"`
+	Detail   string         `json:"detail,omitempty"`
+	Instance string         `json:"instance,omitempty"`
+	Code     string         `json:"code"`
+	Errors   []problemField `json:"errors,omitempty"`
+	TraceID  string         `json:"traceId,omitempty"`
+}
+
+// errorMapping — как доменная ошибка выглядит снаружи. expose == false —
+// detail заменяется на title, чтобы не раскрывать внутренние сообщения.
+type errorMapping struct {
+	target error
+	status int
+	code   string
+	title  string
+	expose bool
+}
+
+var errorMappings = []errorMapping{
+	{shservice.ErrInvalidQuery, http.StatusBadRequest, codeInvalidQuery, "Invalid query parameters", true},
+	{shservice.ErrValidation, http.StatusUnprocessableEntity, codeValidationFailed, "Validation failed", true},
+	{shservice.ErrInvalidTrackingNumber, http.StatusBadRequest, codeInvalidTrackingNumber, "Invalid tracking number", true},
+	{shservice.ErrShipmentNotFound, http.StatusNotFound, codeShipmentNotFound, "Shipment not found", true},
+	{shservice.ErrLocationNotFound, http.StatusNotFound, codeLocationNotFound, "Location not found", true},
+	{shservice.ErrQuoteNotFound, http.StatusNotFound, codeQuoteNotFound, "Quote not found", true},
+	{shservice.ErrWebhookNotFound, http.StatusNotFound, codeWebhookNotFound, "Webhook subscription not found", true},
+	{shservice.ErrDeliveryNotFound, http.StatusNotFound, codeDeliveryNotFound, "Webhook delivery not found", true},
+	{shservice.ErrIllegalTransition, http.StatusConflict, codeIllegalTransition, "Illegal status transition", true},
+	{shservice.ErrStatusConflict, http.StatusConflict, codeConcurrentUpdate, "Shipment was modified concurrently", true},
+	{shservice.ErrVersionMismatch, http.StatusPreconditionFailed, codePreconditionFailed, "Shipment was modified since it was read", true},
+	{shservice.ErrIdempotencyKeyReused, http.StatusConflict, codeIdempotencyKeyReused, "Idempotency key reused", true},
+	{shservice.ErrIdempotencyKeyInFlight, http.StatusConflict, codeIdempotencyKeyInFlight, "Request is still being processed", true},
+	{shservice.ErrCustomerServiceUnavailable, http.StatusServiceUnavailable, codeCustomerServiceUnavailable, "Customer service is temporarily unavailable", false},
+	{shservice.ErrUnavailable, http.StatusServiceUnavailable, codeStorageUnavailable, "Storage is temporarily unavailable", false},
+}
+
+// writeError отображает ошибку сервиса в problem+json
+func writeError(w http.ResponseWriter, r *http.Request, err error) {
+	for _, m := range errorMappings {
+		if !errors.Is(err, m.target) {
+			continue
+		}
+
+		p := newProblem(r, m.status, m.code, m.title)
+		if m.expose {
+			p.Detail = err.Error()
+		} else {
+			slog.Error(m.title, "err", err, "path", r.URL.Path)
+		}
+
+		var verr *shservice.ValidationError
+		if errors.As(err, &verr) {
+			p.Detail = m.title
+			for _, f := range verr.Fields {
+				p.Errors = append(p.Errors, problemField{Field: f.Field, Message: f.Message})
+			}
+		}
+		if m.status == http.StatusServiceUnavailable || m.code == codeIdempotencyKeyInFlight {
+			w.Header().Set("Retry-After", "1")
+		}
+		writeProblem(w, p)
+		return
+	}
+
+	if errors.Is(err, context.Canceled) {
+		// клиент ушёл — отвечать некому
+		return
+	}
+
+	slog.Error("internal error", "err", err, "path", r.URL.Path)
+	writeProblem(w, newProblem(r, http.StatusInternalServerError, codeInternal, "Internal server error"))
+}
+
+// badRequest — запрос не удалось разобрать (JSON, path, query)
+func badRequest(w http.ResponseWriter, r *http.Request, code, detail string, fields ...problemField) {
+	p := newProblem(r, http.StatusBadRequest, code, "Bad request")
+	p.Detail = detail
+	p.Errors = fields
+	writeProblem(w, p)
+}
+
+func newProblem(r *http.Request, status int, code, title string) problem {
+	p := problem{
+		Type:     "about:blank",
+		Title:    title,
+		Status:   status,
+		Instance: r.URL.Path,
+		Code:     code,
+	}
+	if sc := trace.SpanContextFromContext(r.Context()); sc.HasTraceID() {
+		p.TraceID = sc.TraceID().String()
+	}
+	return p
+}
+
+func writeProblem(w http.ResponseWriter, p problem) {
+	w.Header().Set("Content-Type", problemContentType)
+	w.WriteHeader(p.Status)
+	if err := json.NewEncoder(w).Encode(p); err != nil {
+		slog.Error("error encoding problem", "err", err)
+	}
+}
@@ -0,0 +1,85 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	shservice "transline.kz/internal/shipment/service"
+)
+
+// parseListQuery разбирает query-параметры листинга:
+// customerIdn, status (через запятую), route, origin, destination, createdFrom, createdTo (RFC3339),
+// currency, priceMin, priceMax (десятичные строки), sort, limit, cursor
+func parseListQuery(q url.Values) (shservice.ListShipmentsInput, error) {
+	in := shservice.ListShipmentsInput{
+		CustomerIDN:     q.Get("customerIdn"),
+		RouteContains:   q.Get("route"),
+		OriginCity:      q.Get("origin"),
+		DestinationCity: q.Get("destination"),
+		Currency:        q.Get("currency"),
+		PriceMin:        q.Get("priceMin"),
+		PriceMax:        q.Get("priceMax"),
+		Sort:            q.Get("sort"),
+		Cursor:          q.Get("cursor"),
+	}
+
+	for _, v := range q["status"] {
+		for _, st := range strings.Split(v, ",") {
+			if st = strings.TrimSpace(st); st != "" {
+				in.Statuses = append(in.Statuses, shservice.Status(strings.ToUpper(st)))
+			}
+		}
+	}
+
+	var err error
+	if in.CreatedFrom, err = parseTimeParam(q, "createdFrom"); err != nil {
+		return in, err
+	}
+	if in.CreatedTo, err = parseTimeParam(q, "createdTo"); err != nil {
+		return in, err
+	}
+
+	if v := q.Get("limit"); v != "" {
+		if in.Limit, err = strconv.Atoi(v); err != nil {
+			return in, &paramError{param: "limit", msg: "must be an integer"}
+		}
+	}
+
+	return in, nil
+}
+
+func parseTimeParam(q url.Values, name string) (*time.Time, error) {
+	v := q.Get(name)
+	if v == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		return nil, &paramError{param: name, msg: "must be an RFC3339 timestamp"}
+	}
+	t = t.UTC()
+	return &t, nil
+}
+
+// paramError — query-параметр не удалось разобрать
+type paramError struct {
+	param string
+	msg   string
+}
+
+func (e *paramError) Error() string {
+	return "invalid " + e.param + ": " + e.msg
+}
+
+func writeParamError(w http.ResponseWriter, r *http.Request, err error) {
+	var perr *paramError
+	if errors.As(err, &perr) {
+		badRequest(w, r, codeInvalidQuery, err.Error(), problemField{Field: perr.param, Message: perr.msg})
+		return
+	}
+	badRequest(w, r, codeInvalidQuery, err.Error())
+}
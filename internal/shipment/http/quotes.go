@@ -0,0 +1,129 @@
+package http
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	shservice "transline.kz/internal/shipment/service"
+)
+
+type createQuoteRequest struct {
+	Route routeInput `json:"route"`
+	// WeightKg/VolumeM3 — строкой или JSON-числом, как price; либо Items
+	WeightKg     json.Number `json:"weightKg,omitempty"`
+	VolumeM3     json.Number `json:"volumeM3,omitempty"`
+	Items        []itemDTO   `json:"items,omitempty"`
+	ServiceLevel string      `json:"serviceLevel,omitempty"`
+	Options      []string    `json:"options,omitempty"`
+	Currency     string      `json:"currency,omitempty"`
+}
+
+type quoteLineDTO struct {
+	Code        string `json:"code"`
+	Description string `json:"description"`
+	Amount      string `json:"amount"`
+}
+
+type quoteResponse struct {
+	ID                 uuid.UUID      `json:"id"`
+	TariffVersion      int            `json:"tariffVersion"`
+	Route              string         `json:"route"`
+	DistanceKm         int            `json:"distanceKm"`
+	WeightKg           string         `json:"weightKg"`
+	VolumeM3           string         `json:"volumeM3"`
+	ChargeableWeightKg string         `json:"chargeableWeightKg"`
+	ServiceLevel       string         `json:"serviceLevel"`
+	Options            []string       `json:"options"`
+	Price              string         `json:"price"`
+	Currency           string         `json:"currency"`
+	TransitDays        int            `json:"transitDays"`
+	Breakdown          []quoteLineDTO `json:"breakdown"`
+	ExpiresAt          time.Time      `json:"expiresAt"`
+	CreatedAt          time.Time      `json:"createdAt"`
+	Used               bool           `json:"used"`
+}
+
+func toQuoteResponse(q *shservice.Quote) quoteResponse {
+	resp := quoteResponse{
+		ID:                 q.ID,
+		TariffVersion:      q.TariffVersion,
+		Route:              q.Route.String(),
+		DistanceKm:         q.DistanceKm,
+		WeightKg:           q.Weight.Kg(),
+		VolumeM3:           q.Volume.M3(),
+		ChargeableWeightKg: q.ChargeableWeight.Kg(),
+		ServiceLevel:       string(q.ServiceLevel),
+		Options:            q.Options,
+		Price:              q.Price.Amount(),
+		Currency:           string(q.Price.Currency()),
+		TransitDays:        q.TransitDays,
+		Breakdown:          make([]quoteLineDTO, 0, len(q.Breakdown)),
+		ExpiresAt:          q.ExpiresAt,
+		CreatedAt:          q.CreatedAt,
+		Used:               q.Used,
+	}
+	if resp.Options == nil {
+		resp.Options = []string{}
+	}
+	for _, l := range q.Breakdown {
+		resp.Breakdown = append(resp.Breakdown, quoteLineDTO{Code: l.Code, Description: l.Description, Amount: l.Amount.Amount()})
+	}
+	return resp
+}
+
+// CreateQuote — POST /api/v1/quotes
+func (h *Handler) CreateQuote(w http.ResponseWriter, r *http.Request) {
+	var req createQuoteRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		badRequest(w, r, codeMalformedRequest, "invalid json body")
+		return
+	}
+
+	// маршрут разбирается тем же routeInput, что и при создании отправления
+	var route shservice.CreateShipmentInput
+	req.Route.toService(&route)
+
+	q, err := h.service.CreateQuote(r.Context(), shservice.CreateQuoteInput{
+		Route:        route.Route,
+		RouteText:    route.RouteText,
+		Weight:       req.WeightKg.String(),
+		Volume:       req.VolumeM3.String(),
+		Items:        toItemInputs(req.Items),
+		ServiceLevel: req.ServiceLevel,
+		Options:      req.Options,
+		Currency:     req.Currency,
+	})
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(toQuoteResponse(q)); err != nil {
+		slog.Error("error encoding response", "err", err)
+	}
+}
+
+// GetQuote — GET /api/v1/quotes/{id}
+func (h *Handler) GetQuote(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		badRequest(w, r, codeMalformedRequest, "invalid quote id", problemField{Field: "id", Message: "must be a UUID"})
+		return
+	}
+
+	q, err := h.service.GetQuote(r.Context(), id)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(toQuoteResponse(q)); err != nil {
+		slog.Error("error encoding response", "err", err)
+	}
+}
@@ -0,0 +1,105 @@
+package http
+
+import (
+	"encoding/json"
+	"log/slog"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"transline.kz/internal/ratelimit"
+)
+
+type publicEventResponse struct {
+	Type       string    `json:"type"`
+	Status     string    `json:"status,omitempty"`
+	Location   string    `json:"location,omitempty"`
+	OccurredAt time.Time `json:"occurredAt"`
+}
+
+type trackResponse struct {
+	TrackingNumber string                `json:"trackingNumber"`
+	Status         string                `json:"status"`
+	Origin         string                `json:"origin,omitempty"`
+	Destination    string                `json:"destination,omitempty"`
+	Location       string                `json:"location,omitempty"`
+	UpdatedAt      time.Time             `json:"updatedAt"`
+	Events         []publicEventResponse `json:"events"`
+}
+
+// Track — GET /api/v1/track/{trackingNumber}, публичный, без авторизации
+func (h *Handler) Track(w http.ResponseWriter, r *http.Request) {
+	t, err := h.service.Track(r.Context(), r.PathValue("trackingNumber"))
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	resp := trackResponse{
+		TrackingNumber: t.TrackingNumber,
+		Status:         string(t.Status),
+		Origin:         t.Origin,
+		Destination:    t.Destination,
+		Location:       t.Location,
+		UpdatedAt:      t.UpdatedAt,
+		Events:         make([]publicEventResponse, 0, len(t.Events)),
+	}
+	for _, e := range t.Events {
+		resp.Events = append(resp.Events, publicEventResponse{
+			Type:       string(e.Type),
+			Status:     string(e.Status),
+			Location:   e.Location,
+			OccurredAt: e.OccurredAt,
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		slog.Error("error encoding response", "err", err)
+	}
+}
+
+// RateLimited ограничивает частоту запросов с одного IP; сверх лимита — 429
+// с Retry-After. trustedHops — число доверенных прокси перед сервисом, каждый
+// из которых дописывает адрес своего клиента в конец X-Forwarded-For; при 0
+// заголовок не учитывается.
+func (h *Handler) RateLimited(l *ratelimit.Limiter, trustedHops int, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ok, retryAfter := l.Allow(clientIP(r, trustedHops))
+		w.Header().Set("X-RateLimit-Limit", strconv.Itoa(l.Limit()))
+		if !ok {
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+			p := newProblem(r, http.StatusTooManyRequests, codeRateLimited, "Too many requests")
+			p.Detail = "rate limit exceeded, retry later"
+			writeProblem(w, p)
+			return
+		}
+		next(w, r)
+	}
+}
+
+// clientIP — адрес клиента: trustedHops-й справа адрес X-Forwarded-For (его
+// дописал ближайший к клиенту доверенный прокси; всё левее клиент мог подставить
+// сам), иначе адрес соединения
+func clientIP(r *http.Request, trustedHops int) string {
+	if trustedHops > 0 {
+		var hops []string
+		for _, h := range r.Header.Values("X-Forwarded-For") {
+			hops = append(hops, strings.Split(h, ",")...)
+		}
+		if len(hops) >= trustedHops {
+			if ip := net.ParseIP(strings.TrimSpace(hops[len(hops)-trustedHops])); ip != nil {
+				return ip.String()
+			}
+		}
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
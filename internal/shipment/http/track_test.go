@@ -0,0 +1,36 @@
+package http
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClientIP(t *testing.T) {
+	tests := []struct {
+		name        string
+		xff         []string
+		trustedHops int
+		want        string
+	}{
+		{"no proxy ignores header", []string{"203.0.113.7"}, 0, "192.0.2.1"},
+		{"one hop takes rightmost", []string{"203.0.113.7"}, 1, "203.0.113.7"},
+		{"spoofed entries on the left are ignored", []string{"10.0.0.1, 198.51.100.2, 203.0.113.7"}, 1, "203.0.113.7"},
+		{"two hops", []string{"10.0.0.1, 203.0.113.7, 172.18.0.5"}, 2, "203.0.113.7"},
+		{"repeated headers are one list", []string{"10.0.0.1", "203.0.113.7"}, 1, "203.0.113.7"},
+		{"fewer entries than hops", []string{"203.0.113.7"}, 2, "192.0.2.1"},
+		{"garbage falls back to connection", []string{"not-an-ip"}, 1, "192.0.2.1"},
+		{"missing header", nil, 1, "192.0.2.1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/api/v1/track/TL123", nil)
+			r.RemoteAddr = "192.0.2.1:54321"
+			for _, v := range tt.xff {
+				r.Header.Add("X-Forwarded-For", v)
+			}
+			if got := clientIP(r, tt.trustedHops); got != tt.want {
+				t.Errorf("clientIP() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,261 @@
+package http
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	shservice "transline.kz/internal/shipment/service"
+)
+
+type createWebhookRequest struct {
+	CustomerIDN string   `json:"customerIdn"`
+	URL         string   `json:"url"`
+	Secret      string   `json:"secret,omitempty"`
+	EventTypes  []string `json:"eventTypes"`
+}
+
+type webhookResponse struct {
+	ID         uuid.UUID `json:"id"`
+	CustomerID uuid.UUID `json:"customerId"`
+	URL        string    `json:"url"`
+	EventTypes []string  `json:"eventTypes"`
+	// Secret — только в ответе на создание
+	Secret    string    `json:"secret,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+func toWebhookResponse(wh *shservice.Webhook) webhookResponse {
+	return webhookResponse{
+		ID:         wh.ID,
+		CustomerID: wh.CustomerID,
+		URL:        wh.URL,
+		EventTypes: wh.EventTypes,
+		CreatedAt:  wh.CreatedAt,
+	}
+}
+
+type deliveryAttemptDTO struct {
+	Attempt     int       `json:"attempt"`
+	StatusCode  *int      `json:"statusCode,omitempty"`
+	Error       string    `json:"error,omitempty"`
+	DurationMs  int64     `json:"durationMs"`
+	AttemptedAt time.Time `json:"attemptedAt"`
+}
+
+type deliveryResponse struct {
+	ID             uuid.UUID            `json:"id"`
+	WebhookID      uuid.UUID            `json:"webhookId"`
+	EventID        uuid.UUID            `json:"eventId"`
+	EventType      string               `json:"eventType"`
+	State          string               `json:"state"`
+	Attempts       int                  `json:"attempts"`
+	NextAttemptAt  *time.Time           `json:"nextAttemptAt,omitempty"`
+	LastStatusCode *int                 `json:"lastStatusCode,omitempty"`
+	LastError      string               `json:"lastError,omitempty"`
+	CreatedAt      time.Time            `json:"createdAt"`
+	DeliveredAt    *time.Time           `json:"deliveredAt,omitempty"`
+	Payload        json.RawMessage      `json:"payload,omitempty"`
+	Log            []deliveryAttemptDTO `json:"log,omitempty"`
+}
+
+type listDeliveriesResponse struct {
+	Items      []deliveryResponse `json:"items"`
+	NextCursor string             `json:"nextCursor,omitempty"`
+}
+
+func toDeliveryResponse(d *shservice.Delivery) deliveryResponse {
+	resp := deliveryResponse{
+		ID:             d.ID,
+		WebhookID:      d.WebhookID,
+		EventID:        d.EventID,
+		EventType:      d.EventType,
+		State:          string(d.State),
+		Attempts:       d.Attempts,
+		NextAttemptAt:  d.NextAttemptAt,
+		LastStatusCode: d.LastStatusCode,
+		LastError:      d.LastError,
+		CreatedAt:      d.CreatedAt,
+		DeliveredAt:    d.DeliveredAt,
+		Payload:        d.Payload,
+	}
+	for _, a := range d.Log {
+		resp.Log = append(resp.Log, deliveryAttemptDTO{
+			Attempt:     a.Attempt,
+			StatusCode:  a.StatusCode,
+			Error:       a.Error,
+			DurationMs:  a.Duration.Milliseconds(),
+			AttemptedAt: a.AttemptedAt,
+		})
+	}
+	return resp
+}
+
+// CreateWebhook — POST /api/v1/webhooks
+func (h *Handler) CreateWebhook(w http.ResponseWriter, r *http.Request) {
+	var req createWebhookRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		badRequest(w, r, codeMalformedRequest, "invalid json body")
+		return
+	}
+
+	wh, err := h.service.CreateWebhook(r.Context(), shservice.CreateWebhookInput{
+		CustomerIDN: req.CustomerIDN,
+		URL:         req.URL,
+		Secret:      req.Secret,
+		EventTypes:  req.EventTypes,
+	})
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	resp := toWebhookResponse(wh)
+	resp.Secret = wh.Secret
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Location", "/api/v1/webhooks/"+wh.ID.String())
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		slog.Error("error encoding response", "err", err)
+	}
+}
+
+// ListWebhooks — GET /api/v1/webhooks?customerIdn=…
+func (h *Handler) ListWebhooks(w http.ResponseWriter, r *http.Request) {
+	hooks, err := h.service.ListWebhooks(r.Context(), r.URL.Query().Get("customerIdn"))
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	resp := struct {
+		Items []webhookResponse `json:"items"`
+	}{Items: make([]webhookResponse, 0, len(hooks))}
+	for _, wh := range hooks {
+		resp.Items = append(resp.Items, toWebhookResponse(wh))
+	}
+	writeJSON(w, resp)
+}
+
+// GetWebhook — GET /api/v1/webhooks/{id}
+func (h *Handler) GetWebhook(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathUUID(w, r, "id", "invalid webhook id")
+	if !ok {
+		return
+	}
+	wh, err := h.service.GetWebhook(r.Context(), id)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	writeJSON(w, toWebhookResponse(wh))
+}
+
+// DeleteWebhook — DELETE /api/v1/webhooks/{id}; журнал доставок удаляется вместе с подпиской
+func (h *Handler) DeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathUUID(w, r, "id", "invalid webhook id")
+	if !ok {
+		return
+	}
+	if err := h.service.DeleteWebhook(r.Context(), id); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListDeliveries — GET /api/v1/webhooks/{id}/deliveries?state=&limit=&cursor=,
+// от новых к старым
+func (h *Handler) ListDeliveries(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathUUID(w, r, "id", "invalid webhook id")
+	if !ok {
+		return
+	}
+	q := r.URL.Query()
+	in := shservice.ListDeliveriesInput{WebhookID: id, State: q.Get("state"), Cursor: q.Get("cursor")}
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			writeParamError(w, r, &paramError{param: "limit", msg: "must be an integer"})
+			return
+		}
+		in.Limit = n
+	}
+
+	result, err := h.service.ListDeliveries(r.Context(), in)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	resp := listDeliveriesResponse{
+		Items:      make([]deliveryResponse, 0, len(result.Items)),
+		NextCursor: result.NextCursor,
+	}
+	for _, d := range result.Items {
+		resp.Items = append(resp.Items, toDeliveryResponse(d))
+	}
+	writeJSON(w, resp)
+}
+
+// GetDelivery — GET /api/v1/webhooks/{id}/deliveries/{deliveryId}: payload и все попытки
+func (h *Handler) GetDelivery(w http.ResponseWriter, r *http.Request) {
+	webhookID, deliveryID, ok := deliveryPath(w, r)
+	if !ok {
+		return
+	}
+	d, err := h.service.GetDelivery(r.Context(), webhookID, deliveryID)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	writeJSON(w, toDeliveryResponse(d))
+}
+
+// Redeliver — POST /api/v1/webhooks/{id}/deliveries/{deliveryId}/redeliver;
+// 202: доставка поставлена в очередь и уйдёт ближайшим проходом
+func (h *Handler) Redeliver(w http.ResponseWriter, r *http.Request) {
+	webhookID, deliveryID, ok := deliveryPath(w, r)
+	if !ok {
+		return
+	}
+	d, err := h.service.Redeliver(r.Context(), webhookID, deliveryID)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	if err := json.NewEncoder(w).Encode(toDeliveryResponse(d)); err != nil {
+		slog.Error("error encoding response", "err", err)
+	}
+}
+
+func deliveryPath(w http.ResponseWriter, r *http.Request) (webhookID, deliveryID uuid.UUID, ok bool) {
+	if webhookID, ok = pathUUID(w, r, "id", "invalid webhook id"); !ok {
+		return
+	}
+	deliveryID, ok = pathUUID(w, r, "deliveryId", "invalid delivery id")
+	return
+}
+
+// pathUUID разбирает UUID из сегмента пути name; при ошибке отвечает 400
+func pathUUID(w http.ResponseWriter, r *http.Request, name, title string) (uuid.UUID, bool) {
+	id, err := uuid.Parse(r.PathValue(name))
+	if err != nil {
+		badRequest(w, r, codeMalformedRequest, title, problemField{Field: name, Message: "must be a UUID"})
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		slog.Error("error encoding response", "err", err)
+	}
+}
@@ -0,0 +1,67 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"transline.kz/internal/money"
+)
+
+// Cancellation — причина и сбор отмены; Fee — в валюте отправления,
+// CancelledAt проставляет БД
+type Cancellation struct {
+	Reason      string
+	Note        string
+	Fee         money.Money
+	CancelledAt time.Time
+}
+
+// Cancel переводит отправление из ev.StatusFrom в CANCELLED (compare-and-set,
+// иначе ErrStatusConflict), сохраняет причину и сбор и пишет событие ev и
+// ShipmentStatusChanged в outbox в той же транзакции
+func (r *Repo) Cancel(ctx context.Context, id uuid.UUID, c Cancellation, ev Event) (*Shipment, error) {
+	var s *Shipment
+	err := pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		row := tx.QueryRow(ctx, `
+      UPDATE shipments
+      SET status = $3,
+          cancel_reason = $4,
+          cancel_note = NULLIF($5, ''),
+          cancellation_fee = $6,
+          cancelled_at = now(),
+          version = version + 1
+      WHERE id = $1 AND status = $2
+      RETURNING `+shipmentColumns,
+			id, ev.StatusFrom, ev.StatusTo, c.Reason, c.Note, toNumeric(c.Fee))
+		var err error
+		s, err = scanShipment(row)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrStatusConflict
+		}
+		if err != nil {
+			return err
+		}
+		if err := loadStops(ctx, tx, s); err != nil {
+			return err
+		}
+		if err := loadItems(ctx, tx, s); err != nil {
+			return err
+		}
+
+		ev.ShipmentID = id
+		ev.Type = EventStatusChanged
+		e, err := insertEvent(ctx, tx, ev)
+		if err != nil {
+			return err
+		}
+		return publishStatusChanged(ctx, tx, s, e)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
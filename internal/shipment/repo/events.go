@@ -0,0 +1,86 @@
+package repo
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"transline.kz/internal/pgerr"
+)
+
+// Типы событий; совпадают с CHECK в shipment_events.type
+const (
+	EventStatusChanged = "STATUS_CHANGED"
+	EventLocationScan  = "LOCATION_SCAN"
+	EventNote          = "NOTE"
+)
+
+// Event — запись в хронологии отправления. Пустые строки хранятся как NULL.
+type Event struct {
+	ID         int64
+	ShipmentID uuid.UUID
+	Type       string
+	StatusFrom string
+	StatusTo   string
+	Location   string
+	Latitude   *float64
+	Longitude  *float64
+	Note       string
+	Actor      string
+	OccurredAt time.Time
+}
+
+const eventColumns = `id, shipment_id, type, COALESCE(status_from, ''), COALESCE(status_to, ''),
+      COALESCE(location, ''), latitude, longitude, COALESCE(note, ''), actor, occurred_at`
+
+func scanEvent(row pgx.Row) (*Event, error) {
+	e := Event{}
+	err := row.Scan(&e.ID, &e.ShipmentID, &e.Type, &e.StatusFrom, &e.StatusTo,
+		&e.Location, &e.Latitude, &e.Longitude, &e.Note, &e.Actor, &e.OccurredAt)
+	return &e, err
+}
+
+func insertEvent(ctx context.Context, q querier, e Event) (*Event, error) {
+	row := q.QueryRow(ctx, `
+    INSERT INTO shipment_events
+      (shipment_id, type, status_from, status_to, location, latitude, longitude, note, actor)
+    VALUES ($1, $2, NULLIF($3, ''), NULLIF($4, ''), NULLIF($5, ''), $6, $7, NULLIF($8, ''), $9)
+    RETURNING `+eventColumns,
+		e.ShipmentID, e.Type, e.StatusFrom, e.StatusTo, e.Location, e.Latitude, e.Longitude, e.Note, e.Actor)
+	return scanEvent(row)
+}
+
+// AddEvent записывает событие без смены статуса (скан, заметка)
+func (r *Repo) AddEvent(ctx context.Context, e Event) (*Event, error) {
+	out, err := insertEvent(ctx, r.db, e)
+	if pgerr.IsForeignKeyViolation(err) {
+		return nil, ErrNotFound
+	}
+	return out, err
+}
+
+// ListEvents возвращает хронологию отправления от старых к новым
+func (r *Repo) ListEvents(ctx context.Context, shipmentID uuid.UUID) ([]Event, error) {
+	rows, err := r.db.Query(ctx, `
+    SELECT `+eventColumns+`
+    FROM shipment_events
+    WHERE shipment_id = $1
+    ORDER BY occurred_at, id
+  `, shipmentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []Event
+	for rows.Next() {
+		e, err := scanEvent(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, *e)
+	}
+	return out, rows.Err()
+}
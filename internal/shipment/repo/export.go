@@ -0,0 +1,52 @@
+package repo
+
+import (
+	"context"
+
+	"transline.kz/internal/measure"
+)
+
+// ExportRow — отправление для выгрузки: без точек и мест, но с итогами по
+// местам, посчитанными в том же запросе
+type ExportRow struct {
+	*Shipment
+	Pieces int
+	Weight measure.Weight
+}
+
+// exportJoin — итоги по местам для каждой строки выгрузки
+const exportJoin = `
+    LEFT JOIN LATERAL (
+      SELECT sum(quantity)::bigint AS pieces, sum(quantity::bigint * weight_g)::bigint AS weight_g
+      FROM shipment_items
+      WHERE shipment_id = shipments.id
+    ) cargo ON true`
+
+// Export передаёт fn отправления по фильтру в порядке (SortBy, id) прямо из
+// курсора pgx, не накапливая их в памяти; f.Limit не применяется. Ошибка fn
+// прерывает выгрузку и возвращается как есть.
+func (r *Repo) Export(ctx context.Context, f ListFilter, fn func(*ExportRow) error) error {
+	q, args := listQuery(f, shipmentColumns+`, COALESCE(cargo.pieces, 0), COALESCE(cargo.weight_g, 0)`, exportJoin)
+
+	rows, err := r.db.Query(ctx, q, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			row     ExportRow
+			pieces  int64
+			weightG int64
+		)
+		if row.Shipment, err = scanShipment(rows, &pieces, &weightG); err != nil {
+			return err
+		}
+		row.Pieces, row.Weight = int(pieces), measure.Weight(weightG)
+		if err := fn(&row); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
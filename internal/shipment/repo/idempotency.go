@@ -0,0 +1,115 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// ErrIdempotencyClaimLost — ключ перехвачен другим запросом после lockTimeout
+var ErrIdempotencyClaimLost = errors.New("idempotency key claim was taken over")
+
+// IdempotencyRecord — сохранённый результат запроса с Idempotency-Key.
+// StatusCode == 0 означает, что запрос ещё обрабатывается.
+type IdempotencyRecord struct {
+	RequestHash  string
+	StatusCode   int
+	ContentType  string
+	ResponseBody []byte
+	CreatedAt    time.Time
+}
+
+// ClaimIdempotencyKey атомарно занимает ключ под токеном token. Ключ можно занять,
+// если его нет, он истёк, либо он завис в обработке дольше lockTimeout с тем же
+// хешем запроса. Если занять не удалось, возвращается текущая запись.
+func (r *Repo) ClaimIdempotencyKey(
+	ctx context.Context,
+	scope, key, requestHash string,
+	token uuid.UUID,
+	ttl, lockTimeout time.Duration,
+) (claimed bool, existing *IdempotencyRecord, err error) {
+	var ok bool
+	err = r.db.QueryRow(ctx, `
+    INSERT INTO idempotency_keys (scope, key, request_hash, claim_token, expires_at)
+    VALUES ($1, $2, $3, $6, now() + $4::interval)
+    ON CONFLICT (scope, key) DO UPDATE
+      SET request_hash = EXCLUDED.request_hash,
+          claim_token = EXCLUDED.claim_token,
+          status_code = NULL,
+          content_type = NULL,
+          response_body = NULL,
+          created_at = now(),
+          expires_at = EXCLUDED.expires_at
+      WHERE idempotency_keys.expires_at < now()
+         OR (idempotency_keys.status_code IS NULL
+             AND idempotency_keys.request_hash = EXCLUDED.request_hash
+             AND idempotency_keys.created_at < now() - $5::interval)
+    RETURNING true
+  `, scope, key, requestHash, ttl, lockTimeout, token).Scan(&ok)
+	if err == nil {
+		return true, nil, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return false, nil, err
+	}
+
+	rec := IdempotencyRecord{}
+	var status *int
+	err = r.db.QueryRow(ctx, `
+    SELECT request_hash, status_code, COALESCE(content_type, ''), response_body, created_at
+    FROM idempotency_keys
+    WHERE scope = $1 AND key = $2
+  `, scope, key).Scan(&rec.RequestHash, &status, &rec.ContentType, &rec.ResponseBody, &rec.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		// ключ успели освободить между запросами — пусть клиент повторит
+		return false, nil, nil
+	}
+	if err != nil {
+		return false, nil, err
+	}
+	if status != nil {
+		rec.StatusCode = *status
+	}
+	return false, &rec, nil
+}
+
+// CompleteIdempotencyKey сохраняет ответ для повторов. Если ключ уже
+// перехвачен другим запросом — ErrIdempotencyClaimLost, чужая заявка не трогается.
+func (r *Repo) CompleteIdempotencyKey(
+	ctx context.Context,
+	scope, key string,
+	token uuid.UUID,
+	statusCode int, contentType string, body []byte,
+) error {
+	tag, err := r.db.Exec(ctx, `
+    UPDATE idempotency_keys
+    SET status_code = $4, content_type = $5, response_body = $6
+    WHERE scope = $1 AND key = $2 AND claim_token = $3 AND status_code IS NULL
+  `, scope, key, token, statusCode, contentType, body)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrIdempotencyClaimLost
+	}
+	return nil
+}
+
+// ReleaseIdempotencyKey удаляет незавершённый ключ, чтобы запрос можно было
+// повторить; ключ, перехваченный другим запросом, не удаляется
+func (r *Repo) ReleaseIdempotencyKey(ctx context.Context, scope, key string, token uuid.UUID) error {
+	_, err := r.db.Exec(ctx, `
+    DELETE FROM idempotency_keys
+    WHERE scope = $1 AND key = $2 AND claim_token = $3 AND status_code IS NULL
+  `, scope, key, token)
+	return err
+}
+
+// DeleteExpiredIdempotencyKeys чистит истёкшие ключи, возвращает число удалённых
+func (r *Repo) DeleteExpiredIdempotencyKeys(ctx context.Context) (int64, error) {
+	tag, err := r.db.Exec(ctx, `DELETE FROM idempotency_keys WHERE expires_at < now()`)
+	return tag.RowsAffected(), err
+}
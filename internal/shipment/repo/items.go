@@ -0,0 +1,84 @@
+package repo
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"transline.kz/internal/measure"
+)
+
+// Item — грузовое место. JSON-теги нужны для shipment_sagas.items.
+// Нулевые габариты и пустые строки хранятся как NULL.
+type Item struct {
+	Seq         int            `json:"seq"`
+	Description string         `json:"description"`
+	Quantity    int            `json:"quantity"`
+	Weight      measure.Weight `json:"weightG"`
+	Length      measure.Length `json:"lengthMm,omitempty"`
+	Width       measure.Length `json:"widthMm,omitempty"`
+	Height      measure.Length `json:"heightMm,omitempty"`
+	// DeclaredValue — десятичная строка в валюте отправления
+	DeclaredValue string `json:"declaredValue,omitempty"`
+	Packaging     string `json:"packaging"`
+	HSCode        string `json:"hsCode,omitempty"`
+}
+
+func insertItems(ctx context.Context, q querier, shipmentID uuid.UUID, items []Item) error {
+	for _, it := range items {
+		_, err := q.Exec(ctx, `
+      INSERT INTO shipment_items
+        (shipment_id, seq, description, quantity, weight_g, length_mm, width_mm, height_mm,
+         declared_value, packaging, hs_code)
+      VALUES ($1, $2, $3, $4, $5, NULLIF($6, 0), NULLIF($7, 0), NULLIF($8, 0),
+        NULLIF($9, '')::numeric, $10, NULLIF($11, ''))
+    `, shipmentID, it.Seq, it.Description, it.Quantity, int64(it.Weight), int64(it.Length),
+			int64(it.Width), int64(it.Height), it.DeclaredValue, it.Packaging, it.HSCode)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// loadItems заполняет Items у переданных отправлений одним запросом
+func loadItems(ctx context.Context, q querier, shipments ...*Shipment) error {
+	if len(shipments) == 0 {
+		return nil
+	}
+	byID := make(map[uuid.UUID]*Shipment, len(shipments))
+	ids := make([]uuid.UUID, 0, len(shipments))
+	for _, s := range shipments {
+		byID[s.ID] = s
+		ids = append(ids, s.ID)
+	}
+
+	rows, err := q.Query(ctx, `
+    SELECT shipment_id, seq, description, quantity, weight_g, COALESCE(length_mm, 0),
+      COALESCE(width_mm, 0), COALESCE(height_mm, 0), COALESCE(declared_value::text, ''),
+      packaging, COALESCE(hs_code, '')
+    FROM shipment_items
+    WHERE shipment_id = ANY($1)
+    ORDER BY shipment_id, seq
+  `, ids)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			id uuid.UUID
+			it Item
+		)
+		if err := rows.Scan(&id, &it.Seq, &it.Description, &it.Quantity, (*int64)(&it.Weight),
+			(*int64)(&it.Length), (*int64)(&it.Width), (*int64)(&it.Height), &it.DeclaredValue,
+			&it.Packaging, &it.HSCode); err != nil {
+			return err
+		}
+		if s := byID[id]; s != nil {
+			s.Items = append(s.Items, it)
+		}
+	}
+	return rows.Err()
+}
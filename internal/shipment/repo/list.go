@@ -0,0 +1,153 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"transline.kz/internal/money"
+)
+
+// SortColumn — колонка сортировки; значения совпадают с именами в БД
+type SortColumn string
+
+const (
+	SortByCreatedAt SortColumn = "created_at"
+	SortByPrice     SortColumn = "price"
+)
+
+// Cursor — позиция keyset-пагинации: последний ключ сортировки и id
+type Cursor struct {
+	CreatedAt time.Time
+	Price     money.Money
+	ID        uuid.UUID
+}
+
+type ListFilter struct {
+	CustomerID      *uuid.UUID
+	Statuses        []string
+	RouteContains   string
+	OriginCity      string
+	DestinationCity string
+	CreatedFrom     *time.Time
+	CreatedTo       *time.Time
+	Currency        money.Currency
+	PriceMin        *money.Money
+	PriceMax        *money.Money
+
+	SortBy   SortColumn
+	SortDesc bool
+	After    *Cursor
+	Limit    int
+}
+
+// List возвращает отправления по фильтру в порядке (SortBy, id)
+func (r *Repo) List(ctx context.Context, f ListFilter) ([]*Shipment, error) {
+	q, args := listQuery(f, shipmentColumns, "")
+	args = append(args, f.Limit)
+	q += fmt.Sprintf("\n    LIMIT $%d", len(args))
+
+	rows, err := r.db.Query(ctx, q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []*Shipment
+	for rows.Next() {
+		s, err := scanShipment(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
+
+	if err := loadStops(ctx, r.db, out...); err != nil {
+		return nil, err
+	}
+	if err := loadItems(ctx, r.db, out...); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// listQuery — SELECT columns по фильтру f в порядке (SortBy, id) без LIMIT;
+// join присоединяется к FROM shipments
+func listQuery(f ListFilter, columns, join string) (string, []any) {
+	var (
+		where []string
+		args  []any
+	)
+	arg := func(v any) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	if f.CustomerID != nil {
+		where = append(where, "customer_id = "+arg(*f.CustomerID))
+	}
+	if len(f.Statuses) > 0 {
+		where = append(where, "status = ANY("+arg(f.Statuses)+")")
+	}
+	if f.RouteContains != "" {
+		where = append(where, "route ILIKE "+arg("%"+escapeLike(f.RouteContains)+"%"))
+	}
+	if f.OriginCity != "" {
+		where = append(where, "origin_city = "+arg(f.OriginCity))
+	}
+	if f.DestinationCity != "" {
+		where = append(where, "destination_city = "+arg(f.DestinationCity))
+	}
+	if f.CreatedFrom != nil {
+		where = append(where, "created_at >= "+arg(*f.CreatedFrom))
+	}
+	if f.CreatedTo != nil {
+		where = append(where, "created_at < "+arg(*f.CreatedTo))
+	}
+	if f.Currency != "" {
+		where = append(where, "currency = "+arg(string(f.Currency)))
+	}
+	if f.PriceMin != nil {
+		where = append(where, "price >= "+arg(toNumeric(*f.PriceMin)))
+	}
+	if f.PriceMax != nil {
+		where = append(where, "price <= "+arg(toNumeric(*f.PriceMax)))
+	}
+
+	col := f.SortBy
+	if col != SortByPrice {
+		col = SortByCreatedAt
+	}
+	dir, cmp := "ASC", ">"
+	if f.SortDesc {
+		dir, cmp = "DESC", "<"
+	}
+
+	if f.After != nil {
+		var key any = f.After.CreatedAt
+		if col == SortByPrice {
+			key = toNumeric(f.After.Price)
+		}
+		where = append(where, fmt.Sprintf("(%s, id) %s (%s, %s)", col, cmp, arg(key), arg(f.After.ID)))
+	}
+
+	q := `
+    SELECT ` + columns + `
+    FROM shipments` + join
+	if len(where) > 0 {
+		q += "\n    WHERE " + strings.Join(where, " AND ")
+	}
+	q += fmt.Sprintf("\n    ORDER BY %s %s, id %s", col, dir, dir)
+	return q, args
+}
+
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
@@ -0,0 +1,63 @@
+package repo
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"transline.kz/internal/money"
+)
+
+// toNumeric — сумма как точное значение NUMERIC (минорные единицы × 10^-exp)
+func toNumeric(m money.Money) pgtype.Numeric {
+	return pgtype.Numeric{
+		Int:   big.NewInt(m.Minor()),
+		Exp:   -int32(m.Currency().Exponent()),
+		Valid: true,
+	}
+}
+
+// fromNumeric переводит NUMERIC в минорные единицы валюты c без округления
+func fromNumeric(n pgtype.Numeric, c money.Currency) (money.Money, error) {
+	if !n.Valid || n.NaN || n.InfinityModifier != pgtype.Finite {
+		return money.Money{}, fmt.Errorf("amount %v is not a finite number", n)
+	}
+
+	v := new(big.Int).Set(n.Int)
+	shift := int64(n.Exp) + int64(c.Exponent())
+	pow := new(big.Int).Exp(big.NewInt(10), big.NewInt(abs(shift)), nil)
+	if shift >= 0 {
+		v.Mul(v, pow)
+	} else {
+		var rem big.Int
+		v.QuoRem(v, pow, &rem)
+		if rem.Sign() != 0 {
+			return money.Money{}, fmt.Errorf("amount has more than %d fractional digits for %s", c.Exponent(), c)
+		}
+	}
+	if !v.IsInt64() {
+		return money.Money{}, money.ErrOverflow
+	}
+	return money.FromMinor(v.Int64(), c), nil
+}
+
+// ratFromNumeric — NUMERIC как точная дробь (коэффициенты, проценты)
+func ratFromNumeric(n pgtype.Numeric) (*big.Rat, error) {
+	if !n.Valid || n.NaN || n.InfinityModifier != pgtype.Finite {
+		return nil, fmt.Errorf("value %v is not a finite number", n)
+	}
+	r := new(big.Rat).SetInt(n.Int)
+	pow := new(big.Int).Exp(big.NewInt(10), big.NewInt(abs(int64(n.Exp))), nil)
+	if n.Exp >= 0 {
+		return r.Mul(r, new(big.Rat).SetInt(pow)), nil
+	}
+	return r.Quo(r, new(big.Rat).SetInt(pow)), nil
+}
+
+func abs(v int64) int64 {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
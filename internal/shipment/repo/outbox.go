@@ -0,0 +1,93 @@
+package repo
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+
+	"transline.kz/internal/outbox"
+)
+
+// Данные событий outbox по отправлению. Контакты из точек маршрута не
+// публикуются: события уходят внешним подписчикам.
+type shipmentCreatedData struct {
+	ShipmentID      uuid.UUID `json:"shipmentId"`
+	TrackingNumber  string    `json:"trackingNumber"`
+	CustomerID      uuid.UUID `json:"customerId"`
+	Status          string    `json:"status"`
+	Route           string    `json:"route"`
+	OriginCity      string    `json:"originCity,omitempty"`
+	DestinationCity string    `json:"destinationCity,omitempty"`
+	Price           string    `json:"price"`
+	Currency        string    `json:"currency"`
+	CreatedAt       time.Time `json:"createdAt"`
+	Version         int64     `json:"version"`
+}
+
+type statusChangedData struct {
+	ShipmentID     uuid.UUID         `json:"shipmentId"`
+	TrackingNumber string            `json:"trackingNumber"`
+	CustomerID     uuid.UUID         `json:"customerId"`
+	StatusFrom     string            `json:"statusFrom"`
+	StatusTo       string            `json:"statusTo"`
+	Actor          string            `json:"actor"`
+	Note           string            `json:"note,omitempty"`
+	ChangedAt      time.Time         `json:"changedAt"`
+	Version        int64             `json:"version"`
+	Cancellation   *cancellationData `json:"cancellation,omitempty"`
+}
+
+type cancellationData struct {
+	Reason   string `json:"reason"`
+	Fee      string `json:"fee"`
+	Currency string `json:"currency"`
+}
+
+// publishCreated пишет ShipmentCreated в outbox транзакции tx
+func publishCreated(ctx context.Context, tx outbox.Execer, s *Shipment) error {
+	ev, err := outbox.NewEvent(outbox.ShipmentCreated, outbox.AggregateShipment, s.ID, shipmentCreatedData{
+		ShipmentID:      s.ID,
+		TrackingNumber:  s.TrackingNumber,
+		CustomerID:      s.CustomerID,
+		Status:          s.Status,
+		Route:           s.Route,
+		OriginCity:      s.OriginCity,
+		DestinationCity: s.DestinationCity,
+		Price:           s.Price.Amount(),
+		Currency:        string(s.Price.Currency()),
+		CreatedAt:       s.CreatedAt.UTC(),
+		Version:         s.Version,
+	})
+	if err != nil {
+		return err
+	}
+	return outbox.Insert(ctx, tx, ev)
+}
+
+// publishStatusChanged пишет ShipmentStatusChanged по событию хронологии e
+func publishStatusChanged(ctx context.Context, tx outbox.Execer, s *Shipment, e *Event) error {
+	data := statusChangedData{
+		ShipmentID:     s.ID,
+		TrackingNumber: s.TrackingNumber,
+		CustomerID:     s.CustomerID,
+		StatusFrom:     e.StatusFrom,
+		StatusTo:       e.StatusTo,
+		Actor:          e.Actor,
+		Note:           e.Note,
+		ChangedAt:      e.OccurredAt.UTC(),
+		Version:        s.Version,
+	}
+	if c := s.Cancellation; c != nil {
+		data.Cancellation = &cancellationData{
+			Reason:   c.Reason,
+			Fee:      c.Fee.Amount(),
+			Currency: string(c.Fee.Currency()),
+		}
+	}
+	ev, err := outbox.NewEvent(outbox.ShipmentStatusChanged, outbox.AggregateShipment, s.ID, data)
+	if err != nil {
+		return err
+	}
+	return outbox.Insert(ctx, tx, ev)
+}
@@ -2,19 +2,79 @@ package repo
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/jackc/pgx/v5/pgxpool"
+
+	"transline.kz/internal/money"
+)
+
+// querier — общее подмножество *pgxpool.Pool и pgx.Tx
+type querier interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+var (
+	ErrNotFound       = errors.New("shipment not found")
+	ErrStatusConflict = errors.New("shipment status was changed concurrently")
 )
 
 type Shipment struct {
-	ID         uuid.UUID
-	Route      string
-	Price      float64
-	Status     string
-	CustomerID uuid.UUID
-	CreatedAt  time.Time
+	ID              uuid.UUID
+	TrackingNumber  string
+	Route           string
+	OriginCity      string
+	DestinationCity string
+	Price           money.Money
+	Status          string
+	CustomerID      uuid.UUID
+	CreatedAt       time.Time
+	// Version растёт на 1 при каждом изменении строки
+	Version int64
+	// Stops и Items заполняются отдельными запросами к shipment_stops и shipment_items
+	Stops []Stop
+	Items []Item
+	// Cancellation — nil, если отправление не отменялось через Cancel
+	Cancellation *Cancellation
+}
+
+const shipmentColumns = `id, tracking_number, route, COALESCE(origin_city, ''), COALESCE(destination_city, ''),
+      price, currency, status, customer_id, created_at, version,
+      COALESCE(cancel_reason, ''), COALESCE(cancel_note, ''), cancellation_fee, cancelled_at`
+
+// scanShipment читает shipmentColumns; extra — дополнительные колонки после них
+func scanShipment(row pgx.Row, extra ...any) (*Shipment, error) {
+	var (
+		s        Shipment
+		price    pgtype.Numeric
+		currency string
+		c        Cancellation
+		fee      pgtype.Numeric
+	)
+	dest := append([]any{&s.ID, &s.TrackingNumber, &s.Route, &s.OriginCity, &s.DestinationCity, &price, &currency, &s.Status, &s.CustomerID, &s.CreatedAt, &s.Version,
+		&c.Reason, &c.Note, &fee, &c.CancelledAt}, extra...)
+	if err := row.Scan(dest...); err != nil {
+		return nil, err
+	}
+	var err error
+	if s.Price, err = fromNumeric(price, money.Currency(currency)); err != nil {
+		return nil, fmt.Errorf("shipment %s price: %w", s.ID, err)
+	}
+	if c.Reason != "" {
+		if c.Fee, err = fromNumeric(fee, s.Price.Currency()); err != nil {
+			return nil, fmt.Errorf("shipment %s cancellation fee: %w", s.ID, err)
+		}
+		s.Cancellation = &c
+	}
+	return &s, nil
 }
 
 type Repo struct {
@@ -25,15 +85,66 @@ func New(db *pgxpool.Pool) *Repo {
 	return &Repo{db: db}
 }
 
-func (r *Repo) Create(ctx context.Context, customerID uuid.UUID, route string, price float64) (*Shipment, error) {
-	id := uuid.New()
+func (r *Repo) Get(ctx context.Context, id uuid.UUID) (*Shipment, error) {
 	row := r.db.QueryRow(ctx, `
-    INSERT INTO shipments (id, route, price, customer_id)
-    VALUES ($1,$2,$3,$4)
-    RETURNING id, route, price, status, customer_id, created_at
-  `, id, route, price, customerID)
-
-	s := Shipment{}
-	err := row.Scan(&s.ID, &s.Route, &s.Price, &s.Status, &s.CustomerID, &s.CreatedAt)
-	return &s, err
+    SELECT `+shipmentColumns+`
+    FROM shipments
+    WHERE id = $1
+  `, id)
+
+	s, err := scanShipment(row)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := loadStops(ctx, r.db, s); err != nil {
+		return nil, err
+	}
+	if err := loadItems(ctx, r.db, s); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// UpdateStatus меняет статус, только если он всё ещё равен ev.StatusFrom
+// (compare-and-set), иначе возвращает ErrStatusConflict. Событие ev и
+// ShipmentStatusChanged в outbox пишутся в той же транзакции.
+func (r *Repo) UpdateStatus(ctx context.Context, id uuid.UUID, ev Event) (*Shipment, error) {
+	var s *Shipment
+	err := pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		row := tx.QueryRow(ctx, `
+      UPDATE shipments
+      SET status = $3, version = version + 1
+      WHERE id = $1 AND status = $2
+      RETURNING `+shipmentColumns,
+			id, ev.StatusFrom, ev.StatusTo)
+		var err error
+		s, err = scanShipment(row)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrStatusConflict
+		}
+		if err != nil {
+			return err
+		}
+		if err := loadStops(ctx, tx, s); err != nil {
+			return err
+		}
+		if err := loadItems(ctx, tx, s); err != nil {
+			return err
+		}
+
+		ev.ShipmentID = id
+		ev.Type = EventStatusChanged
+		e, err := insertEvent(ctx, tx, ev)
+		if err != nil {
+			return err
+		}
+		return publishStatusChanged(ctx, tx, s, e)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
 }
@@ -0,0 +1,150 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"transline.kz/internal/measure"
+	"transline.kz/internal/money"
+)
+
+var (
+	ErrQuoteNotFound = errors.New("quote not found")
+	// ErrQuoteUnavailable — котировка истекла или уже использована
+	ErrQuoteUnavailable = errors.New("quote expired or already used")
+)
+
+// QuoteLine — строка расшифровки цены; Amount — десятичная строка в валюте котировки
+type QuoteLine struct {
+	Code        string `json:"code"`
+	Description string `json:"description"`
+	Amount      string `json:"amount"`
+}
+
+type Quote struct {
+	ID               uuid.UUID
+	TariffID         int64
+	TariffVersion    int
+	OriginCity       string
+	DestinationCity  string
+	Waypoints        []string
+	DistanceKm       int
+	Weight           measure.Weight
+	Volume           measure.Volume
+	ChargeableWeight measure.Weight
+	ServiceLevel     string
+	Options          []string
+	Price            money.Money
+	TransitDays      int
+	Breakdown        []QuoteLine
+	ExpiresAt        time.Time
+	// ShipmentID — отправление, для которого котировка использована
+	ShipmentID *uuid.UUID
+	CreatedAt  time.Time
+}
+
+const quoteColumns = `q.id, q.tariff_id, t.version, q.origin_city, q.destination_city, q.waypoints,
+      q.distance_km, q.weight_g, q.volume_cm3, q.chargeable_weight_g, q.service_level, q.options,
+      q.price, q.currency, q.transit_days, q.breakdown, q.expires_at, q.shipment_id, q.created_at`
+
+func scanQuote(row pgx.Row) (*Quote, error) {
+	var (
+		q        Quote
+		price    pgtype.Numeric
+		currency string
+	)
+	err := row.Scan(&q.ID, &q.TariffID, &q.TariffVersion, &q.OriginCity, &q.DestinationCity, &q.Waypoints,
+		&q.DistanceKm, (*int64)(&q.Weight), (*int64)(&q.Volume), (*int64)(&q.ChargeableWeight),
+		&q.ServiceLevel, &q.Options, &price, &currency, &q.TransitDays, &q.Breakdown, &q.ExpiresAt,
+		&q.ShipmentID, &q.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	if q.Price, err = fromNumeric(price, money.Currency(currency)); err != nil {
+		return nil, fmt.Errorf("quote %s price: %w", q.ID, err)
+	}
+	return &q, nil
+}
+
+func (r *Repo) InsertQuote(ctx context.Context, q Quote) (*Quote, error) {
+	// nil-срез ушёл бы в NOT NULL колонку как NULL
+	if q.Waypoints == nil {
+		q.Waypoints = []string{}
+	}
+	if q.Options == nil {
+		q.Options = []string{}
+	}
+	row := r.db.QueryRow(ctx, `
+    WITH q AS (
+      INSERT INTO quotes (id, tariff_id, origin_city, destination_city, waypoints, distance_km,
+        weight_g, volume_cm3, chargeable_weight_g, service_level, options, price, currency,
+        transit_days, breakdown, expires_at)
+      VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)
+      RETURNING *
+    )
+    SELECT `+quoteColumns+`
+    FROM q JOIN tariffs t ON t.id = q.tariff_id
+  `, q.ID, q.TariffID, q.OriginCity, q.DestinationCity, q.Waypoints, q.DistanceKm,
+		int64(q.Weight), int64(q.Volume), int64(q.ChargeableWeight), q.ServiceLevel, q.Options,
+		toNumeric(q.Price), string(q.Price.Currency()), q.TransitDays, q.Breakdown, q.ExpiresAt)
+	return scanQuote(row)
+}
+
+func (r *Repo) GetQuote(ctx context.Context, id uuid.UUID) (*Quote, error) {
+	row := r.db.QueryRow(ctx, `
+    SELECT `+quoteColumns+`
+    FROM quotes q JOIN tariffs t ON t.id = q.tariff_id
+    WHERE q.id = $1
+  `, id)
+	q, err := scanQuote(row)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrQuoteNotFound
+	}
+	return q, err
+}
+
+// ShipmentQuote — котировка, по которой создано отправление; ErrQuoteNotFound,
+// если отправление создано без котировки
+func (r *Repo) ShipmentQuote(ctx context.Context, shipmentID uuid.UUID) (*Quote, error) {
+	row := r.db.QueryRow(ctx, `
+    SELECT `+quoteColumns+`
+    FROM quotes q JOIN tariffs t ON t.id = q.tariff_id
+    WHERE q.shipment_id = $1
+  `, shipmentID)
+	q, err := scanQuote(row)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrQuoteNotFound
+	}
+	return q, err
+}
+
+// claimQuote закрепляет котировку за отправлением, если она не истекла
+// и ещё не использована
+func claimQuote(ctx context.Context, q querier, quoteID, shipmentID uuid.UUID) error {
+	tag, err := q.Exec(ctx, `
+    UPDATE quotes
+    SET shipment_id = $2
+    WHERE id = $1 AND shipment_id IS NULL AND expires_at > now()
+  `, quoteID, shipmentID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrQuoteUnavailable
+	}
+	return nil
+}
+
+// releaseQuote освобождает котировку компенсированного отправления
+func releaseQuote(ctx context.Context, q querier, shipmentID uuid.UUID) error {
+	_, err := q.Exec(ctx, `
+    UPDATE quotes SET shipment_id = NULL WHERE shipment_id = $1
+  `, shipmentID)
+	return err
+}
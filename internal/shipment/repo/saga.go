@@ -0,0 +1,331 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"transline.kz/internal/money"
+	"transline.kz/internal/pgerr"
+)
+
+// Состояния saga создания отправления; совпадают с CHECK в shipment_sagas.state
+const (
+	SagaStarted          = "STARTED"
+	SagaCustomerUpserted = "CUSTOMER_UPSERTED"
+	SagaCompleted        = "COMPLETED"
+	SagaCompensating     = "COMPENSATING"
+	SagaCompensated      = "COMPENSATED"
+)
+
+var (
+	// ErrSagaConflict — saga уже в другом состоянии (её завершил или
+	// компенсирует другой процесс)
+	ErrSagaConflict = errors.New("shipment saga is in a different state")
+	// ErrCustomerMissing — клиент удалён между шагами (компенсация другой saga)
+	ErrCustomerMissing = errors.New("customer does not exist")
+)
+
+// BatchError — ошибка строки Index пакетной операции; транзакция пакета откачена
+type BatchError struct {
+	Index int
+	Err   error
+}
+
+func (e *BatchError) Error() string {
+	return fmt.Sprintf("batch row %d: %v", e.Index, e.Err)
+}
+
+func (e *BatchError) Unwrap() error {
+	return e.Err
+}
+
+type Saga struct {
+	ID              uuid.UUID
+	IDN             string
+	Route           string
+	Stops           []Stop
+	Items           []Item
+	Price           money.Money
+	QuoteID         *uuid.UUID
+	Actor           string
+	State           string
+	CustomerID      *uuid.UUID
+	CustomerCreated bool
+	Attempts        int
+	LastError       string
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+}
+
+const sagaColumns = `id, idn, route, stops, items, price, currency, quote_id, actor, state, customer_id, customer_created,
+      attempts, COALESCE(last_error, ''), created_at, updated_at`
+
+func scanSaga(row pgx.Row) (*Saga, error) {
+	var (
+		s        Saga
+		price    pgtype.Numeric
+		currency string
+	)
+	err := row.Scan(&s.ID, &s.IDN, &s.Route, &s.Stops, &s.Items, &price, &currency, &s.QuoteID, &s.Actor, &s.State, &s.CustomerID,
+		&s.CustomerCreated, &s.Attempts, &s.LastError, &s.CreatedAt, &s.UpdatedAt)
+	if err != nil {
+		return &s, err
+	}
+	s.Price, err = fromNumeric(price, money.Currency(currency))
+	return &s, err
+}
+
+// StartSaga записывает сагу; если задан QuoteID, в той же транзакции закрепляет
+// котировку (ErrQuoteUnavailable, если она истекла или уже использована)
+func (r *Repo) StartSaga(ctx context.Context, s Saga) (*Saga, error) {
+	var out *Saga
+	err := pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		var err error
+		out, err = startSaga(ctx, tx, s)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// StartSagas — StartSaga для пакета в одной транзакции: либо записаны все
+// саги, либо ни одной (ошибка строки — *BatchError)
+func (r *Repo) StartSagas(ctx context.Context, sagas []Saga) ([]*Saga, error) {
+	out := make([]*Saga, len(sagas))
+	err := pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		for i, s := range sagas {
+			sg, err := startSaga(ctx, tx, s)
+			if err != nil {
+				return &BatchError{Index: i, Err: err}
+			}
+			out[i] = sg
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func startSaga(ctx context.Context, tx pgx.Tx, s Saga) (*Saga, error) {
+	// nil-срез ушёл бы в NOT NULL колонку как NULL
+	if s.Items == nil {
+		s.Items = []Item{}
+	}
+	if s.QuoteID != nil {
+		if err := claimQuote(ctx, tx, *s.QuoteID, s.ID); err != nil {
+			return nil, err
+		}
+	}
+	row := tx.QueryRow(ctx, `
+    INSERT INTO shipment_sagas (id, idn, route, stops, items, price, currency, quote_id, actor, state)
+    VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+    RETURNING `+sagaColumns,
+		s.ID, s.IDN, s.Route, s.Stops, s.Items, toNumeric(s.Price), string(s.Price.Currency()), s.QuoteID,
+		s.Actor, SagaStarted)
+	return scanSaga(row)
+}
+
+// SagaCustomerUpserted фиксирует результат первого шага
+func (r *Repo) SagaCustomerUpserted(ctx context.Context, id, customerID uuid.UUID, created bool) error {
+	tag, err := r.db.Exec(ctx, `
+    UPDATE shipment_sagas
+    SET state = $2, customer_id = $3, customer_created = customer_created OR $4,
+        attempts = attempts + 1, updated_at = now()
+    WHERE id = $1 AND state IN ($5, $2)
+  `, id, SagaCustomerUpserted, customerID, created, SagaStarted)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrSagaConflict
+	}
+	return nil
+}
+
+// CompleteSaga — второй шаг: вставляет отправление с id саги и новым трек-номером,
+// его точки и места, первое событие хронологии и ShipmentCreated в outbox и
+// переводит сагу в COMPLETED в одной транзакции
+func (r *Repo) CompleteSaga(ctx context.Context, id uuid.UUID) (*Shipment, error) {
+	numbers, err := reserveTrackingNumbers(ctx, r.db, time.Now(), 1)
+	if err != nil {
+		return nil, err
+	}
+	var s *Shipment
+	err = pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		var err error
+		s, err = completeSaga(ctx, tx, id, numbers[0])
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// CompleteSagas — CompleteSaga для пакета в одной транзакции: либо созданы
+// все отправления, либо ни одного (ошибка строки — *BatchError)
+func (r *Repo) CompleteSagas(ctx context.Context, ids []uuid.UUID) ([]*Shipment, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	numbers, err := reserveTrackingNumbers(ctx, r.db, time.Now(), len(ids))
+	if err != nil {
+		return nil, err
+	}
+	out := make([]*Shipment, len(ids))
+	err = pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		for i, id := range ids {
+			s, err := completeSaga(ctx, tx, id, numbers[i])
+			if err != nil {
+				return &BatchError{Index: i, Err: err}
+			}
+			out[i] = s
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func completeSaga(ctx context.Context, tx pgx.Tx, id uuid.UUID, number string) (*Shipment, error) {
+	var (
+		customerID *uuid.UUID
+		route      string
+		stops      []Stop
+		items      []Item
+		price      pgtype.Numeric
+		currency   string
+		actor      string
+	)
+	err := tx.QueryRow(ctx, `
+      UPDATE shipment_sagas
+      SET state = $2, updated_at = now()
+      WHERE id = $1 AND state = $3
+      RETURNING customer_id, route, stops, items, price, currency, actor
+    `, id, SagaCompleted, SagaCustomerUpserted).Scan(&customerID, &route, &stops, &items, &price, &currency, &actor)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrSagaConflict
+	}
+	if err != nil {
+		return nil, err
+	}
+	if customerID == nil {
+		return nil, ErrSagaConflict
+	}
+
+	origin, destination := endpoints(stops)
+	row := tx.QueryRow(ctx, `
+      INSERT INTO shipments (id, tracking_number, route, origin_city, destination_city, price, currency, customer_id)
+      VALUES ($1,$2,$3,$4,$5,$6,$7,$8)
+      RETURNING `+shipmentColumns,
+		id, number, route, origin, destination, price, currency, *customerID)
+	s, err := scanShipment(row)
+	if err != nil {
+		if pgerr.IsForeignKeyViolation(err) {
+			return nil, ErrCustomerMissing
+		}
+		return nil, err
+	}
+	if err := insertStops(ctx, tx, s.ID, stops); err != nil {
+		return nil, err
+	}
+	s.Stops = stops
+	if err := insertItems(ctx, tx, s.ID, items); err != nil {
+		return nil, err
+	}
+	s.Items = items
+
+	_, err = insertEvent(ctx, tx, Event{
+		ShipmentID: s.ID,
+		Type:       EventStatusChanged,
+		StatusTo:   s.Status,
+		Actor:      actor,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := publishCreated(ctx, tx, s); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// BeginSagaCompensation переводит незавершённую сагу в COMPENSATING
+func (r *Repo) BeginSagaCompensation(ctx context.Context, id uuid.UUID, cause string) (*Saga, error) {
+	row := r.db.QueryRow(ctx, `
+    UPDATE shipment_sagas
+    SET state = $2, last_error = NULLIF($3, ''), attempts = attempts + 1, updated_at = now()
+    WHERE id = $1 AND state IN ($4, $5, $2)
+    RETURNING `+sagaColumns,
+		id, SagaCompensating, cause, SagaStarted, SagaCustomerUpserted)
+	s, err := scanSaga(row)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrSagaConflict
+	}
+	return s, err
+}
+
+// FinishSagaCompensation переводит сагу в COMPENSATED и освобождает её котировку
+func (r *Repo) FinishSagaCompensation(ctx context.Context, id uuid.UUID) error {
+	return pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		tag, err := tx.Exec(ctx, `
+      UPDATE shipment_sagas
+      SET state = $2, updated_at = now()
+      WHERE id = $1 AND state = $3
+    `, id, SagaCompensated, SagaCompensating)
+		if err != nil {
+			return err
+		}
+		if tag.RowsAffected() == 0 {
+			return ErrSagaConflict
+		}
+		return releaseQuote(ctx, tx, id)
+	})
+}
+
+// RecordSagaError сохраняет последнюю ошибку без смены состояния
+func (r *Repo) RecordSagaError(ctx context.Context, id uuid.UUID, cause string) error {
+	_, err := r.db.Exec(ctx, `
+    UPDATE shipment_sagas
+    SET last_error = $2, updated_at = now()
+    WHERE id = $1
+  `, id, cause)
+	return err
+}
+
+// StaleSagas возвращает незавершённые саги, не менявшиеся дольше olderThan
+func (r *Repo) StaleSagas(ctx context.Context, olderThan time.Duration, limit int) ([]Saga, error) {
+	rows, err := r.db.Query(ctx, `
+    SELECT `+sagaColumns+`
+    FROM shipment_sagas
+    WHERE state NOT IN ($1, $2) AND updated_at < now() - $3::interval
+    ORDER BY updated_at
+    LIMIT $4
+  `, SagaCompleted, SagaCompensated, olderThan, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []Saga
+	for rows.Next() {
+		s, err := scanSaga(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, *s)
+	}
+	return out, rows.Err()
+}
@@ -0,0 +1,145 @@
+package repo
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// Виды точек маршрута; совпадают с CHECK в shipment_stops.kind
+const (
+	StopOrigin      = "ORIGIN"
+	StopWaypoint    = "WAYPOINT"
+	StopDestination = "DESTINATION"
+)
+
+// Stop — точка маршрута. JSON-теги нужны для shipment_sagas.stops.
+// Пустые строки хранятся как NULL.
+type Stop struct {
+	Seq          int      `json:"seq"`
+	Kind         string   `json:"kind"`
+	CityCode     string   `json:"cityCode"`
+	Address      string   `json:"address,omitempty"`
+	Latitude     *float64 `json:"lat,omitempty"`
+	Longitude    *float64 `json:"lon,omitempty"`
+	ContactName  string   `json:"contactName,omitempty"`
+	ContactPhone string   `json:"contactPhone,omitempty"`
+}
+
+// endpoints возвращает коды городов отправления и назначения
+func endpoints(stops []Stop) (origin, destination *string) {
+	for i := range stops {
+		switch stops[i].Kind {
+		case StopOrigin:
+			origin = &stops[i].CityCode
+		case StopDestination:
+			destination = &stops[i].CityCode
+		}
+	}
+	return origin, destination
+}
+
+func insertStops(ctx context.Context, q querier, shipmentID uuid.UUID, stops []Stop) error {
+	for _, st := range stops {
+		_, err := q.Exec(ctx, `
+      INSERT INTO shipment_stops
+        (shipment_id, seq, kind, city_code, address, latitude, longitude, contact_name, contact_phone)
+      VALUES ($1, $2, $3, $4, NULLIF($5, ''), $6, $7, NULLIF($8, ''), NULLIF($9, ''))
+    `, shipmentID, st.Seq, st.Kind, st.CityCode, st.Address, st.Latitude, st.Longitude,
+			st.ContactName, st.ContactPhone)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// loadStops заполняет Stops у переданных отправлений одним запросом
+func loadStops(ctx context.Context, q querier, shipments ...*Shipment) error {
+	if len(shipments) == 0 {
+		return nil
+	}
+	byID := make(map[uuid.UUID]*Shipment, len(shipments))
+	ids := make([]uuid.UUID, 0, len(shipments))
+	for _, s := range shipments {
+		byID[s.ID] = s
+		ids = append(ids, s.ID)
+	}
+
+	rows, err := q.Query(ctx, `
+    SELECT shipment_id, seq, kind, city_code, COALESCE(address, ''), latitude, longitude,
+      COALESCE(contact_name, ''), COALESCE(contact_phone, '')
+    FROM shipment_stops
+    WHERE shipment_id = ANY($1)
+    ORDER BY shipment_id, seq
+  `, ids)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			id uuid.UUID
+			st Stop
+		)
+		if err := rows.Scan(&id, &st.Seq, &st.Kind, &st.CityCode, &st.Address, &st.Latitude,
+			&st.Longitude, &st.ContactName, &st.ContactPhone); err != nil {
+			return err
+		}
+		if s := byID[id]; s != nil {
+			s.Stops = append(s.Stops, st)
+		}
+	}
+	return rows.Err()
+}
+
+// DistinctCityCodes — все коды городов, встречающиеся в точках маршрутов
+func (r *Repo) DistinctCityCodes(ctx context.Context) ([]string, error) {
+	rows, err := r.db.Query(ctx, `
+    SELECT DISTINCT city_code
+    FROM shipment_stops
+    ORDER BY city_code
+  `)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowTo[string])
+}
+
+// RenameCityCode заменяет код города в точках маршрутов, в денормализованных
+// колонках и строке маршрута shipments; возвращает число изменённых отправлений
+func (r *Repo) RenameCityCode(ctx context.Context, from, to string) (int64, error) {
+	var n int64
+	err := pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		rows, err := tx.Query(ctx, `
+      UPDATE shipment_stops SET city_code = $2
+      WHERE city_code = $1
+      RETURNING shipment_id
+    `, from, to)
+		if err != nil {
+			return err
+		}
+		ids, err := pgx.CollectRows(rows, pgx.RowTo[uuid.UUID])
+		if err != nil {
+			return err
+		}
+
+		tag, err := tx.Exec(ctx, `
+      UPDATE shipments s
+      SET route = (
+            SELECT string_agg(city_code, '→' ORDER BY seq)
+            FROM shipment_stops st
+            WHERE st.shipment_id = s.id
+          ),
+          origin_city = CASE WHEN origin_city = $2 THEN $3 ELSE origin_city END,
+          destination_city = CASE WHEN destination_city = $2 THEN $3 ELSE destination_city END,
+          version = version + 1
+      WHERE id = ANY($1)
+    `, ids, from, to)
+		n = tag.RowsAffected()
+		return err
+	})
+	return n, err
+}
@@ -0,0 +1,167 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"transline.kz/internal/money"
+)
+
+// Виды надбавок; совпадают с CHECK в tariff_surcharges.kind
+const (
+	SurchargePercent = "PERCENT"
+	SurchargeFixed   = "FIXED"
+)
+
+var ErrNoTariff = errors.New("no active tariff")
+
+type Tariff struct {
+	ID                int64
+	Version           int
+	Currency          money.Currency
+	ValidFrom         time.Time
+	ValidTo           *time.Time
+	MinPrice          money.Money
+	VolumetricKgPerM3 int64
+	RoadFactor        *big.Rat
+	Rates             []TariffRate
+	Surcharges        []TariffSurcharge
+}
+
+// TariffRate — ставка для уровня сервиса в поясе до MaxDistanceKm (nil — без ограничения)
+type TariffRate struct {
+	ServiceLevel  string
+	MaxDistanceKm *int
+	BasePrice     money.Money
+	PerKm         money.Money
+	PerKg         money.Money
+	TransitDays   int
+}
+
+// TariffSurcharge — надбавка: Percent для PERCENT, Amount для FIXED
+type TariffSurcharge struct {
+	Code        string
+	Kind        string
+	Percent     *big.Rat
+	Amount      money.Money
+	Mandatory   bool
+	Description string
+}
+
+// ActiveTariff возвращает действующую на момент at версию тарифа в валюте c
+func (r *Repo) ActiveTariff(ctx context.Context, c money.Currency, at time.Time) (*Tariff, error) {
+	var (
+		t          Tariff
+		currency   string
+		minPrice   pgtype.Numeric
+		roadFactor pgtype.Numeric
+	)
+	err := r.db.QueryRow(ctx, `
+    SELECT id, version, currency, valid_from, valid_to, min_price, volumetric_kg_per_m3, road_factor
+    FROM tariffs
+    WHERE currency = $1 AND valid_from <= $2 AND (valid_to IS NULL OR valid_to > $2)
+    ORDER BY valid_from DESC, version DESC
+    LIMIT 1
+  `, string(c), at).Scan(&t.ID, &t.Version, &currency, &t.ValidFrom, &t.ValidTo, &minPrice,
+		&t.VolumetricKgPerM3, &roadFactor)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNoTariff
+	}
+	if err != nil {
+		return nil, err
+	}
+	t.Currency = money.Currency(currency)
+	if t.MinPrice, err = fromNumeric(minPrice, t.Currency); err != nil {
+		return nil, fmt.Errorf("tariff %d min_price: %w", t.ID, err)
+	}
+	if t.RoadFactor, err = ratFromNumeric(roadFactor); err != nil {
+		return nil, fmt.Errorf("tariff %d road_factor: %w", t.ID, err)
+	}
+
+	if t.Rates, err = r.tariffRates(ctx, t.ID, t.Currency); err != nil {
+		return nil, err
+	}
+	if t.Surcharges, err = r.tariffSurcharges(ctx, t.ID, t.Currency); err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// tariffRates — ставки тарифа, пояса по возрастанию расстояния
+func (r *Repo) tariffRates(ctx context.Context, tariffID int64, c money.Currency) ([]TariffRate, error) {
+	rows, err := r.db.Query(ctx, `
+    SELECT service_level, max_distance_km, base_price, per_km, per_kg, transit_days
+    FROM tariff_rates
+    WHERE tariff_id = $1
+    ORDER BY service_level, max_distance_km NULLS LAST
+  `, tariffID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []TariffRate
+	for rows.Next() {
+		var (
+			rate               TariffRate
+			base, perKm, perKg pgtype.Numeric
+		)
+		if err := rows.Scan(&rate.ServiceLevel, &rate.MaxDistanceKm, &base, &perKm, &perKg, &rate.TransitDays); err != nil {
+			return nil, err
+		}
+		if rate.BasePrice, err = fromNumeric(base, c); err != nil {
+			return nil, err
+		}
+		if rate.PerKm, err = fromNumeric(perKm, c); err != nil {
+			return nil, err
+		}
+		if rate.PerKg, err = fromNumeric(perKg, c); err != nil {
+			return nil, err
+		}
+		out = append(out, rate)
+	}
+	return out, rows.Err()
+}
+
+func (r *Repo) tariffSurcharges(ctx context.Context, tariffID int64, c money.Currency) ([]TariffSurcharge, error) {
+	rows, err := r.db.Query(ctx, `
+    SELECT code, kind, value, mandatory, description
+    FROM tariff_surcharges
+    WHERE tariff_id = $1
+    ORDER BY mandatory DESC, code
+  `, tariffID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []TariffSurcharge
+	for rows.Next() {
+		var (
+			sc    TariffSurcharge
+			value pgtype.Numeric
+		)
+		if err := rows.Scan(&sc.Code, &sc.Kind, &value, &sc.Mandatory, &sc.Description); err != nil {
+			return nil, err
+		}
+		switch sc.Kind {
+		case SurchargePercent:
+			sc.Percent, err = ratFromNumeric(value)
+		case SurchargeFixed:
+			sc.Amount, err = fromNumeric(value, c)
+		default:
+			err = fmt.Errorf("unknown surcharge kind %q", sc.Kind)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("tariff %d surcharge %s: %w", tariffID, sc.Code, err)
+		}
+		out = append(out, sc)
+	}
+	return out, rows.Err()
+}
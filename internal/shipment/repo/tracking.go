@@ -0,0 +1,50 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"transline.kz/internal/tracking"
+)
+
+// reserveTrackingNumbers выдаёт n следующих трек-номеров за день момента now.
+// Счётчик увеличивается отдельным коротким запросом вне транзакции создания:
+// строка дня блокируется только на время UPDATE, а не до коммита, и создание
+// отправлений не выстраивается в очередь. Цена — дыры в нумерации от
+// откатившихся вставок; номера за день всё равно возрастают.
+func reserveTrackingNumbers(ctx context.Context, db querier, now time.Time, n int) ([]string, error) {
+	day := tracking.Day(now)
+	var last int
+	err := db.QueryRow(ctx, `
+    INSERT INTO tracking_sequences (day, last_seq)
+    VALUES ($1::date, $2)
+    ON CONFLICT (day) DO UPDATE SET last_seq = tracking_sequences.last_seq + EXCLUDED.last_seq
+    RETURNING last_seq
+  `, day.Format(time.DateOnly), n).Scan(&last)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]string, n)
+	for i := range out {
+		if out[i], err = tracking.Format(day, last-n+1+i); err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+// IDByTrackingNumber ищет отправление по нормализованному трек-номеру
+func (r *Repo) IDByTrackingNumber(ctx context.Context, number string) (uuid.UUID, error) {
+	var id uuid.UUID
+	err := r.db.QueryRow(ctx, `
+    SELECT id FROM shipments WHERE tracking_number = $1
+  `, number).Scan(&id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return uuid.Nil, ErrNotFound
+	}
+	return id, err
+}
@@ -0,0 +1,75 @@
+package repo
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"transline.kz/internal/money"
+)
+
+// ErrVersionConflict — строка изменена после чтения: версия не совпала
+var ErrVersionConflict = errors.New("shipment version conflict")
+
+// ShipmentUpdate — новое состояние редактируемых полей; точки маршрута и
+// грузовые места заменяются целиком
+type ShipmentUpdate struct {
+	Route string
+	Stops []Stop
+	Price money.Money
+	Items []Item
+}
+
+// Update перезаписывает маршрут, цену и грузовые места, только если версия
+// всё ещё равна version (иначе ErrVersionConflict), и увеличивает её. Событие
+// ev пишется в той же транзакции.
+func (r *Repo) Update(ctx context.Context, id uuid.UUID, version int64, u ShipmentUpdate, ev Event) (*Shipment, error) {
+	var s *Shipment
+	err := pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		origin, destination := endpoints(u.Stops)
+		row := tx.QueryRow(ctx, `
+      UPDATE shipments
+      SET route = $3,
+          origin_city = $4,
+          destination_city = $5,
+          price = $6,
+          currency = $7,
+          version = version + 1
+      WHERE id = $1 AND version = $2
+      RETURNING `+shipmentColumns,
+			id, version, u.Route, origin, destination, toNumeric(u.Price), string(u.Price.Currency()))
+		var err error
+		s, err = scanShipment(row)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrVersionConflict
+		}
+		if err != nil {
+			return err
+		}
+
+		if _, err := tx.Exec(ctx, `DELETE FROM shipment_stops WHERE shipment_id = $1`, id); err != nil {
+			return err
+		}
+		if err := insertStops(ctx, tx, id, u.Stops); err != nil {
+			return err
+		}
+		s.Stops = u.Stops
+		if _, err := tx.Exec(ctx, `DELETE FROM shipment_items WHERE shipment_id = $1`, id); err != nil {
+			return err
+		}
+		if err := insertItems(ctx, tx, id, u.Items); err != nil {
+			return err
+		}
+		s.Items = u.Items
+
+		ev.ShipmentID = id
+		_, err = insertEvent(ctx, tx, ev)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
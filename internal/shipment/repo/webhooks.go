@@ -0,0 +1,342 @@
+package repo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"transline.kz/internal/pgerr"
+)
+
+var (
+	ErrWebhookNotFound  = errors.New("webhook subscription not found")
+	ErrDeliveryNotFound = errors.New("webhook delivery not found")
+)
+
+// Состояния доставки; совпадают с CHECK в webhook_deliveries.state
+const (
+	DeliveryPending   = "PENDING"
+	DeliveryDelivered = "DELIVERED"
+	DeliveryDead      = "DEAD"
+)
+
+type WebhookSubscription struct {
+	ID         uuid.UUID
+	CustomerID uuid.UUID
+	URL        string
+	Secret     string
+	EventTypes []string
+	CreatedAt  time.Time
+}
+
+const webhookColumns = `id, customer_id, url, secret, event_types, created_at`
+
+func scanWebhook(row pgx.Row) (*WebhookSubscription, error) {
+	var w WebhookSubscription
+	err := row.Scan(&w.ID, &w.CustomerID, &w.URL, &w.Secret, &w.EventTypes, &w.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrWebhookNotFound
+	}
+	return &w, err
+}
+
+// WebhookDelivery — доставка события подписке. NextAttemptAt — nil после
+// DELIVERED и DEAD; LastStatusCode — nil, если ответа не было.
+type WebhookDelivery struct {
+	ID             uuid.UUID
+	SubscriptionID uuid.UUID
+	EventID        uuid.UUID
+	EventType      string
+	Payload        json.RawMessage
+	State          string
+	Attempts       int
+	NextAttemptAt  *time.Time
+	LastStatusCode *int
+	LastError      string
+	CreatedAt      time.Time
+	DeliveredAt    *time.Time
+}
+
+const deliveryColumns = `d.id, d.subscription_id, d.event_id, d.event_type, d.payload, d.state, d.attempts,
+      d.next_attempt_at, d.last_status_code, COALESCE(d.last_error, ''), d.created_at, d.delivered_at`
+
+func scanDelivery(row pgx.Row, extra ...any) (*WebhookDelivery, error) {
+	var d WebhookDelivery
+	dest := append([]any{&d.ID, &d.SubscriptionID, &d.EventID, &d.EventType, &d.Payload, &d.State, &d.Attempts,
+		&d.NextAttemptAt, &d.LastStatusCode, &d.LastError, &d.CreatedAt, &d.DeliveredAt}, extra...)
+	err := row.Scan(dest...)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrDeliveryNotFound
+	}
+	return &d, err
+}
+
+// DeliveryAttempt — запись журнала попыток; StatusCode — nil при ошибке сети
+type DeliveryAttempt struct {
+	Attempt     int
+	StatusCode  *int
+	Error       string
+	Duration    time.Duration
+	AttemptedAt time.Time
+}
+
+// CreateWebhook сохраняет подписку; ErrCustomerMissing — клиента нет
+func (r *Repo) CreateWebhook(ctx context.Context, w WebhookSubscription) (*WebhookSubscription, error) {
+	row := r.db.QueryRow(ctx, `
+    INSERT INTO webhook_subscriptions (id, customer_id, url, secret, event_types)
+    VALUES ($1, $2, $3, $4, $5)
+    RETURNING `+webhookColumns,
+		w.ID, w.CustomerID, w.URL, w.Secret, w.EventTypes)
+	out, err := scanWebhook(row)
+	if pgerr.IsForeignKeyViolation(err) {
+		return nil, ErrCustomerMissing
+	}
+	return out, err
+}
+
+func (r *Repo) GetWebhook(ctx context.Context, id uuid.UUID) (*WebhookSubscription, error) {
+	row := r.db.QueryRow(ctx, `
+    SELECT `+webhookColumns+`
+    FROM webhook_subscriptions
+    WHERE id = $1
+  `, id)
+	return scanWebhook(row)
+}
+
+// ListWebhooks возвращает подписки клиента (все, если customerID == nil)
+// от старых к новым
+func (r *Repo) ListWebhooks(ctx context.Context, customerID *uuid.UUID) ([]WebhookSubscription, error) {
+	rows, err := r.db.Query(ctx, `
+    SELECT `+webhookColumns+`
+    FROM webhook_subscriptions
+    WHERE $1::uuid IS NULL OR customer_id = $1
+    ORDER BY created_at, id
+  `, customerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []WebhookSubscription
+	for rows.Next() {
+		w, err := scanWebhook(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, *w)
+	}
+	return out, rows.Err()
+}
+
+// DeleteWebhook удаляет подписку вместе с журналом доставок
+func (r *Repo) DeleteWebhook(ctx context.Context, id uuid.UUID) error {
+	tag, err := r.db.Exec(ctx, `
+    DELETE FROM webhook_subscriptions
+    WHERE id = $1
+  `, id)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrWebhookNotFound
+	}
+	return nil
+}
+
+// EnqueueDeliveries ставит событие в очередь всем подпискам клиента на
+// eventType. Повтор события новых доставок не создаёт.
+func (r *Repo) EnqueueDeliveries(ctx context.Context, customerID uuid.UUID, eventType string, eventID uuid.UUID, payload []byte) (int64, error) {
+	tag, err := r.db.Exec(ctx, `
+    INSERT INTO webhook_deliveries (id, subscription_id, event_id, event_type, payload, state, next_attempt_at)
+    SELECT gen_random_uuid(), s.id, $3, $2, $4, $5, now()
+    FROM webhook_subscriptions s
+    WHERE s.customer_id = $1 AND $2 = ANY(s.event_types)
+    ON CONFLICT (subscription_id, event_id) DO NOTHING
+  `, customerID, eventType, eventID, payload, DeliveryPending)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
+// DueDelivery — доставка к отправке вместе с адресом и ключом подписки
+type DueDelivery struct {
+	WebhookDelivery
+	URL    string
+	Secret string
+}
+
+// ClaimDeliveries забирает до limit доставок, срок которых наступил, и
+// откладывает их на lease: если процесс упадёт во время отправки, доставка
+// вернётся в очередь по истечении lease
+func (r *Repo) ClaimDeliveries(ctx context.Context, limit int, lease time.Duration) ([]DueDelivery, error) {
+	rows, err := r.db.Query(ctx, `
+    UPDATE webhook_deliveries d
+    SET next_attempt_at = now() + $3::interval
+    FROM webhook_subscriptions s
+    WHERE s.id = d.subscription_id
+      AND d.id IN (
+        SELECT id
+        FROM webhook_deliveries
+        WHERE state = $1 AND next_attempt_at <= now()
+        ORDER BY next_attempt_at
+        LIMIT $2
+        FOR UPDATE SKIP LOCKED
+      )
+    RETURNING `+deliveryColumns+`, s.url, s.secret
+  `, DeliveryPending, limit, lease)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []DueDelivery
+	for rows.Next() {
+		var due DueDelivery
+		d, err := scanDelivery(rows, &due.URL, &due.Secret)
+		if err != nil {
+			return nil, err
+		}
+		due.WebhookDelivery = *d
+		out = append(out, due)
+	}
+	return out, rows.Err()
+}
+
+// RecordAttempt пишет попытку в журнал и переводит доставку в state; next —
+// время следующей попытки для PENDING. Попытка не записывается, если
+// доставку за это время переотправили вручную (attempts изменился).
+func (r *Repo) RecordAttempt(ctx context.Context, d *WebhookDelivery, a DeliveryAttempt, state string, next *time.Time) error {
+	return pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		tag, err := tx.Exec(ctx, `
+      UPDATE webhook_deliveries
+      SET attempts = attempts + 1,
+          state = $3,
+          next_attempt_at = $4,
+          last_status_code = $5,
+          last_error = NULLIF($6, ''),
+          delivered_at = CASE WHEN $3 = $7 THEN now() END
+      WHERE id = $1 AND attempts = $2 AND state = $8
+    `, d.ID, d.Attempts, state, next, a.StatusCode, a.Error, DeliveryDelivered, DeliveryPending)
+		if err != nil || tag.RowsAffected() == 0 {
+			return err
+		}
+		_, err = tx.Exec(ctx, `
+      INSERT INTO webhook_delivery_attempts (delivery_id, attempt, status_code, error, duration_ms)
+      VALUES ($1, $2, $3, NULLIF($4, ''), $5)
+    `, d.ID, d.Attempts+1, a.StatusCode, a.Error, a.Duration.Milliseconds())
+		return err
+	})
+}
+
+type DeliveryFilter struct {
+	SubscriptionID uuid.UUID
+	State          string
+	// After — последняя запись предыдущей страницы (по убыванию created_at, id)
+	After *Cursor
+	Limit int
+}
+
+// ListDeliveries — журнал доставок подписки от новых к старым
+func (r *Repo) ListDeliveries(ctx context.Context, f DeliveryFilter) ([]WebhookDelivery, error) {
+	args := []any{f.SubscriptionID}
+	arg := func(v any) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+	q := `
+    SELECT ` + deliveryColumns + `
+    FROM webhook_deliveries d
+    WHERE d.subscription_id = $1`
+	if f.State != "" {
+		q += " AND d.state = " + arg(f.State)
+	}
+	if f.After != nil {
+		q += fmt.Sprintf(" AND (d.created_at, d.id) < (%s, %s)", arg(f.After.CreatedAt), arg(f.After.ID))
+	}
+	q += "\n    ORDER BY d.created_at DESC, d.id DESC\n    LIMIT " + arg(f.Limit)
+
+	rows, err := r.db.Query(ctx, q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []WebhookDelivery
+	for rows.Next() {
+		d, err := scanDelivery(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, *d)
+	}
+	return out, rows.Err()
+}
+
+// GetDelivery возвращает доставку подписки и её попытки по порядку
+func (r *Repo) GetDelivery(ctx context.Context, subscriptionID, id uuid.UUID) (*WebhookDelivery, []DeliveryAttempt, error) {
+	row := r.db.QueryRow(ctx, `
+    SELECT `+deliveryColumns+`
+    FROM webhook_deliveries d
+    WHERE d.id = $1 AND d.subscription_id = $2
+  `, id, subscriptionID)
+	d, err := scanDelivery(row)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rows, err := r.db.Query(ctx, `
+    SELECT attempt, status_code, COALESCE(error, ''), duration_ms, attempted_at
+    FROM webhook_delivery_attempts
+    WHERE delivery_id = $1
+    ORDER BY id
+  `, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	var attempts []DeliveryAttempt
+	for rows.Next() {
+		var (
+			a  DeliveryAttempt
+			ms int64
+		)
+		if err := rows.Scan(&a.Attempt, &a.StatusCode, &a.Error, &ms, &a.AttemptedAt); err != nil {
+			return nil, nil, err
+		}
+		a.Duration = time.Duration(ms) * time.Millisecond
+		attempts = append(attempts, a)
+	}
+	return d, attempts, rows.Err()
+}
+
+// Redeliver возвращает доставку в очередь с немедленной попыткой и новым
+// расписанием повторов; журнал прежних попыток сохраняется
+func (r *Repo) Redeliver(ctx context.Context, subscriptionID, id uuid.UUID) (*WebhookDelivery, error) {
+	row := r.db.QueryRow(ctx, `
+    UPDATE webhook_deliveries d
+    SET state = $3, attempts = 0, next_attempt_at = now(), delivered_at = NULL
+    WHERE d.id = $1 AND d.subscription_id = $2
+    RETURNING `+deliveryColumns,
+		id, subscriptionID, DeliveryPending)
+	return scanDelivery(row)
+}
+
+// PurgeDeliveries удаляет доставленные раньше чем olderThan назад; DEAD
+// остаются до ручной переотправки или удаления подписки
+func (r *Repo) PurgeDeliveries(ctx context.Context, olderThan time.Duration) (int64, error) {
+	tag, err := r.db.Exec(ctx, `
+    DELETE FROM webhook_deliveries
+    WHERE state = $1 AND delivered_at < now() - $2::interval
+  `, DeliveryDelivered, olderThan)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
@@ -0,0 +1,371 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+
+	pb "transline.kz/api/proto/customerpb"
+	"transline.kz/internal/shipment/repo"
+)
+
+// BatchMode — как пакет реагирует на ошибки строк
+type BatchMode string
+
+const (
+	// BatchAllOrNothing — ошибка любой строки отменяет весь пакет
+	BatchAllOrNothing BatchMode = "ALL_OR_NOTHING"
+	// BatchBestEffort — создаются все строки, прошедшие проверки
+	BatchBestEffort BatchMode = "BEST_EFFORT"
+)
+
+// MaxBatchSize — предел строк в одном пакете
+const MaxBatchSize = 500
+
+// ParseBatchMode разбирает режим; пустая строка — ALL_OR_NOTHING
+func ParseBatchMode(s string) (BatchMode, error) {
+	switch m := BatchMode(strings.ToUpper(strings.TrimSpace(s))); m {
+	case "":
+		return BatchAllOrNothing, nil
+	case BatchAllOrNothing, BatchBestEffort:
+		return m, nil
+	}
+	return "", invalidQuery("mode", "must be %s or %s", BatchAllOrNothing, BatchBestEffort)
+}
+
+// BatchRowStatus — итог строки пакета
+type BatchRowStatus string
+
+const (
+	BatchRowCreated BatchRowStatus = "CREATED"
+	BatchRowFailed  BatchRowStatus = "FAILED"
+	// BatchRowSkipped — строка корректна, но пакет ALL_OR_NOTHING отменён из-за других строк
+	BatchRowSkipped BatchRowStatus = "SKIPPED"
+)
+
+type BatchInput struct {
+	Mode BatchMode
+	Rows []CreateShipmentInput
+	// Errors — ошибки разбора строк до сервиса (например, нечисловое поле CSV);
+	// строка с ошибкой сразу FAILED. nil или по элементу на строку.
+	Errors []error
+}
+
+// BatchRowResult — итог строки; Err заполнен для FAILED
+type BatchRowResult struct {
+	Index          int
+	Status         BatchRowStatus
+	ID             uuid.UUID
+	TrackingNumber string
+	CustomerID     uuid.UUID
+	Err            error
+}
+
+type BatchResult struct {
+	Mode    BatchMode
+	Created int
+	Failed  int
+	Rows    []BatchRowResult
+}
+
+// batchRow — строка пакета в процессе создания
+type batchRow struct {
+	result *BatchRowResult
+	saga   repo.Saga
+}
+
+func (r *batchRow) fail(err error) {
+	r.result.Status, r.result.Err = BatchRowFailed, err
+}
+
+func (r *batchRow) created(sh *repo.Shipment) {
+	r.result.Status, r.result.Err = BatchRowCreated, nil
+	r.result.ID, r.result.TrackingNumber, r.result.CustomerID = sh.ID, sh.TrackingNumber, sh.CustomerID
+}
+
+// CreateShipments создаёт пакет отправлений по правилам CreateShipment.
+// Каждая строка проверяется отдельно, ошибки возвращаются по строкам в
+// BatchResult; error — только если пакет не удалось обработать целиком
+// (например, недоступна БД в режиме ALL_OR_NOTHING). Клиент с одним ИИН/БИН
+// создаётся в customer-service один раз на пакет.
+func (s *Service) CreateShipments(ctx context.Context, in BatchInput) (*BatchResult, error) {
+	switch {
+	case len(in.Rows) == 0:
+		return nil, invalidField("rows", "must not be empty")
+	case len(in.Rows) > MaxBatchSize:
+		return nil, invalidField("rows", "too many rows (max %d)", MaxBatchSize)
+	}
+	if in.Mode == "" {
+		in.Mode = BatchAllOrNothing
+	}
+
+	res := &BatchResult{Mode: in.Mode, Rows: make([]BatchRowResult, len(in.Rows))}
+	rows := make([]*batchRow, len(in.Rows))
+	quotes := make(map[uuid.UUID]int)
+	for i, row := range in.Rows {
+		res.Rows[i] = BatchRowResult{Index: i, Status: BatchRowSkipped}
+		rows[i] = &batchRow{result: &res.Rows[i]}
+		if i < len(in.Errors) && in.Errors[i] != nil {
+			rows[i].fail(in.Errors[i])
+			continue
+		}
+		if row.QuoteID != nil {
+			if first, ok := quotes[*row.QuoteID]; ok {
+				rows[i].fail(invalidField("quoteId", "already used by row %d", first))
+				continue
+			}
+			quotes[*row.QuoteID] = i
+		}
+		sg, err := s.prepareShipment(ctx, row)
+		if err != nil && !errors.Is(err, ErrValidation) && in.Mode == BatchAllOrNothing {
+			return nil, err
+		}
+		if err != nil {
+			rows[i].fail(err)
+			continue
+		}
+		rows[i].saga = sg
+	}
+
+	var err error
+	if in.Mode == BatchAllOrNothing {
+		err = s.createAllOrNothing(ctx, rows)
+	} else {
+		s.createBestEffort(ctx, rows)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for _, r := range res.Rows {
+		switch r.Status {
+		case BatchRowCreated:
+			res.Created++
+		case BatchRowFailed:
+			res.Failed++
+		}
+	}
+	return res, nil
+}
+
+// createAllOrNothing создаёт все строки в одной транзакции или ни одной
+func (s *Service) createAllOrNothing(ctx context.Context, rows []*batchRow) error {
+	for _, r := range rows {
+		if r.result.Status == BatchRowFailed {
+			return nil
+		}
+	}
+
+	sagas := make([]repo.Saga, len(rows))
+	for i, r := range rows {
+		sagas[i] = r.saga
+	}
+	_, err := s.repo.StartSagas(ctx, sagas)
+	var berr *repo.BatchError
+	if errors.As(err, &berr) && errors.Is(err, repo.ErrQuoteUnavailable) {
+		rows[berr.Index].fail(invalidField("quoteId", "quote has expired or was already used"))
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to start shipment sagas: %w", storageError(err))
+	}
+	// Сбой после записи саг откатывает все строки
+	compensateAll := func(cause error) {
+		for _, r := range rows {
+			s.compensate(ctx, r.saga.ID, cause)
+		}
+	}
+
+	if err := s.upsertBatchCustomers(ctx, rows); err != nil {
+		compensateAll(err)
+		if errors.Is(err, ErrValidation) {
+			// данные клиента отклонены — это ошибка строк, а не пакета
+			return nil
+		}
+		return err
+	}
+
+	ids := make([]uuid.UUID, len(rows))
+	for i, r := range rows {
+		ids[i] = r.saga.ID
+	}
+	shipments, err := s.repo.CompleteSagas(ctx, ids)
+	if err != nil {
+		compensateAll(err)
+		return fmt.Errorf("failed to create shipments: %w", storageError(err))
+	}
+	for i, sh := range shipments {
+		rows[i].created(sh)
+		sagaStep(ctx, sh.ID, repo.SagaCompleted)
+	}
+	return nil
+}
+
+// createBestEffort создаёт каждую корректную строку независимо от остальных
+func (s *Service) createBestEffort(ctx context.Context, rows []*batchRow) {
+	var pending []*batchRow
+	for _, r := range rows {
+		if r.result.Status == BatchRowFailed {
+			continue
+		}
+		_, err := s.repo.StartSaga(ctx, r.saga)
+		switch {
+		case errors.Is(err, repo.ErrQuoteUnavailable):
+			r.fail(invalidField("quoteId", "quote has expired or was already used"))
+		case err != nil:
+			r.fail(fmt.Errorf("failed to start shipment saga: %w", storageError(err)))
+		default:
+			pending = append(pending, r)
+		}
+	}
+
+	// ошибки клиента уже записаны в строки
+	_ = s.upsertBatchCustomers(ctx, pending)
+
+	for _, r := range pending {
+		if r.result.Status == BatchRowFailed {
+			continue
+		}
+		sh, err := s.repo.CompleteSaga(ctx, r.saga.ID)
+		if err != nil {
+			r.fail(fmt.Errorf("failed to create shipment: %w", storageError(err)))
+			continue
+		}
+		r.created(sh)
+		sagaStep(ctx, sh.ID, repo.SagaCompleted)
+	}
+
+	// Компенсация — после всех строк: клиента, созданного строкой-владельцем,
+	// могут использовать успешные строки с тем же ИИН/БИН
+	used := make(map[string]bool)
+	for _, r := range pending {
+		if r.result.Status == BatchRowCreated {
+			used[r.saga.IDN] = true
+		}
+	}
+	for _, r := range pending {
+		if r.result.Status != BatchRowFailed {
+			continue
+		}
+		if used[r.saga.IDN] {
+			s.releaseSaga(ctx, r.saga.ID, r.result.Err)
+		} else {
+			s.compensate(ctx, r.saga.ID, r.result.Err)
+		}
+	}
+}
+
+// upsertBatchCustomers создаёт клиентов пакета одним BatchUpsertCustomers:
+// по элементу на ИИН/БИН с id саги первой строки как request_id. Шаг 1
+// записывается во все саги группы. Сбой помечает строки группы FAILED;
+// возвращается первая ошибка.
+func (s *Service) upsertBatchCustomers(ctx context.Context, rows []*batchRow) error {
+	var (
+		order  []string
+		groups = make(map[string][]*batchRow)
+	)
+	for _, r := range rows {
+		if _, ok := groups[r.saga.IDN]; !ok {
+			order = append(order, r.saga.IDN)
+		}
+		groups[r.saga.IDN] = append(groups[r.saga.IDN], r)
+	}
+	if len(order) == 0 {
+		return nil
+	}
+
+	var first error
+	failGroup := func(group []*batchRow, err error) {
+		for _, r := range group {
+			r.fail(err)
+		}
+		if first == nil {
+			first = err
+		}
+	}
+
+	items := make([]*pb.UpsertCustomerRequest, len(order))
+	for i, idn := range order {
+		items[i] = &pb.UpsertCustomerRequest{Idn: idn, RequestId: groups[idn][0].saga.ID.String()}
+	}
+	results, err := s.upsertCustomers(ctx, items)
+	if err != nil {
+		err = fmt.Errorf("failed to upsert customers: %w", customerError(err))
+		for _, idn := range order {
+			failGroup(groups[idn], err)
+		}
+		return first
+	}
+
+	for i, idn := range order {
+		group, res := groups[idn], results[i]
+		if len(res.Violations) > 0 {
+			v := newValidation()
+			for _, fv := range res.Violations {
+				v.add("customer."+fv.GetField(), "%s", fv.GetDescription())
+			}
+			failGroup(group, fmt.Errorf("failed to upsert customer: %w", v))
+			continue
+		}
+		cus := res.GetCustomer()
+		customerID, err := uuid.Parse(cus.GetId())
+		if err != nil {
+			failGroup(group, fmt.Errorf("invalid customer id format: %w", err))
+			continue
+		}
+
+		owner := group[0].saga.ID
+		for _, r := range group {
+			// остальные строки группы клиента не создавали — компенсировать его должен владелец
+			created := cus.Created && r.saga.ID == owner
+			if err := s.repo.SagaCustomerUpserted(ctx, r.saga.ID, customerID, created); err != nil {
+				err = fmt.Errorf("failed to record saga step: %w", storageError(err))
+				r.fail(err)
+				if first == nil {
+					first = err
+				}
+				continue
+			}
+			sagaStep(ctx, r.saga.ID, repo.SagaCustomerUpserted, attribute.Bool("customer.created", created))
+		}
+	}
+	return first
+}
+
+// upsertCustomers — BatchUpsertCustomers с повторами; таймаут растёт с
+// размером пакета
+func (s *Service) upsertCustomers(ctx context.Context, items []*pb.UpsertCustomerRequest) ([]*pb.UpsertCustomerResult, error) {
+	timeout := customerCallTimeout * time.Duration(1+len(items)/100)
+	var results []*pb.UpsertCustomerResult
+	err := callWithRetry(ctx, timeout, func(ctx context.Context) error {
+		var err error
+		results, err = s.customerGRPC.BatchUpsertCustomers(ctx, items)
+		return err
+	})
+	return results, err
+}
+
+// releaseSaga закрывает сагу без удаления клиента: его создала эта сага,
+// но используют отправления других строк пакета
+func (s *Service) releaseSaga(ctx context.Context, id uuid.UUID, cause error) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), compensationTimeout)
+	defer cancel()
+
+	if _, err := s.sagas.BeginSagaCompensation(ctx, id, cause.Error()); err != nil {
+		if !errors.Is(err, repo.ErrSagaConflict) {
+			slog.Error("failed to begin saga compensation", "saga_id", id, "err", err, "cause", cause)
+		}
+		return
+	}
+	if err := s.sagas.FinishSagaCompensation(ctx, id); err != nil && !errors.Is(err, repo.ErrSagaConflict) {
+		slog.Error("failed to finish saga compensation", "saga_id", id, "err", err, "cause", cause)
+		return
+	}
+	sagaStep(ctx, id, repo.SagaCompensated, attribute.Bool("customer.kept", true))
+}
@@ -0,0 +1,177 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"transline.kz/internal/money"
+	"transline.kz/internal/shipment/repo"
+)
+
+// CancelReason — причина отмены; совпадает с CHECK в shipments.cancel_reason
+type CancelReason string
+
+const (
+	CancelCustomerRequest    CancelReason = "CUSTOMER_REQUEST"
+	CancelDuplicate          CancelReason = "DUPLICATE"
+	CancelIncorrectDetails   CancelReason = "INCORRECT_DETAILS"
+	CancelCargoNotReady      CancelReason = "CARGO_NOT_READY"
+	CancelCarrierUnavailable CancelReason = "CARRIER_UNAVAILABLE"
+	// CancelOther требует пояснения в Note
+	CancelOther CancelReason = "OTHER"
+)
+
+var cancelReasons = []CancelReason{
+	CancelCustomerRequest,
+	CancelDuplicate,
+	CancelIncorrectDetails,
+	CancelCargoNotReady,
+	CancelCarrierUnavailable,
+	CancelOther,
+}
+
+func ParseCancelReason(s string) (CancelReason, bool) {
+	r := CancelReason(strings.ToUpper(strings.TrimSpace(s)))
+	for _, known := range cancelReasons {
+		if r == known {
+			return r, true
+		}
+	}
+	return "", false
+}
+
+// DefaultCancelGracePeriod — сколько после создания неподтверждённое
+// отправление отменяется бесплатно
+const DefaultCancelGracePeriod = 15 * time.Minute
+
+// Сбор за отмену — процент от цены перевозки; растёт с продвижением
+// отправления: после подтверждения под него уже выделен транспорт
+var (
+	cancelFeeCreated   = big.NewRat(5, 100)
+	cancelFeeConfirmed = big.NewRat(15, 100)
+)
+
+// FeeRule — по какому правилу начислен сбор
+type FeeRule string
+
+const (
+	FeeGracePeriod  FeeRule = "GRACE_PERIOD"
+	FeeCarrierFault FeeRule = "CARRIER_FAULT"
+	FeeUnconfirmed  FeeRule = "UNCONFIRMED"
+	FeeConfirmed    FeeRule = "CONFIRMED"
+)
+
+// Cancellation — причина и сбор отмены
+type Cancellation struct {
+	Reason      CancelReason
+	Note        string
+	Fee         money.Money
+	CancelledAt time.Time
+}
+
+type CancelInput struct {
+	ShipmentID uuid.UUID
+	Reason     string
+	// Note — пояснение; обязательно для причины OTHER
+	Note  string
+	Actor string
+}
+
+// Cancel отменяет отправление до забора груза (CREATED, CONFIRMED),
+// начисляет сбор по cancellationFee и пишет событие смены статуса
+func (s *Service) Cancel(ctx context.Context, in CancelInput) (*Shipment, error) {
+	v := newValidation()
+	reason, ok := ParseCancelReason(in.Reason)
+	switch {
+	case strings.TrimSpace(in.Reason) == "":
+		v.add("reason", "is required")
+	case !ok:
+		v.add("reason", "unknown reason %q (allowed: %v)", in.Reason, cancelReasons)
+	}
+	note := strings.TrimSpace(in.Note)
+	if reason == CancelOther && note == "" {
+		v.add("note", "is required when reason is OTHER")
+	}
+	if len(note) > 2000 {
+		v.add("note", "too long (max 2000 chars)")
+	}
+	if len(in.Actor) > 255 {
+		v.add("actor", "too long (max 255 chars)")
+	}
+	if err := v.err(); err != nil {
+		return nil, err
+	}
+
+	current, err := s.repo.Get(ctx, in.ShipmentID)
+	if errors.Is(err, repo.ErrNotFound) {
+		return nil, ErrShipmentNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load shipment: %w", storageError(err))
+	}
+	from := Status(current.Status)
+	if err := checkTransition(from, StatusCancelled); err != nil {
+		return nil, err
+	}
+
+	fee, rule, err := s.cancellationFee(current, from, reason, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute cancellation fee: %w", err)
+	}
+
+	eventNote := fmt.Sprintf("reason %s, fee %s (%s)", reason, fee, rule)
+	if note != "" {
+		eventNote += ": " + note
+	}
+	sh, err := s.repo.Cancel(ctx, in.ShipmentID,
+		repo.Cancellation{Reason: string(reason), Note: note, Fee: fee},
+		repo.Event{
+			StatusFrom: string(from),
+			StatusTo:   string(StatusCancelled),
+			Note:       eventNote,
+			Actor:      actorOrDefault(in.Actor),
+		})
+	if errors.Is(err, repo.ErrStatusConflict) {
+		return nil, ErrStatusConflict
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to cancel shipment: %w", storageError(err))
+	}
+	return s.toShipment(sh)
+}
+
+// cancellationFee — сбор за отмену: бесплатно по вине перевозчика и в
+// течение CancelGracePeriod после создания, иначе процент от цены по статусу
+func (s *Service) cancellationFee(sh *repo.Shipment, from Status, reason CancelReason, now time.Time) (money.Money, FeeRule, error) {
+	free := money.MustParse("0", sh.Price.Currency())
+	switch {
+	case reason == CancelCarrierUnavailable:
+		return free, FeeCarrierFault, nil
+	case from == StatusCreated && now.Sub(sh.CreatedAt) <= s.cfg.CancelGracePeriod:
+		return free, FeeGracePeriod, nil
+	case from == StatusCreated:
+		fee, err := sh.Price.MulRat(cancelFeeCreated)
+		return fee, FeeUnconfirmed, err
+	default:
+		fee, err := sh.Price.MulRat(cancelFeeConfirmed)
+		return fee, FeeConfirmed, err
+	}
+}
+
+func toCancellation(c *repo.Cancellation) *Cancellation {
+	if c == nil {
+		return nil
+	}
+	return &Cancellation{
+		Reason:      CancelReason(c.Reason),
+		Note:        c.Note,
+		Fee:         c.Fee,
+		CancelledAt: c.CancelledAt,
+	}
+}
@@ -0,0 +1,301 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"transline.kz/internal/measure"
+	"transline.kz/internal/money"
+	"transline.kz/internal/shipment/repo"
+)
+
+// PackagingType — вид упаковки; совпадает с CHECK в shipment_items.packaging
+type PackagingType string
+
+const (
+	PackagingBox    PackagingType = "BOX"
+	PackagingPallet PackagingType = "PALLET"
+	PackagingCrate  PackagingType = "CRATE"
+	PackagingBag    PackagingType = "BAG"
+	PackagingDrum   PackagingType = "DRUM"
+	PackagingRoll   PackagingType = "ROLL"
+	PackagingLoose  PackagingType = "LOOSE"
+	PackagingOther  PackagingType = "OTHER"
+)
+
+const DefaultPackaging = PackagingBox
+
+func ParsePackaging(s string) (PackagingType, bool) {
+	switch p := PackagingType(strings.ToUpper(strings.TrimSpace(s))); p {
+	case PackagingBox, PackagingPallet, PackagingCrate, PackagingBag,
+		PackagingDrum, PackagingRoll, PackagingLoose, PackagingOther:
+		return p, true
+	}
+	return "", false
+}
+
+// Ограничения грузовых мест; габариты одного места — по внутренним размерам полуприцепа
+const (
+	MaxItems       = 100
+	MaxItemQty     = 10_000
+	MaxItemLength  = 13_600 * measure.Millimetre
+	MaxItemWidth   = 2_480 * measure.Millimetre
+	MaxItemHeight  = 2_700 * measure.Millimetre
+	maxDescription = 255
+)
+
+// VolumetricKgPerM3 — плотность для объёмного веса груза (стандарт автоперевозок);
+// при тарификации используется коэффициент тарифа
+const VolumetricKgPerM3 = 250
+
+// ItemInput — грузовое место в запросе; числа — десятичные строки
+type ItemInput struct {
+	Description string
+	Quantity    int
+	// Weight — вес брутто одного места, кг
+	Weight string
+	// Length/Width/Height — габариты одного места, см; задаются все три или ни одного
+	Length string
+	Width  string
+	Height string
+	// DeclaredValue — объявленная ценность всей строки в валюте отправления
+	DeclaredValue string
+	Packaging     string
+	HSCode        string
+}
+
+type Dimensions struct {
+	Length measure.Length
+	Width  measure.Length
+	Height measure.Length
+}
+
+// Item — грузовое место; Weight и Dimensions — на одно место
+type Item struct {
+	Description   string
+	Quantity      int
+	Weight        measure.Weight
+	Dimensions    *Dimensions
+	DeclaredValue *money.Money
+	Packaging     PackagingType
+	HSCode        string
+}
+
+// Volume — объём одного места; 0, если габариты не указаны
+func (it Item) Volume() measure.Volume {
+	if it.Dimensions == nil {
+		return 0
+	}
+	return measure.BoxVolume(it.Dimensions.Length, it.Dimensions.Width, it.Dimensions.Height)
+}
+
+// Cargo — грузовые места отправления с итогами
+type Cargo struct {
+	Items         []Item
+	TotalQuantity int
+	TotalWeight   measure.Weight
+	TotalVolume   measure.Volume
+	// VolumetricWeight — объёмный вес по VolumetricKgPerM3; ChargeableWeight —
+	// большее из фактического и объёмного
+	VolumetricWeight measure.Weight
+	ChargeableWeight measure.Weight
+	// DeclaredValue — сумма объявленных ценностей; nil, если ни у одного места её нет
+	DeclaredValue *money.Money
+}
+
+func newCargo(items []Item) Cargo {
+	c := Cargo{Items: items}
+	for _, it := range items {
+		c.TotalQuantity += it.Quantity
+		c.TotalWeight += it.Weight * measure.Weight(it.Quantity)
+		c.TotalVolume += it.Volume() * measure.Volume(it.Quantity)
+		if it.DeclaredValue != nil {
+			if c.DeclaredValue == nil {
+				c.DeclaredValue = it.DeclaredValue
+			} else if sum, err := c.DeclaredValue.Add(*it.DeclaredValue); err == nil {
+				c.DeclaredValue = &sum
+			}
+		}
+	}
+	c.VolumetricWeight = c.TotalVolume.VolumetricWeight(VolumetricKgPerM3)
+	c.ChargeableWeight = max(c.TotalWeight, c.VolumetricWeight)
+	return c
+}
+
+// parseCargo разбирает и проверяет грузовые места; ценность — в валюте currency
+func parseCargo(v *ValidationError, in []ItemInput, currency money.Currency) Cargo {
+	if len(in) > MaxItems {
+		v.add("items", "too many items (max %d)", MaxItems)
+		return Cargo{}
+	}
+	items := make([]Item, 0, len(in))
+	for i, ii := range in {
+		items = append(items, parseItem(v, fmt.Sprintf("items[%d]", i), ii, currency))
+	}
+	c := newCargo(items)
+	if c.TotalWeight > MaxWeight {
+		v.add("items", "total weight %s kg exceeds %s kg", c.TotalWeight.Kg(), MaxWeight.Kg())
+	}
+	if c.TotalVolume > MaxVolume {
+		v.add("items", "total volume %s m3 exceeds %s m3", c.TotalVolume.M3(), MaxVolume.M3())
+	}
+	return c
+}
+
+func parseItem(v *ValidationError, field string, in ItemInput, currency money.Currency) Item {
+	it := Item{
+		Description: strings.TrimSpace(in.Description),
+		Quantity:    in.Quantity,
+		Packaging:   DefaultPackaging,
+	}
+
+	switch {
+	case it.Description == "":
+		v.add(field+".description", "is required")
+	case len(it.Description) > maxDescription:
+		v.add(field+".description", "too long (max %d chars)", maxDescription)
+	}
+	if it.Quantity < 1 || it.Quantity > MaxItemQty {
+		v.add(field+".quantity", "must be between 1 and %d", MaxItemQty)
+	}
+
+	w, err := measure.ParseKg(in.Weight)
+	switch {
+	case in.Weight == "":
+		v.add(field+".weightKg", "is required")
+	case err != nil:
+		v.add(field+".weightKg", "%v", err)
+	case w <= 0:
+		v.add(field+".weightKg", "must be positive")
+	case w > MaxWeight:
+		v.add(field+".weightKg", "too high (max %s kg)", MaxWeight.Kg())
+	default:
+		it.Weight = w
+	}
+
+	if in.Length != "" || in.Width != "" || in.Height != "" {
+		d := Dimensions{
+			Length: parseDimension(v, field+".lengthCm", in.Length, MaxItemLength),
+			Width:  parseDimension(v, field+".widthCm", in.Width, MaxItemWidth),
+			Height: parseDimension(v, field+".heightCm", in.Height, MaxItemHeight),
+		}
+		if d.Length > 0 && d.Width > 0 && d.Height > 0 {
+			it.Dimensions = &d
+		}
+	}
+
+	if in.DeclaredValue != "" {
+		m, err := money.Parse(in.DeclaredValue, currency)
+		switch {
+		case err != nil:
+			v.add(field+".declaredValue", "%v", err)
+		case m.IsNegative():
+			v.add(field+".declaredValue", "must not be negative")
+		default:
+			if c, _ := m.Cmp(money.MustParse(maxPrice, currency)); c > 0 {
+				v.add(field+".declaredValue", "too high (max %s)", maxPrice)
+			} else {
+				it.DeclaredValue = &m
+			}
+		}
+	}
+
+	if in.Packaging != "" {
+		p, ok := ParsePackaging(in.Packaging)
+		if !ok {
+			v.add(field+".packaging", "unknown packaging %q", in.Packaging)
+		}
+		it.Packaging = p
+	}
+
+	// HS-код пишут и с точками/пробелами: "8471.30 00"
+	it.HSCode = strings.Map(func(r rune) rune {
+		if r == '.' || unicode.IsSpace(r) {
+			return -1
+		}
+		return r
+	}, in.HSCode)
+	if it.HSCode != "" && !validHSCode(it.HSCode) {
+		v.add(field+".hsCode", "must be 6 to 10 digits")
+	}
+	return it
+}
+
+func parseDimension(v *ValidationError, field, s string, limit measure.Length) measure.Length {
+	if s == "" {
+		v.add(field, "is required when other dimensions are set")
+		return 0
+	}
+	l, err := measure.ParseCm(s)
+	switch {
+	case err != nil:
+		v.add(field, "%v", err)
+	case l <= 0:
+		v.add(field, "must be positive")
+	case l > limit:
+		v.add(field, "too large (max %s cm)", limit.Cm())
+	default:
+		return l
+	}
+	return 0
+}
+
+func validHSCode(s string) bool {
+	if len(s) < 6 || len(s) > 10 {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func (c Cargo) toRepo() []repo.Item {
+	out := make([]repo.Item, len(c.Items))
+	for i, it := range c.Items {
+		out[i] = repo.Item{
+			Seq:         i,
+			Description: it.Description,
+			Quantity:    it.Quantity,
+			Weight:      it.Weight,
+			Packaging:   string(it.Packaging),
+			HSCode:      it.HSCode,
+		}
+		if d := it.Dimensions; d != nil {
+			out[i].Length, out[i].Width, out[i].Height = d.Length, d.Width, d.Height
+		}
+		if it.DeclaredValue != nil {
+			out[i].DeclaredValue = it.DeclaredValue.Amount()
+		}
+	}
+	return out
+}
+
+// toCargo собирает места из БД; ценность — в валюте отправления
+func toCargo(items []repo.Item, currency money.Currency) (Cargo, error) {
+	out := make([]Item, 0, len(items))
+	for _, ri := range items {
+		it := Item{
+			Description: ri.Description,
+			Quantity:    ri.Quantity,
+			Weight:      ri.Weight,
+			Packaging:   PackagingType(ri.Packaging),
+			HSCode:      ri.HSCode,
+		}
+		if ri.Length > 0 {
+			it.Dimensions = &Dimensions{Length: ri.Length, Width: ri.Width, Height: ri.Height}
+		}
+		if ri.DeclaredValue != "" {
+			m, err := money.Parse(ri.DeclaredValue, currency)
+			if err != nil {
+				return Cargo{}, fmt.Errorf("item %d declared value: %w", ri.Seq, err)
+			}
+			it.DeclaredValue = &m
+		}
+		out = append(out, it)
+	}
+	return newCargo(out), nil
+}
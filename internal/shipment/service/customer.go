@@ -0,0 +1,43 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ErrCustomerServiceUnavailable — customer-service или его БД недоступны
+var ErrCustomerServiceUnavailable = errors.New("customer service unavailable")
+
+// customerError отделяет ошибки данных клиента (ValidationError по полям
+// customer.*) от недоступности customer-service
+func customerError(err error) error {
+	st, ok := status.FromError(err)
+	if !ok {
+		return err
+	}
+
+	switch st.Code() {
+	case codes.InvalidArgument:
+		v := newValidation()
+		for _, d := range st.Details() {
+			br, ok := d.(*errdetails.BadRequest)
+			if !ok {
+				continue
+			}
+			for _, fv := range br.GetFieldViolations() {
+				v.add("customer."+fv.GetField(), "%s", fv.GetDescription())
+			}
+		}
+		if len(v.Fields) == 0 {
+			v.add("customer", "%s", st.Message())
+		}
+		return v
+	case codes.Unavailable, codes.DeadlineExceeded, codes.ResourceExhausted:
+		return fmt.Errorf("%w: %w", ErrCustomerServiceUnavailable, err)
+	}
+	return err
+}
@@ -0,0 +1,162 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"transline.kz/internal/shipment/document"
+	"transline.kz/internal/shipment/repo"
+	"transline.kz/internal/tracking"
+)
+
+// Document — готовый PDF печатной формы
+type Document struct {
+	TrackingNumber string
+	Format         document.Format
+	PDF            []byte
+}
+
+// ShipmentLabel — транспортная этикетка; по умолчанию термоформат 100×150
+func (s *Service) ShipmentLabel(ctx context.Context, id uuid.UUID, format string) (*Document, error) {
+	f, err := parseDocumentFormat(format, document.FormatThermal)
+	if err != nil {
+		return nil, err
+	}
+	d, _, err := s.documentData(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	pdf, err := document.Label(d, f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render label: %w", err)
+	}
+	return &Document{TrackingNumber: d.TrackingNumber, Format: f, PDF: pdf}, nil
+}
+
+// ShipmentWaybill — транспортная накладная; по умолчанию A4. Реквизиты
+// плательщика берутся из customer-service; без них накладная не печатается:
+// если он недоступен — ErrCustomerServiceUnavailable.
+func (s *Service) ShipmentWaybill(ctx context.Context, id uuid.UUID, format string) (*Document, error) {
+	f, err := parseDocumentFormat(format, document.FormatA4)
+	if err != nil {
+		return nil, err
+	}
+	d, customerID, err := s.documentData(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	grpcCtx, cancel := context.WithTimeout(ctx, customerCallTimeout)
+	defer cancel()
+	cus, err := s.customerGRPC.GetCustomerById(grpcCtx, customerID.String())
+	if err != nil {
+		return nil, fmt.Errorf("failed to load payer: %w", customerError(err))
+	}
+	d.Customer = document.Customer{
+		IDN:     cus.Idn,
+		Name:    cus.Name,
+		Phone:   cus.Phone,
+		Address: cus.LegalAddress,
+	}
+
+	pdf, err := document.Waybill(d, f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render waybill: %w", err)
+	}
+	return &Document{TrackingNumber: d.TrackingNumber, Format: f, PDF: pdf}, nil
+}
+
+func parseDocumentFormat(s string, def document.Format) (document.Format, error) {
+	if s == "" {
+		return def, nil
+	}
+	f, err := document.ParseFormat(s)
+	if err != nil {
+		return "", invalidQuery("format", "must be A4 or THERMAL_100X150")
+	}
+	return f, nil
+}
+
+// documentData собирает данные печатной формы: точки маршрута с адресами,
+// места и итоги груза. Возвращает также ID клиента для реквизитов плательщика.
+func (s *Service) documentData(ctx context.Context, id uuid.UUID) (document.Shipment, uuid.UUID, error) {
+	rsh, err := s.repo.Get(ctx, id)
+	if errors.Is(err, repo.ErrNotFound) {
+		return document.Shipment{}, uuid.Nil, ErrShipmentNotFound
+	}
+	if err != nil {
+		return document.Shipment{}, uuid.Nil, fmt.Errorf("failed to load shipment: %w", storageError(err))
+	}
+	sh, err := s.toShipment(rsh)
+	if err != nil {
+		return document.Shipment{}, uuid.Nil, err
+	}
+
+	d := document.Shipment{
+		TrackingNumber: sh.TrackingNumber,
+		ID:             sh.ID.String(),
+		CreatedAt:      sh.CreatedAt.In(tracking.Zone),
+		Origin:         document.Party{City: s.cityName(rsh.OriginCity)},
+		Destination:    document.Party{City: s.cityName(rsh.DestinationCity)},
+		Price:          sh.Price.Amount(),
+		Currency:       string(sh.Price.Currency()),
+		TrackURL:       s.trackURL(sh.TrackingNumber),
+	}
+	if sh.Route.Origin.CityCode != "" {
+		d.Origin = s.documentParty(sh.Route.Origin)
+		d.Destination = s.documentParty(sh.Route.Destination)
+		for _, w := range sh.Route.Waypoints {
+			d.Waypoints = append(d.Waypoints, s.cityName(w.CityCode))
+		}
+	}
+
+	c := sh.Cargo
+	for _, it := range c.Items {
+		di := document.Item{
+			Description: it.Description,
+			Packaging:   string(it.Packaging),
+			Quantity:    it.Quantity,
+			WeightKg:    it.Weight.Kg(),
+			HSCode:      it.HSCode,
+		}
+		if it.Dimensions != nil {
+			di.DimensionsCm = it.Dimensions.Length.Cm() + " x " + it.Dimensions.Width.Cm() + " x " + it.Dimensions.Height.Cm()
+			di.VolumeM3 = it.Volume().M3()
+		}
+		if it.DeclaredValue != nil {
+			di.DeclaredValue = it.DeclaredValue.Amount()
+		}
+		d.Items = append(d.Items, di)
+	}
+	if len(c.Items) > 0 {
+		d.Pieces = c.TotalQuantity
+		d.WeightKg = c.TotalWeight.Kg()
+		d.VolumeM3 = c.TotalVolume.M3()
+		d.ChargeableKg = c.ChargeableWeight.Kg()
+	}
+	if c.DeclaredValue != nil {
+		d.DeclaredValue = c.DeclaredValue.Amount()
+	}
+	return d, sh.CustomerID, nil
+}
+
+func (s *Service) documentParty(st Stop) document.Party {
+	return document.Party{
+		City:    s.cityName(st.CityCode),
+		Address: st.Address,
+		Contact: st.Contact.Name,
+		Phone:   st.Contact.Phone,
+	}
+}
+
+// trackURL — содержимое QR-кода: ссылка на публичное отслеживание или,
+// если адрес не настроен, сам трек-номер
+func (s *Service) trackURL(number string) string {
+	if s.cfg.TrackingURL == "" {
+		return number
+	}
+	return s.cfg.TrackingURL + number
+}
@@ -0,0 +1,86 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"transline.kz/internal/pgerr"
+)
+
+var (
+	// ErrValidation — входные данные нарушают бизнес-правила
+	ErrValidation = errors.New("validation failed")
+	// ErrUnavailable — БД shipment-service временно недоступна
+	ErrUnavailable = errors.New("storage unavailable")
+)
+
+// FieldError — нарушение правила для конкретного поля запроса
+type FieldError struct {
+	Field   string
+	Message string
+}
+
+// ValidationError — ErrValidation (или ErrInvalidQuery) с перечнем нарушений по полям
+type ValidationError struct {
+	kind   error
+	Fields []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	parts := make([]string, 0, len(e.Fields))
+	for _, f := range e.Fields {
+		parts = append(parts, f.Field+": "+f.Message)
+	}
+	return e.kind.Error() + ": " + strings.Join(parts, "; ")
+}
+
+func (e *ValidationError) Is(target error) bool {
+	return target == e.kind
+}
+
+func (e *ValidationError) add(field, format string, args ...any) {
+	e.Fields = append(e.Fields, FieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+}
+
+// has сообщает, есть ли уже нарушение для поля
+func (e *ValidationError) has(field string) bool {
+	for _, f := range e.Fields {
+		if f.Field == field {
+			return true
+		}
+	}
+	return false
+}
+
+// err возвращает nil, если нарушений нет
+func (e *ValidationError) err() error {
+	if len(e.Fields) == 0 {
+		return nil
+	}
+	return e
+}
+
+func newValidation() *ValidationError {
+	return &ValidationError{kind: ErrValidation}
+}
+
+func invalidField(field, format string, args ...any) error {
+	v := newValidation()
+	v.add(field, format, args...)
+	return v
+}
+
+func invalidQuery(param, format string, args ...any) error {
+	v := &ValidationError{kind: ErrInvalidQuery}
+	v.add(param, format, args...)
+	return v
+}
+
+// storageError помечает ошибки соединения с БД как ErrUnavailable
+func storageError(err error) error {
+	if pgerr.Transient(err) {
+		return fmt.Errorf("%w: %w", ErrUnavailable, err)
+	}
+	return err
+}
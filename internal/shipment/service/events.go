@@ -0,0 +1,177 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"transline.kz/internal/shipment/repo"
+)
+
+// EventType — тип события в хронологии отправления
+type EventType string
+
+const (
+	EventStatusChanged EventType = repo.EventStatusChanged
+	EventLocationScan  EventType = repo.EventLocationScan
+	EventNote          EventType = repo.EventNote
+)
+
+// DefaultActor используется, если клиент не указал, кто совершил действие
+const DefaultActor = "anonymous"
+
+type Coordinates struct {
+	Latitude  float64
+	Longitude float64
+}
+
+// Event — запись хронологии: смена статуса, скан на точке или заметка
+type Event struct {
+	ID          int64
+	ShipmentID  uuid.UUID
+	Type        EventType
+	StatusFrom  Status
+	StatusTo    Status
+	Location    string
+	Coordinates *Coordinates
+	Note        string
+	Actor       string
+	OccurredAt  time.Time
+}
+
+// EventDetails — общие для событий поля, которые передаёт клиент
+type EventDetails struct {
+	Actor       string
+	Location    string
+	Coordinates *Coordinates
+	Note        string
+}
+
+type AddEventInput struct {
+	ShipmentID uuid.UUID
+	Type       EventType
+	EventDetails
+}
+
+func (d EventDetails) validate(v *ValidationError) {
+	if len(d.Actor) > 255 {
+		v.add("actor", "too long (max 255 chars)")
+	}
+	if len(d.Location) > 255 {
+		v.add("location", "too long (max 255 chars)")
+	}
+	if len(d.Note) > 2000 {
+		v.add("note", "too long (max 2000 chars)")
+	}
+	validateCoordinates(v, "coordinates", d.Coordinates)
+}
+
+func validateCoordinates(v *ValidationError, field string, c *Coordinates) {
+	if c == nil {
+		return
+	}
+	if c.Latitude < -90 || c.Latitude > 90 {
+		v.add(field+".lat", "must be between -90 and 90")
+	}
+	if c.Longitude < -180 || c.Longitude > 180 {
+		v.add(field+".lon", "must be between -180 and 180")
+	}
+}
+
+func (d EventDetails) toRepo() repo.Event {
+	e := repo.Event{
+		Location: strings.TrimSpace(d.Location),
+		Note:     strings.TrimSpace(d.Note),
+		Actor:    actorOrDefault(d.Actor),
+	}
+	if d.Coordinates != nil {
+		e.Latitude = &d.Coordinates.Latitude
+		e.Longitude = &d.Coordinates.Longitude
+	}
+	return e
+}
+
+func actorOrDefault(actor string) string {
+	if actor = strings.TrimSpace(actor); actor != "" {
+		return actor
+	}
+	return DefaultActor
+}
+
+func toEvent(e *repo.Event) *Event {
+	out := &Event{
+		ID:         e.ID,
+		ShipmentID: e.ShipmentID,
+		Type:       EventType(e.Type),
+		StatusFrom: Status(e.StatusFrom),
+		StatusTo:   Status(e.StatusTo),
+		Location:   e.Location,
+		Note:       e.Note,
+		Actor:      e.Actor,
+		OccurredAt: e.OccurredAt,
+	}
+	if e.Latitude != nil && e.Longitude != nil {
+		out.Coordinates = &Coordinates{Latitude: *e.Latitude, Longitude: *e.Longitude}
+	}
+	return out
+}
+
+// AddEvent записывает скан или заметку; смена статуса идёт только через Transition
+func (s *Service) AddEvent(ctx context.Context, in AddEventInput) (*Event, error) {
+	v := newValidation()
+	switch in.Type {
+	case EventLocationScan:
+		if strings.TrimSpace(in.Location) == "" && in.Coordinates == nil {
+			v.add("location", "location scan requires location or coordinates")
+		}
+	case EventNote:
+		if strings.TrimSpace(in.Note) == "" {
+			v.add("note", "is required")
+		}
+	case EventStatusChanged:
+		v.add("type", "use transitions to change status")
+	default:
+		v.add("type", "unknown event type %q", in.Type)
+	}
+	in.validate(v)
+	if err := v.err(); err != nil {
+		return nil, err
+	}
+
+	ev := in.toRepo()
+	ev.ShipmentID = in.ShipmentID
+	ev.Type = string(in.Type)
+
+	e, err := s.repo.AddEvent(ctx, ev)
+	if errors.Is(err, repo.ErrNotFound) {
+		return nil, ErrShipmentNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to add shipment event: %w", storageError(err))
+	}
+	return toEvent(e), nil
+}
+
+// ListEvents возвращает хронологию отправления от старых событий к новым
+func (s *Service) ListEvents(ctx context.Context, shipmentID uuid.UUID) ([]*Event, error) {
+	if _, err := s.repo.Get(ctx, shipmentID); err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			return nil, ErrShipmentNotFound
+		}
+		return nil, fmt.Errorf("failed to load shipment: %w", storageError(err))
+	}
+
+	rows, err := s.repo.ListEvents(ctx, shipmentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list shipment events: %w", storageError(err))
+	}
+
+	out := make([]*Event, 0, len(rows))
+	for i := range rows {
+		out = append(out, toEvent(&rows[i]))
+	}
+	return out, nil
+}
@@ -0,0 +1,66 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"transline.kz/internal/measure"
+	"transline.kz/internal/shipment/repo"
+)
+
+// ExportRow — отправление в выгрузке. Точки и места не загружаются: Route
+// пустой, Cargo содержит только итоги Pieces и Weight.
+type ExportRow struct {
+	*Shipment
+	OriginCity      string
+	DestinationCity string
+	Pieces          int
+	Weight          measure.Weight
+}
+
+// errExportWrite — ошибку вернул fn, а не хранилище
+type errExportWrite struct{ err error }
+
+func (e errExportWrite) Error() string { return e.err.Error() }
+func (e errExportWrite) Unwrap() error { return e.err }
+
+// ExportShipments передаёт fn все отправления по фильтрам листинга в
+// порядке Sort, читая их из БД потоком. Limit и Cursor не применяются.
+// Ошибка fn возвращается как есть.
+func (s *Service) ExportShipments(ctx context.Context, in ListShipmentsInput, fn func(*ExportRow) error) error {
+	in.Limit, in.Cursor = 0, ""
+	f, err := s.listFilter(ctx, in)
+	if errors.Is(err, errCustomerUnknown) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	err = s.repo.Export(ctx, f, func(row *repo.ExportRow) error {
+		sh, err := s.toShipment(row.Shipment)
+		if err != nil {
+			return err
+		}
+		err = fn(&ExportRow{
+			Shipment:        sh,
+			OriginCity:      row.OriginCity,
+			DestinationCity: row.DestinationCity,
+			Pieces:          row.Pieces,
+			Weight:          row.Weight,
+		})
+		if err != nil {
+			return errExportWrite{err}
+		}
+		return nil
+	})
+	var werr errExportWrite
+	if errors.As(err, &werr) {
+		return werr.err
+	}
+	if err != nil {
+		return fmt.Errorf("failed to export shipments: %w", storageError(err))
+	}
+	return nil
+}
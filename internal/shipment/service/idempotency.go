@@ -0,0 +1,89 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// IdempotentHandlerTimeout — сколько может выполняться запрос с
+	// Idempotency-Key (включая пакеты до 500 строк); по истечении его контекст отменяется
+	IdempotentHandlerTimeout = 2 * time.Minute
+	// idempotencyLockTimeout — через сколько незавершённый ключ можно перехватить
+	// (например, если инстанс упал посреди обработки). С запасом больше
+	// IdempotentHandlerTimeout, чтобы повтор не выполнил ещё идущий запрос второй раз.
+	idempotencyLockTimeout = 5 * time.Minute
+)
+
+var (
+	ErrIdempotencyKeyReused   = errors.New("idempotency key was already used with a different request")
+	ErrIdempotencyKeyInFlight = errors.New("request with this idempotency key is still being processed")
+)
+
+// StoredResponse — ответ, сохранённый для повторов с тем же Idempotency-Key
+type StoredResponse struct {
+	StatusCode  int
+	ContentType string
+	Body        []byte
+}
+
+// IdempotencyClaim — ключ, занятый запросом. Сохранить ответ или освободить
+// ключ может только владелец заявки.
+type IdempotencyClaim struct {
+	scope, key string
+	token      uuid.UUID
+}
+
+// BeginIdempotent занимает ключ для запроса с хешем requestHash.
+// Если вернулся StoredResponse, его нужно отдать клиенту как есть; если
+// вернулась заявка — вызывающий владеет ключом, должен уложиться в
+// IdempotentHandlerTimeout и обязан вызвать CompleteIdempotent или AbortIdempotent.
+func (s *Service) BeginIdempotent(
+	ctx context.Context,
+	scope, key, requestHash string,
+	ttl time.Duration,
+) (*IdempotencyClaim, *StoredResponse, error) {
+
+	token := uuid.New()
+	claimed, rec, err := s.repo.ClaimIdempotencyKey(ctx, scope, key, requestHash, token, ttl, idempotencyLockTimeout)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to claim idempotency key: %w", storageError(err))
+	}
+	if claimed {
+		return &IdempotencyClaim{scope: scope, key: key, token: token}, nil, nil
+	}
+	if rec == nil {
+		return nil, nil, ErrIdempotencyKeyInFlight
+	}
+	if rec.RequestHash != requestHash {
+		return nil, nil, ErrIdempotencyKeyReused
+	}
+	if rec.StatusCode == 0 {
+		return nil, nil, ErrIdempotencyKeyInFlight
+	}
+
+	return nil, &StoredResponse{
+		StatusCode:  rec.StatusCode,
+		ContentType: rec.ContentType,
+		Body:        rec.ResponseBody,
+	}, nil
+}
+
+// CompleteIdempotent сохраняет ответ; repo.ErrIdempotencyClaimLost — ключ
+// перехвачен, ответ не сохранён
+func (s *Service) CompleteIdempotent(ctx context.Context, c *IdempotencyClaim, resp StoredResponse) error {
+	return s.repo.CompleteIdempotencyKey(ctx, c.scope, c.key, c.token, resp.StatusCode, resp.ContentType, resp.Body)
+}
+
+func (s *Service) AbortIdempotent(ctx context.Context, c *IdempotencyClaim) error {
+	return s.repo.ReleaseIdempotencyKey(ctx, c.scope, c.key, c.token)
+}
+
+// PurgeExpiredIdempotencyKeys удаляет истёкшие ключи
+func (s *Service) PurgeExpiredIdempotencyKeys(ctx context.Context) (int64, error) {
+	return s.repo.DeleteExpiredIdempotencyKeys(ctx)
+}
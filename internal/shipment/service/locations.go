@@ -0,0 +1,65 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"transline.kz/internal/location"
+)
+
+var ErrLocationNotFound = errors.New("location not found")
+
+// SearchLocations — подсказки городов для автодополнения
+func (s *Service) SearchLocations(query string, limit int) []location.Match {
+	if limit <= 0 || limit > MaxPageSize {
+		limit = DefaultPageSize
+	}
+	return s.locations.Search(query, limit)
+}
+
+// GetLocation ищет город по каноническому коду или коду КАТО
+func (s *Service) GetLocation(code string) (*location.City, error) {
+	if c, ok := s.locations.City(code); ok {
+		return c, nil
+	}
+	if c, ok := s.locations.ByKATO(code); ok {
+		return c, nil
+	}
+	return nil, ErrLocationNotFound
+}
+
+// LocationOblast — область города; nil у городов республиканского значения
+func (s *Service) LocationOblast(c *location.City) *location.Oblast {
+	o, _ := s.locations.Oblast(c.Oblast)
+	return o
+}
+
+// NormalizeStoredCities переписывает коды городов, сохранённые до появления
+// справочника ("Алматы", "almaty city"), на канонические. Нераспознанные
+// и неоднозначные коды остаются как есть.
+func (s *Service) NormalizeStoredCities(ctx context.Context) (int64, error) {
+	codes, err := s.repo.DistinctCityCodes(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list city codes: %w", storageError(err))
+	}
+
+	var total int64
+	for _, code := range codes {
+		if _, ok := s.locations.City(code); ok {
+			continue
+		}
+		m, err := s.locations.Resolve(code)
+		if err != nil {
+			slog.Debug("stored city code not recognised", "code", code, "err", err)
+			continue
+		}
+		n, err := s.repo.RenameCityCode(ctx, code, m.City.Code)
+		if err != nil {
+			return total, fmt.Errorf("failed to rename city code %q: %w", code, storageError(err))
+		}
+		total += n
+	}
+	return total, nil
+}
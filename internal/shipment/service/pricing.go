@@ -0,0 +1,172 @@
+package service
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+
+	"transline.kz/internal/location"
+	"transline.kz/internal/measure"
+	"transline.kz/internal/money"
+	"transline.kz/internal/shipment/repo"
+)
+
+// ServiceLevel — уровень сервиса; совпадает с CHECK в tariff_rates.service_level
+type ServiceLevel string
+
+const (
+	ServiceEconomy  ServiceLevel = "ECONOMY"
+	ServiceStandard ServiceLevel = "STANDARD"
+	ServiceExpress  ServiceLevel = "EXPRESS"
+)
+
+const DefaultServiceLevel = ServiceStandard
+
+// Ограничения одной перевозки (полуприцеп)
+const (
+	MaxWeight = 20_000 * measure.Kilogram
+	MaxVolume = 90 * measure.CubicMetre
+)
+
+// Коды строк расшифровки цены; надбавки используют свои коды из тарифа
+const (
+	lineBase     = "BASE"
+	lineDistance = "DISTANCE"
+	lineWeight   = "WEIGHT"
+	lineMinPrice = "MIN_PRICE"
+)
+
+func ParseServiceLevel(s string) (ServiceLevel, bool) {
+	switch l := ServiceLevel(s); l {
+	case ServiceEconomy, ServiceStandard, ServiceExpress:
+		return l, true
+	}
+	return "", false
+}
+
+// priceRequest — что тарифицируется: города маршрута по порядку, груз, сервис и опции
+type priceRequest struct {
+	cities  []*location.City
+	weight  measure.Weight
+	volume  measure.Volume
+	level   ServiceLevel
+	options []string
+}
+
+type priceResult struct {
+	distanceKm  int
+	chargeable  measure.Weight
+	transitDays int
+	lines       []PriceLine
+	total       money.Money
+}
+
+// PriceLine — строка расшифровки цены
+type PriceLine struct {
+	Code        string
+	Description string
+	Amount      money.Money
+}
+
+// calculatePrice считает цену по тарифу:
+//
+//	перевозка = base + per_km × расстояние + per_kg × оплачиваемый вес (не меньше min_price)
+//	итого     = перевозка + процентные надбавки от перевозки + фиксированные надбавки
+//
+// Оплачиваемый вес — максимум из фактического и объёмного. Каждая строка
+// округляется до тиына, итог — сумма строк.
+func calculatePrice(t *repo.Tariff, req priceRequest) (*priceResult, error) {
+	res := &priceResult{
+		distanceKm: routeDistanceKm(req.cities, t.RoadFactor),
+		chargeable: max(req.weight, req.volume.VolumetricWeight(t.VolumetricKgPerM3)),
+	}
+
+	rate, ok := selectRate(t, req.level, res.distanceKm)
+	if !ok {
+		return nil, fmt.Errorf("tariff v%d has no %s rate for %d km", t.Version, req.level, res.distanceKm)
+	}
+	res.transitDays = rate.TransitDays
+
+	distance, err := rate.PerKm.MulRat(big.NewRat(int64(res.distanceKm), 1))
+	if err != nil {
+		return nil, err
+	}
+	weight, err := rate.PerKg.MulRat(big.NewRat(int64(res.chargeable), int64(measure.Kilogram)))
+	if err != nil {
+		return nil, err
+	}
+	res.lines = []PriceLine{
+		{Code: lineBase, Description: fmt.Sprintf("%s base rate", req.level), Amount: rate.BasePrice},
+		{Code: lineDistance, Description: fmt.Sprintf("%d km", res.distanceKm), Amount: distance},
+		{Code: lineWeight, Description: fmt.Sprintf("%s kg chargeable", res.chargeable.Kg()), Amount: weight},
+	}
+
+	freight, err := sum(t.Currency, res.lines)
+	if err != nil {
+		return nil, err
+	}
+	if c, _ := freight.Cmp(t.MinPrice); c < 0 {
+		adj, _ := t.MinPrice.Sub(freight)
+		res.lines = append(res.lines, PriceLine{Code: lineMinPrice, Description: "Minimum price adjustment", Amount: adj})
+		freight = t.MinPrice
+	}
+
+	requested := make(map[string]bool, len(req.options))
+	for _, o := range req.options {
+		requested[o] = true
+	}
+	for _, sc := range t.Surcharges {
+		if !sc.Mandatory && !requested[sc.Code] {
+			continue
+		}
+		amount := sc.Amount
+		if sc.Kind == repo.SurchargePercent {
+			if amount, err = freight.MulRat(new(big.Rat).Quo(sc.Percent, big.NewRat(100, 1))); err != nil {
+				return nil, err
+			}
+		}
+		res.lines = append(res.lines, PriceLine{Code: sc.Code, Description: sc.Description, Amount: amount})
+	}
+
+	if res.total, err = sum(t.Currency, res.lines); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// routeDistanceKm — сумма расстояний между соседними городами маршрута по
+// прямой, умноженная на дорожный коэффициент тарифа, с округлением вверх
+func routeDistanceKm(cities []*location.City, roadFactor *big.Rat) int {
+	var km float64
+	for i := 1; i < len(cities); i++ {
+		km += location.DistanceKm(cities[i-1], cities[i])
+	}
+	factor, _ := roadFactor.Float64()
+	return int(math.Ceil(km * factor))
+}
+
+// selectRate — ставка уровня сервиса для самого узкого пояса, покрывающего расстояние
+// (tariff.Rates отсортированы по поясам, пояс без ограничения — последним)
+func selectRate(t *repo.Tariff, level ServiceLevel, km int) (*repo.TariffRate, bool) {
+	for i := range t.Rates {
+		r := &t.Rates[i]
+		if r.ServiceLevel != string(level) {
+			continue
+		}
+		if r.MaxDistanceKm == nil || km <= *r.MaxDistanceKm {
+			return r, true
+		}
+	}
+	return nil, false
+}
+
+func sum(c money.Currency, lines []PriceLine) (money.Money, error) {
+	total := money.FromMinor(0, c)
+	for _, l := range lines {
+		var err error
+		if total, err = total.Add(l.Amount); err != nil {
+			return money.Money{}, err
+		}
+	}
+	return total, nil
+}
@@ -0,0 +1,209 @@
+package service
+
+import (
+	"math/big"
+	"testing"
+
+	"transline.kz/internal/location"
+	"transline.kz/internal/measure"
+	"transline.kz/internal/money"
+	"transline.kz/internal/shipment/repo"
+)
+
+func kzt(s string) money.Money { return money.MustParse(s, money.KZT) }
+
+func ptr[T any](v T) *T { return &v }
+
+// Города на экваторе: градус долготы — 111.19 км по большому кругу
+func equator(lons ...float64) []*location.City {
+	out := make([]*location.City, len(lons))
+	for i, lon := range lons {
+		out[i] = &location.City{Code: "X", Longitude: lon}
+	}
+	return out
+}
+
+func testTariff() *repo.Tariff {
+	return &repo.Tariff{
+		Version:           3,
+		Currency:          money.KZT,
+		MinPrice:          kzt("5000"),
+		VolumetricKgPerM3: 250,
+		RoadFactor:        big.NewRat(1, 1),
+		Rates: []repo.TariffRate{
+			{ServiceLevel: "STANDARD", MaxDistanceKm: ptr(100), BasePrice: kzt("1000"), PerKm: kzt("50"), PerKg: kzt("10.50"), TransitDays: 1},
+			{ServiceLevel: "EXPRESS", MaxDistanceKm: ptr(100), BasePrice: kzt("4000"), PerKm: kzt("80"), PerKg: kzt("20"), TransitDays: 1},
+			{ServiceLevel: "STANDARD", MaxDistanceKm: ptr(500), BasePrice: kzt("2000"), PerKm: kzt("35.50"), PerKg: kzt("12.25"), TransitDays: 2},
+			{ServiceLevel: "STANDARD", BasePrice: kzt("3000"), PerKm: kzt("30"), PerKg: kzt("11"), TransitDays: 5},
+		},
+		Surcharges: []repo.TariffSurcharge{
+			{Code: "FUEL", Kind: repo.SurchargePercent, Percent: big.NewRat(125, 10), Mandatory: true, Description: "Fuel"},
+			{Code: "FRAGILE", Kind: repo.SurchargeFixed, Amount: kzt("1500"), Description: "Fragile"},
+			{Code: "DOOR_PICKUP", Kind: repo.SurchargePercent, Percent: big.NewRat(333, 100), Description: "Door pickup"},
+		},
+	}
+}
+
+func TestCalculatePrice(t *testing.T) {
+	type line struct{ code, desc, amount string }
+	tests := []struct {
+		name      string
+		req       priceRequest
+		wantKm    int
+		wantKg    string
+		wantDays  int
+		wantLines []line
+		wantTotal string
+	}{
+		{
+			// 1.125 тиына округляются от нуля; топливо — 12.5% от перевозки
+			name:     "distance band, mandatory and fixed surcharges",
+			req:      priceRequest{cities: equator(0, 1), weight: 100_500, volume: 200_000, level: ServiceStandard, options: []string{"FRAGILE"}},
+			wantKm:   112,
+			wantKg:   "100.5",
+			wantDays: 2,
+			wantLines: []line{
+				{"BASE", "STANDARD base rate", "2000.00"},
+				{"DISTANCE", "112 km", "3976.00"},
+				{"WEIGHT", "100.5 kg chargeable", "1231.13"},
+				{"FUEL", "Fuel", "900.89"},
+				{"FRAGILE", "Fragile", "1500.00"},
+			},
+			wantTotal: "9608.02",
+		},
+		{
+			// процентные надбавки считаются от перевозки после доведения до минимума
+			name:     "raised to the minimum price",
+			req:      priceRequest{cities: equator(0, 0.5), weight: measure.Kilogram, level: ServiceStandard},
+			wantKm:   56,
+			wantKg:   "1",
+			wantDays: 1,
+			wantLines: []line{
+				{"BASE", "STANDARD base rate", "1000.00"},
+				{"DISTANCE", "56 km", "2800.00"},
+				{"WEIGHT", "1 kg chargeable", "10.50"},
+				{"MIN_PRICE", "Minimum price adjustment", "1189.50"},
+				{"FUEL", "Fuel", "625.00"},
+			},
+			wantTotal: "5625.00",
+		},
+		{
+			// объёмный вес 1.5 м³ × 250 = 375 кг больше фактического; надбавки —
+			// в порядке тарифа, а не запроса
+			name:     "volumetric weight with percent and fixed options",
+			req:      priceRequest{cities: equator(0, 1), weight: 100 * measure.Kilogram, volume: 1_500_000, level: ServiceStandard, options: []string{"DOOR_PICKUP", "FRAGILE"}},
+			wantKm:   112,
+			wantKg:   "375",
+			wantDays: 2,
+			wantLines: []line{
+				{"BASE", "STANDARD base rate", "2000.00"},
+				{"DISTANCE", "112 km", "3976.00"},
+				{"WEIGHT", "375 kg chargeable", "4593.75"},
+				{"FUEL", "Fuel", "1321.22"},
+				{"FRAGILE", "Fragile", "1500.00"},
+				{"DOOR_PICKUP", "Door pickup", "351.97"},
+			},
+			wantTotal: "13742.94",
+		},
+		{
+			name:     "open-ended band",
+			req:      priceRequest{cities: equator(0, 4, 10), weight: 2000 * measure.Kilogram, level: ServiceStandard},
+			wantKm:   1112,
+			wantKg:   "2000",
+			wantDays: 5,
+			wantLines: []line{
+				{"BASE", "STANDARD base rate", "3000.00"},
+				{"DISTANCE", "1112 km", "33360.00"},
+				{"WEIGHT", "2000 kg chargeable", "22000.00"},
+				{"FUEL", "Fuel", "7295.00"},
+			},
+			wantTotal: "65655.00",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := calculatePrice(testTariff(), tt.req)
+			if err != nil {
+				t.Fatalf("calculatePrice error = %v", err)
+			}
+			if res.distanceKm != tt.wantKm || res.chargeable.Kg() != tt.wantKg || res.transitDays != tt.wantDays {
+				t.Errorf("distance %d km, chargeable %s kg, %d days; want %d km, %s kg, %d days",
+					res.distanceKm, res.chargeable.Kg(), res.transitDays, tt.wantKm, tt.wantKg, tt.wantDays)
+			}
+			if len(res.lines) != len(tt.wantLines) {
+				t.Fatalf("lines = %+v, want %+v", res.lines, tt.wantLines)
+			}
+			for i, w := range tt.wantLines {
+				l := res.lines[i]
+				if l.Code != w.code || l.Description != w.desc || l.Amount != kzt(w.amount) {
+					t.Errorf("line %d = %s %q %s, want %s %q %s KZT", i, l.Code, l.Description, l.Amount, w.code, w.desc, w.amount)
+				}
+			}
+			if res.total != kzt(tt.wantTotal) {
+				t.Errorf("total = %s, want %s KZT", res.total, tt.wantTotal)
+			}
+		})
+	}
+}
+
+func TestCalculatePriceNoRate(t *testing.T) {
+	// у EXPRESS только пояс до 100 км
+	_, err := calculatePrice(testTariff(), priceRequest{cities: equator(0, 1), weight: measure.Kilogram, level: ServiceExpress})
+	if err == nil || err.Error() != "tariff v3 has no EXPRESS rate for 112 km" {
+		t.Errorf("calculatePrice error = %v", err)
+	}
+	_, err = calculatePrice(testTariff(), priceRequest{cities: equator(0, 0.5), weight: measure.Kilogram, level: ServiceEconomy})
+	if err == nil {
+		t.Error("calculatePrice without ECONOMY rates succeeded")
+	}
+}
+
+func TestSelectRate(t *testing.T) {
+	tests := []struct {
+		level    ServiceLevel
+		km       int
+		wantBase string // "" — ставки нет
+	}{
+		{ServiceStandard, 0, "1000.00"},
+		{ServiceStandard, 100, "1000.00"},
+		{ServiceStandard, 101, "2000.00"},
+		{ServiceStandard, 500, "2000.00"},
+		{ServiceStandard, 501, "3000.00"},
+		{ServiceStandard, 100_000, "3000.00"},
+		{ServiceExpress, 100, "4000.00"},
+		{ServiceExpress, 101, ""},
+		{ServiceEconomy, 10, ""},
+	}
+	for _, tt := range tests {
+		r, ok := selectRate(testTariff(), tt.level, tt.km)
+		switch {
+		case tt.wantBase == "" && ok:
+			t.Errorf("selectRate(%s, %d) = %s, want no rate", tt.level, tt.km, r.BasePrice)
+		case tt.wantBase != "" && (!ok || r.BasePrice.Amount() != tt.wantBase):
+			t.Errorf("selectRate(%s, %d) = %v, %v; want base %s", tt.level, tt.km, r, ok, tt.wantBase)
+		}
+	}
+}
+
+func TestRouteDistanceKm(t *testing.T) {
+	tests := []struct {
+		name   string
+		cities []*location.City
+		factor *big.Rat
+		want   int
+	}{
+		{"single city", equator(0), big.NewRat(1, 1), 0},
+		{"same point", equator(1, 1), big.NewRat(1, 1), 0},
+		// 111.19 км → вверх до 112
+		{"rounded up", equator(0, 1), big.NewRat(1, 1), 112},
+		// 111.19 × 1.2 = 133.43
+		{"road factor", equator(0, 1), big.NewRat(6, 5), 134},
+		// сумма плеч, а не расстояние по прямой: 111.19 + 111.19
+		{"waypoint back and forth", equator(0, 1, 0), big.NewRat(1, 1), 223},
+	}
+	for _, tt := range tests {
+		if got := routeDistanceKm(tt.cities, tt.factor); got != tt.want {
+			t.Errorf("%s: routeDistanceKm = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,317 @@
+package service
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"transline.kz/internal/idn"
+	"transline.kz/internal/money"
+	"transline.kz/internal/shipment/repo"
+	"transline.kz/internal/tracking"
+)
+
+const (
+	DefaultPageSize = 20
+	MaxPageSize     = 100
+)
+
+var ErrInvalidQuery = errors.New("invalid query")
+
+// Допустимые значения сортировки; префикс "-" — по убыванию
+const (
+	SortCreatedAt = "createdAt"
+	SortPrice     = "price"
+)
+
+type ListShipmentsInput struct {
+	CustomerIDN   string
+	Statuses      []Status
+	RouteContains string
+	// OriginCity/DestinationCity — коды городов отправления и назначения
+	OriginCity      string
+	DestinationCity string
+	CreatedFrom     *time.Time
+	CreatedTo       *time.Time
+	// Currency ограничивает выборку одной валютой; PriceMin/PriceMax —
+	// десятичные строки в этой валюте (по умолчанию KZT)
+	Currency string
+	PriceMin string
+	PriceMax string
+
+	// Sort — "createdAt", "-createdAt", "price", "-price"; по умолчанию "-createdAt"
+	Sort   string
+	Limit  int
+	Cursor string
+}
+
+type ListShipmentsResult struct {
+	Items []*Shipment
+	// NextCursor пустой, если страница последняя
+	NextCursor string
+}
+
+// cursorToken — содержимое непрозрачного курсора; Sort фиксирует порядок,
+// для которого курсор был выдан
+type cursorToken struct {
+	Sort      string    `json:"s"`
+	CreatedAt time.Time `json:"c,omitzero"`
+	Price     string    `json:"p,omitempty"`
+	Currency  string    `json:"cur,omitempty"`
+	ID        uuid.UUID `json:"i"`
+}
+
+func (s *Service) GetShipment(ctx context.Context, id uuid.UUID) (*Shipment, error) {
+	sh, err := s.repo.Get(ctx, id)
+	if errors.Is(err, repo.ErrNotFound) {
+		return nil, ErrShipmentNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load shipment: %w", storageError(err))
+	}
+	return s.toShipment(sh)
+}
+
+// ShipmentIDByTrackingNumber ищет отправление по трек-номеру; регистр,
+// пробелы и дефисы во вводе не важны
+func (s *Service) ShipmentIDByTrackingNumber(ctx context.Context, number string) (uuid.UUID, error) {
+	number = tracking.Normalize(number)
+	if err := tracking.Validate(number); err != nil {
+		return uuid.Nil, fmt.Errorf("%w: %w", ErrInvalidTrackingNumber, err)
+	}
+	id, err := s.repo.IDByTrackingNumber(ctx, number)
+	if errors.Is(err, repo.ErrNotFound) {
+		return uuid.Nil, ErrShipmentNotFound
+	}
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("failed to look up tracking number: %w", storageError(err))
+	}
+	return id, nil
+}
+
+func (s *Service) ListShipments(
+	ctx context.Context,
+	in ListShipmentsInput,
+) (*ListShipmentsResult, error) {
+
+	f, err := s.listFilter(ctx, in)
+	if errors.Is(err, errCustomerUnknown) {
+		return &ListShipmentsResult{Items: []*Shipment{}}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// +1 запись, чтобы понять, есть ли следующая страница
+	limit := f.Limit
+	f.Limit++
+
+	rows, err := s.repo.List(ctx, f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list shipments: %w", storageError(err))
+	}
+
+	res := &ListShipmentsResult{Items: make([]*Shipment, 0, len(rows))}
+	for i := range rows {
+		if i == limit {
+			break
+		}
+		sh, err := s.toShipment(rows[i])
+		if err != nil {
+			return nil, err
+		}
+		res.Items = append(res.Items, sh)
+	}
+
+	if len(rows) > limit {
+		last := rows[limit-1]
+		res.NextCursor = encodeCursor(cursorToken{
+			Sort:      sortKey(f),
+			CreatedAt: last.CreatedAt,
+			Price:     last.Price.Amount(),
+			Currency:  string(last.Price.Currency()),
+			ID:        last.ID,
+		})
+	}
+	return res, nil
+}
+
+// listFilter — фильтр репозитория с кодами городов и id клиента;
+// errCustomerUnknown — клиента с таким IDN нет, выборка пуста
+func (s *Service) listFilter(ctx context.Context, in ListShipmentsInput) (repo.ListFilter, error) {
+	f, err := buildListFilter(in)
+	if err != nil {
+		return f, err
+	}
+	if in.OriginCity != "" {
+		if f.OriginCity, _, err = s.resolveCity(in.OriginCity); err != nil {
+			return f, invalidQuery("origin", "%v", err)
+		}
+	}
+	if in.DestinationCity != "" {
+		if f.DestinationCity, _, err = s.resolveCity(in.DestinationCity); err != nil {
+			return f, invalidQuery("destination", "%v", err)
+		}
+	}
+
+	// IDN → id клиента через customer-service
+	if in.CustomerIDN != "" {
+		customerID, err := s.resolveCustomerIDN(ctx, in.CustomerIDN)
+		if err != nil {
+			return f, err
+		}
+		f.CustomerID = &customerID
+	}
+	return f, nil
+}
+
+func buildListFilter(in ListShipmentsInput) (repo.ListFilter, error) {
+	f := repo.ListFilter{
+		RouteContains: strings.TrimSpace(in.RouteContains),
+		CreatedFrom:   in.CreatedFrom,
+		CreatedTo:     in.CreatedTo,
+		Limit:         in.Limit,
+	}
+
+	for _, st := range in.Statuses {
+		if _, err := ParseStatus(string(st)); err != nil {
+			return f, invalidQuery("status", "unknown status %q", st)
+		}
+		f.Statuses = append(f.Statuses, string(st))
+	}
+
+	if in.CreatedFrom != nil && in.CreatedTo != nil && !in.CreatedFrom.Before(*in.CreatedTo) {
+		return f, invalidQuery("createdFrom", "must be before createdTo")
+	}
+	if in.Currency != "" || in.PriceMin != "" || in.PriceMax != "" {
+		if err := priceRange(&f, in); err != nil {
+			return f, err
+		}
+	}
+
+	switch {
+	case f.Limit == 0:
+		f.Limit = DefaultPageSize
+	case f.Limit < 0 || f.Limit > MaxPageSize:
+		return f, invalidQuery("limit", "must be between 1 and %d", MaxPageSize)
+	}
+
+	sort := in.Sort
+	if sort == "" {
+		sort = "-" + SortCreatedAt
+	}
+	f.SortDesc = strings.HasPrefix(sort, "-")
+	switch strings.TrimPrefix(sort, "-") {
+	case SortCreatedAt:
+		f.SortBy = repo.SortByCreatedAt
+	case SortPrice:
+		f.SortBy = repo.SortByPrice
+	default:
+		return f, invalidQuery("sort", "unknown sort %q", in.Sort)
+	}
+
+	if in.Cursor != "" {
+		c, err := decodeCursor(in.Cursor)
+		if err != nil {
+			return f, invalidQuery("cursor", "malformed cursor")
+		}
+		if c.Sort != sortKey(f) {
+			return f, invalidQuery("cursor", "cursor was issued for a different sort")
+		}
+		f.After = &repo.Cursor{CreatedAt: c.CreatedAt, ID: c.ID}
+		if f.SortBy == repo.SortByPrice {
+			cur, err := money.ParseCurrency(c.Currency)
+			if err != nil {
+				return f, invalidQuery("cursor", "malformed cursor")
+			}
+			if f.After.Price, err = money.Parse(c.Price, cur); err != nil {
+				return f, invalidQuery("cursor", "malformed cursor")
+			}
+		}
+	}
+
+	return f, nil
+}
+
+// priceRange — фильтр по валюте и диапазону цены; границы без явной
+// валюты трактуются в KZT
+func priceRange(f *repo.ListFilter, in ListShipmentsInput) error {
+	v := &ValidationError{kind: ErrInvalidQuery}
+	cur, err := money.ParseCurrency(in.Currency)
+	if err != nil {
+		v.add("currency", "%v", err)
+		return v
+	}
+	f.Currency = cur
+
+	if in.PriceMin != "" {
+		if m, ok := parsePrice(v, "priceMin", in.PriceMin, string(cur)); ok {
+			f.PriceMin = &m
+		}
+	}
+	if in.PriceMax != "" {
+		if m, ok := parsePrice(v, "priceMax", in.PriceMax, string(cur)); ok {
+			f.PriceMax = &m
+		}
+	}
+	if f.PriceMin != nil && f.PriceMax != nil {
+		if c, _ := f.PriceMin.Cmp(*f.PriceMax); c > 0 {
+			v.add("priceMin", "must not exceed priceMax")
+		}
+	}
+	return v.err()
+}
+
+var errCustomerUnknown = errors.New("customer is not registered")
+
+func (s *Service) resolveCustomerIDN(ctx context.Context, idnStr string) (uuid.UUID, error) {
+	if err := idn.Validate(idnStr); err != nil {
+		return uuid.Nil, invalidQuery("customerIdn", "%v", err)
+	}
+
+	grpcCtx, cancel := context.WithTimeout(ctx, customerCallTimeout)
+	defer cancel()
+	cus, err := s.customerGRPC.GetCustomerByIdn(grpcCtx, idnStr)
+	if status.Code(err) == codes.NotFound {
+		return uuid.Nil, errCustomerUnknown
+	}
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("failed to resolve customer: %w", customerError(err))
+	}
+
+	id, err := uuid.Parse(cus.Id)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("invalid customer id format: %w", err)
+	}
+	return id, nil
+}
+
+func sortKey(f repo.ListFilter) string {
+	if f.SortDesc {
+		return "-" + string(f.SortBy)
+	}
+	return string(f.SortBy)
+}
+
+func encodeCursor(c cursorToken) string {
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func decodeCursor(s string) (cursorToken, error) {
+	var c cursorToken
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return c, err
+	}
+	err = json.Unmarshal(b, &c)
+	return c, err
+}
@@ -0,0 +1,296 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"transline.kz/internal/location"
+	"transline.kz/internal/measure"
+	"transline.kz/internal/money"
+	"transline.kz/internal/shipment/repo"
+)
+
+var ErrQuoteNotFound = errors.New("quote not found")
+
+type CreateQuoteInput struct {
+	// Route/RouteText — как в CreateShipmentInput; тарифицируются только города
+	Route     Route
+	RouteText string
+	// Weight — кг, Volume — м³, десятичные строки; вместо них можно передать
+	// Items — тогда вес и объём считаются по местам
+	Weight       string
+	Volume       string
+	Items        []ItemInput
+	ServiceLevel string
+	// Options — коды необязательных надбавок тарифа (FRAGILE, DOOR_PICKUP, ...)
+	Options  []string
+	Currency string
+}
+
+// Quote — рассчитанная цена перевозки, действительная до ExpiresAt
+type Quote struct {
+	ID               uuid.UUID
+	TariffVersion    int
+	Route            Route
+	DistanceKm       int
+	Weight           measure.Weight
+	Volume           measure.Volume
+	ChargeableWeight measure.Weight
+	ServiceLevel     ServiceLevel
+	Options          []string
+	Price            money.Money
+	TransitDays      int
+	Breakdown        []PriceLine
+	ExpiresAt        time.Time
+	CreatedAt        time.Time
+	// Used — котировка уже закреплена за отправлением
+	Used bool
+}
+
+// CreateQuote рассчитывает цену по действующему тарифу и сохраняет котировку на Config.QuoteTTL
+func (s *Service) CreateQuote(ctx context.Context, in CreateQuoteInput) (*Quote, error) {
+	v := newValidation()
+	route := s.routeFromInput(v, in.Route, in.RouteText)
+	s.requireKnownCities(v, &route)
+	currency, err := money.ParseCurrency(in.Currency)
+	if err != nil {
+		v.add("currency", "%v", err)
+		currency = money.DefaultCurrency
+	}
+
+	var (
+		weight measure.Weight
+		volume measure.Volume
+	)
+	if len(in.Items) > 0 {
+		if in.Weight != "" || in.Volume != "" {
+			v.add("items", "must not be set together with weightKg/volumeM3")
+		}
+		cargo := parseCargo(v, in.Items, currency)
+		weight, volume = cargo.TotalWeight, cargo.TotalVolume
+	} else {
+		weight, volume = parseLoad(v, in.Weight, in.Volume)
+	}
+
+	level := DefaultServiceLevel
+	if in.ServiceLevel != "" {
+		var ok bool
+		if level, ok = ParseServiceLevel(strings.ToUpper(in.ServiceLevel)); !ok {
+			v.add("serviceLevel", "unknown service level %q", in.ServiceLevel)
+		}
+	}
+
+	if err := v.err(); err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	tariff, err := s.repo.ActiveTariff(ctx, currency, now)
+	if errors.Is(err, repo.ErrNoTariff) {
+		return nil, invalidField("currency", "no tariff in %s", currency)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load tariff: %w", storageError(err))
+	}
+
+	options := normalizeOptions(v, tariff, in.Options)
+	if err := v.err(); err != nil {
+		return nil, err
+	}
+
+	cities := make([]*location.City, 0, len(route.Waypoints)+2)
+	for _, st := range route.Stops() {
+		c, _ := s.locations.City(st.CityCode)
+		cities = append(cities, c)
+	}
+	priced, err := calculatePrice(tariff, priceRequest{
+		cities:  cities,
+		weight:  weight,
+		volume:  volume,
+		level:   level,
+		options: options,
+	})
+	if err != nil {
+		return nil, invalidField("serviceLevel", "%v", err)
+	}
+
+	q := repo.Quote{
+		ID:               uuid.New(),
+		TariffID:         tariff.ID,
+		OriginCity:       route.Origin.CityCode,
+		DestinationCity:  route.Destination.CityCode,
+		DistanceKm:       priced.distanceKm,
+		Weight:           weight,
+		Volume:           volume,
+		ChargeableWeight: priced.chargeable,
+		ServiceLevel:     string(level),
+		Options:          options,
+		Price:            priced.total,
+		TransitDays:      priced.transitDays,
+		ExpiresAt:        now.Add(s.cfg.QuoteTTL),
+	}
+	for _, w := range route.Waypoints {
+		q.Waypoints = append(q.Waypoints, w.CityCode)
+	}
+	for _, l := range priced.lines {
+		q.Breakdown = append(q.Breakdown, repo.QuoteLine{Code: l.Code, Description: l.Description, Amount: l.Amount.Amount()})
+	}
+
+	saved, err := s.repo.InsertQuote(ctx, q)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save quote: %w", storageError(err))
+	}
+	return toQuote(saved)
+}
+
+func (s *Service) GetQuote(ctx context.Context, id uuid.UUID) (*Quote, error) {
+	q, err := s.repo.GetQuote(ctx, id)
+	if errors.Is(err, repo.ErrQuoteNotFound) {
+		return nil, ErrQuoteNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load quote: %w", storageError(err))
+	}
+	return toQuote(q)
+}
+
+// parseLoad разбирает вес и объём груза, заданные итогом
+func parseLoad(v *ValidationError, weightKg, volumeM3 string) (measure.Weight, measure.Volume) {
+	weight, err := measure.ParseKg(weightKg)
+	switch {
+	case err != nil:
+		v.add("weightKg", "%v", err)
+	case weight <= 0:
+		v.add("weightKg", "must be positive")
+	case weight > MaxWeight:
+		v.add("weightKg", "too high (max %s kg)", MaxWeight.Kg())
+	}
+
+	var volume measure.Volume
+	if volumeM3 != "" {
+		volume, err = measure.ParseM3(volumeM3)
+		switch {
+		case err != nil:
+			v.add("volumeM3", "%v", err)
+		case volume < 0:
+			v.add("volumeM3", "must not be negative")
+		case volume > MaxVolume:
+			v.add("volumeM3", "too high (max %s m3)", MaxVolume.M3())
+		}
+	}
+	return weight, volume
+}
+
+// normalizeOptions проверяет опции по тарифу: только необязательные надбавки, без повторов
+func normalizeOptions(v *ValidationError, t *repo.Tariff, options []string) []string {
+	out := make([]string, 0, len(options))
+	for i, o := range options {
+		o = strings.ToUpper(strings.TrimSpace(o))
+		if slices.Contains(out, o) {
+			continue
+		}
+		idx := slices.IndexFunc(t.Surcharges, func(sc repo.TariffSurcharge) bool { return sc.Code == o })
+		switch {
+		case idx < 0:
+			v.add(fmt.Sprintf("options[%d]", i), "unknown option %q", o)
+		case t.Surcharges[idx].Mandatory:
+			// обязательные надбавки применяются и так
+		default:
+			out = append(out, o)
+		}
+	}
+	return out
+}
+
+// usableQuote загружает котировку для создания отправления. Неизвестная,
+// истёкшая или использованная котировка — нарушение в v и nil без ошибки.
+func (s *Service) usableQuote(ctx context.Context, v *ValidationError, id uuid.UUID) (*repo.Quote, error) {
+	q, err := s.repo.GetQuote(ctx, id)
+	if errors.Is(err, repo.ErrQuoteNotFound) {
+		v.add("quoteId", "unknown quote")
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load quote: %w", storageError(err))
+	}
+	switch {
+	case q.ShipmentID != nil:
+		v.add("quoteId", "quote was already used")
+	case !time.Now().Before(q.ExpiresAt):
+		v.add("quoteId", "quote expired at %s", q.ExpiresAt.Format(time.RFC3339))
+	default:
+		return q, nil
+	}
+	return nil, nil
+}
+
+// routeForQuote — маршрут отправления по котировке: без маршрута в запросе
+// берутся города котировки, иначе города запроса должны с ней совпадать
+func (s *Service) routeForQuote(v *ValidationError, route Route, text string, q *repo.Quote) Route {
+	quoted := Route{
+		Origin:      Stop{CityCode: q.OriginCity},
+		Destination: Stop{CityCode: q.DestinationCity},
+	}
+	for _, w := range q.Waypoints {
+		quoted.Waypoints = append(quoted.Waypoints, Stop{CityCode: w})
+	}
+
+	if text == "" && route.Origin.CityCode == "" && route.Destination.CityCode == "" && len(route.Waypoints) == 0 {
+		return quoted
+	}
+	route = s.routeFromInput(v, route, text)
+	if !v.has("route") && route.String() != quoted.String() {
+		v.add("route", "does not match the quote (%s)", quoted.String())
+	}
+	return route
+}
+
+// checkQuotedCargo — груз отправления по котировке не тяжелее и не объёмнее
+// тарифицированного
+func checkQuotedCargo(v *ValidationError, cargo Cargo, q *repo.Quote) {
+	if cargo.TotalWeight > q.Weight {
+		v.add("items", "total weight %s kg exceeds the quoted %s kg", cargo.TotalWeight.Kg(), q.Weight.Kg())
+	}
+	if cargo.TotalVolume > q.Volume {
+		v.add("items", "total volume %s m3 exceeds the quoted %s m3", cargo.TotalVolume.M3(), q.Volume.M3())
+	}
+}
+
+func toQuote(q *repo.Quote) (*Quote, error) {
+	out := &Quote{
+		ID:            q.ID,
+		TariffVersion: q.TariffVersion,
+		Route: Route{
+			Origin:      Stop{Kind: StopOrigin, CityCode: q.OriginCity},
+			Destination: Stop{Kind: StopDestination, CityCode: q.DestinationCity},
+		},
+		DistanceKm:       q.DistanceKm,
+		Weight:           q.Weight,
+		Volume:           q.Volume,
+		ChargeableWeight: q.ChargeableWeight,
+		ServiceLevel:     ServiceLevel(q.ServiceLevel),
+		Options:          q.Options,
+		Price:            q.Price,
+		TransitDays:      q.TransitDays,
+		ExpiresAt:        q.ExpiresAt,
+		CreatedAt:        q.CreatedAt,
+		Used:             q.ShipmentID != nil,
+	}
+	for _, w := range q.Waypoints {
+		out.Route.Waypoints = append(out.Route.Waypoints, Stop{Kind: StopWaypoint, CityCode: w})
+	}
+	for _, l := range q.Breakdown {
+		amount, err := money.Parse(l.Amount, q.Price.Currency())
+		if err != nil {
+			return nil, fmt.Errorf("quote %s breakdown %s: %w", q.ID, l.Code, err)
+		}
+		out.Breakdown = append(out.Breakdown, PriceLine{Code: l.Code, Description: l.Description, Amount: amount})
+	}
+	return out, nil
+}
@@ -0,0 +1,262 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"transline.kz/internal/location"
+	"transline.kz/internal/shipment/repo"
+)
+
+// MaxWaypoints — максимум промежуточных точек в маршруте
+const MaxWaypoints = 8
+
+// routeSeparators — разделители в старом строковом формате "ALMATY→ASTANA"
+var routeSeparators = []string{"→", "->"}
+
+type StopKind string
+
+const (
+	StopOrigin      StopKind = repo.StopOrigin
+	StopWaypoint    StopKind = repo.StopWaypoint
+	StopDestination StopKind = repo.StopDestination
+)
+
+type Contact struct {
+	Name  string
+	Phone string
+}
+
+// Stop — точка маршрута: город (код) и, при необходимости, адрес, координаты и контакт
+type Stop struct {
+	Kind        StopKind
+	CityCode    string
+	Address     string
+	Coordinates *Coordinates
+	Contact     Contact
+	// Unresolved — города нет в справочнике: CityCode — название в верхнем
+	// регистре, координат города нет
+	Unresolved bool
+}
+
+// Route — структурированный маршрут
+type Route struct {
+	Origin      Stop
+	Waypoints   []Stop
+	Destination Stop
+}
+
+// ParseRoute разбирает старый формат "ALMATY→ASTANA" или "ALMATY→KARAGANDA→ASTANA":
+// первая точка — отправление, последняя — назначение, остальные — промежуточные
+func ParseRoute(s string) (Route, error) {
+	parts := []string{s}
+	for _, sep := range routeSeparators {
+		var next []string
+		for _, p := range parts {
+			next = append(next, strings.Split(p, sep)...)
+		}
+		parts = next
+	}
+	if len(parts) < 2 {
+		return Route{}, fmt.Errorf("must look like ORIGIN→DESTINATION")
+	}
+
+	stops := make([]Stop, len(parts))
+	for i, p := range parts {
+		stops[i] = Stop{CityCode: p}
+	}
+	return Route{
+		Origin:      stops[0],
+		Waypoints:   stops[1 : len(stops)-1],
+		Destination: stops[len(stops)-1],
+	}, nil
+}
+
+// String — маршрут в старом строковом формате, хранится в shipments.route
+func (r Route) String() string {
+	codes := make([]string, 0, len(r.Waypoints)+2)
+	for _, st := range r.Stops() {
+		codes = append(codes, st.CityCode)
+	}
+	return strings.Join(codes, "→")
+}
+
+// Stops — точки маршрута по порядку с проставленным Kind
+func (r Route) Stops() []Stop {
+	out := make([]Stop, 0, len(r.Waypoints)+2)
+	o := r.Origin
+	o.Kind = StopOrigin
+	out = append(out, o)
+	for _, w := range r.Waypoints {
+		w.Kind = StopWaypoint
+		out = append(out, w)
+	}
+	d := r.Destination
+	d.Kind = StopDestination
+	return append(out, d)
+}
+
+// routeFromInput — маршрут из структурированного ввода или, если он пуст,
+// из строки старого формата; города приводятся к кодам справочника
+func (s *Service) routeFromInput(v *ValidationError, route Route, text string) Route {
+	if text != "" && route.Origin.CityCode == "" && route.Destination.CityCode == "" {
+		r, err := ParseRoute(text)
+		if err != nil {
+			v.add("route", "%v", err)
+			return Route{}
+		}
+		route = r
+	}
+	s.resolveRoute(v, &route)
+	route.validate(v)
+	return route
+}
+
+// resolveRoute обрезает пробелы и приводит города к каноническим кодам
+// справочника. Города не из справочника остаются названием в верхнем регистре
+// с пометкой Unresolved; неоднозначные названия — нарушения в v.
+func (s *Service) resolveRoute(v *ValidationError, r *Route) {
+	r.eachStop(func(field string, st *Stop) { s.resolveStop(v, field, st) })
+}
+
+// requireKnownCities — для тарификации нужны координаты, поэтому все города
+// маршрута должны быть из справочника
+func (s *Service) requireKnownCities(v *ValidationError, r *Route) {
+	r.eachStop(func(field string, st *Stop) {
+		if st.Unresolved && !v.has(field+".city") {
+			v.add(field+".city", "%v", s.unknownCity(st.CityCode))
+		}
+	})
+}
+
+// eachStop вызывает fn для каждой точки маршрута с именем поля для ошибок
+func (r *Route) eachStop(fn func(field string, st *Stop)) {
+	fn("route.origin", &r.Origin)
+	for i := range r.Waypoints {
+		fn(fmt.Sprintf("route.waypoints[%d]", i), &r.Waypoints[i])
+	}
+	fn("route.destination", &r.Destination)
+}
+
+// markUnresolved помечает точки прочитанного маршрута, чьих городов нет в справочнике
+func (s *Service) markUnresolved(r *Route) {
+	r.eachStop(func(_ string, st *Stop) {
+		_, ok := s.locations.City(st.CityCode)
+		st.Unresolved = !ok
+	})
+}
+
+func (s *Service) resolveStop(v *ValidationError, field string, st *Stop) {
+	st.Address = strings.TrimSpace(st.Address)
+	st.Contact.Name = strings.TrimSpace(st.Contact.Name)
+	st.Contact.Phone = strings.TrimSpace(st.Contact.Phone)
+
+	st.CityCode = strings.TrimSpace(st.CityCode)
+	if st.CityCode == "" {
+		return
+	}
+	code, known, err := s.resolveCity(st.CityCode)
+	if err != nil {
+		v.add(field+".city", "%v", err)
+		return
+	}
+	st.CityCode, st.Unresolved = code, !known
+}
+
+// resolveCity возвращает канонический код города для любого его написания.
+// Справочник неполный, поэтому город не из него — не ошибка: кодом становится
+// название в верхнем регистре, known=false.
+func (s *Service) resolveCity(name string) (code string, known bool, err error) {
+	m, err := s.locations.Resolve(name)
+	if errors.Is(err, location.ErrNotFound) {
+		return strings.ToUpper(strings.TrimSpace(name)), false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	return m.City.Code, true, nil
+}
+
+// unknownCity — ошибка для города не из справочника с подсказкой ближайшего
+func (s *Service) unknownCity(name string) error {
+	if hint := s.locations.Search(name, 1); len(hint) > 0 {
+		return fmt.Errorf("unknown city %q, did you mean %s?", name, hint[0].City.Code)
+	}
+	return fmt.Errorf("unknown city %q", name)
+}
+
+func (r Route) validate(v *ValidationError) {
+	r.Origin.validate(v, "route.origin")
+	if len(r.Waypoints) > MaxWaypoints {
+		v.add("route.waypoints", "too many waypoints (max %d)", MaxWaypoints)
+	}
+	for i, w := range r.Waypoints {
+		w.validate(v, fmt.Sprintf("route.waypoints[%d]", i))
+	}
+	r.Destination.validate(v, "route.destination")
+	if len(r.String()) > 255 {
+		v.add("route", "too long (max 255 chars)")
+	}
+}
+
+func (st Stop) validate(v *ValidationError, field string) {
+	if st.CityCode == "" && !v.has(field+".city") {
+		v.add(field+".city", "is required")
+	}
+	if len(st.Address) > 255 {
+		v.add(field+".address", "too long (max 255 chars)")
+	}
+	if len(st.Contact.Name) > 255 {
+		v.add(field+".contact.name", "too long (max 255 chars)")
+	}
+	if len(st.Contact.Phone) > 32 {
+		v.add(field+".contact.phone", "too long (max 32 chars)")
+	}
+	validateCoordinates(v, field+".coordinates", st.Coordinates)
+}
+
+func (r Route) toRepo() []repo.Stop {
+	stops := r.Stops()
+	out := make([]repo.Stop, len(stops))
+	for i, st := range stops {
+		out[i] = repo.Stop{
+			Seq:          i,
+			Kind:         string(st.Kind),
+			CityCode:     st.CityCode,
+			Address:      st.Address,
+			ContactName:  st.Contact.Name,
+			ContactPhone: st.Contact.Phone,
+		}
+		if c := st.Coordinates; c != nil {
+			out[i].Latitude = &c.Latitude
+			out[i].Longitude = &c.Longitude
+		}
+	}
+	return out
+}
+
+// toRoute собирает маршрут из точек БД; ok=false для старых записей без точек
+func toRoute(stops []repo.Stop) (r Route, ok bool) {
+	var hasOrigin, hasDestination bool
+	for _, s := range stops {
+		st := Stop{
+			Kind:     StopKind(s.Kind),
+			CityCode: s.CityCode,
+			Address:  s.Address,
+			Contact:  Contact{Name: s.ContactName, Phone: s.ContactPhone},
+		}
+		if s.Latitude != nil && s.Longitude != nil {
+			st.Coordinates = &Coordinates{Latitude: *s.Latitude, Longitude: *s.Longitude}
+		}
+		switch st.Kind {
+		case StopOrigin:
+			r.Origin, hasOrigin = st, true
+		case StopDestination:
+			r.Destination, hasDestination = st, true
+		default:
+			r.Waypoints = append(r.Waypoints, st)
+		}
+	}
+	return r, hasOrigin && hasDestination
+}
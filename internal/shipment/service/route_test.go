@@ -0,0 +1,62 @@
+package service
+
+import (
+	"testing"
+
+	"transline.kz/internal/location"
+)
+
+func TestRouteFromInputCities(t *testing.T) {
+	s := &Service{locations: location.Default()}
+	v := newValidation()
+	r := s.routeFromInput(v, Route{}, "Алматы→ Esik →Shu")
+	if err := v.err(); err != nil {
+		t.Fatalf("routeFromInput error = %v", err)
+	}
+
+	want := []struct {
+		code       string
+		unresolved bool
+	}{
+		{"ALMATY", false},
+		{"ESIK", true},
+		{"SHU", true},
+	}
+	stops := r.Stops()
+	if len(stops) != len(want) {
+		t.Fatalf("stops = %+v", stops)
+	}
+	for i, w := range want {
+		if stops[i].CityCode != w.code || stops[i].Unresolved != w.unresolved {
+			t.Errorf("stop %d = %s (unresolved %v), want %s (unresolved %v)",
+				i, stops[i].CityCode, stops[i].Unresolved, w.code, w.unresolved)
+		}
+	}
+
+	// для цены нужны координаты городов
+	s.requireKnownCities(v, &r)
+	if !v.has("route.waypoints[0].city") || !v.has("route.destination.city") || v.has("route.origin.city") {
+		t.Errorf("requireKnownCities: %v", v.err())
+	}
+}
+
+func TestRouteFromInputAmbiguousCity(t *testing.T) {
+	s := &Service{locations: location.Default()}
+	v := newValidation()
+	s.routeFromInput(v, Route{}, "Aksuy→ALMATY")
+	if !v.has("route.origin.city") {
+		t.Errorf("ambiguous origin accepted: %v", v.err())
+	}
+}
+
+// Пустой сегмент старого формата — нарушение, как и в переносе миграции 011
+func TestRouteFromInputEmptySegment(t *testing.T) {
+	s := &Service{locations: location.Default()}
+	for _, text := range []string{"ALMATY→→ASTANA", "ALMATY-> ->ASTANA", "ALMATY→", "ALMATY"} {
+		v := newValidation()
+		s.routeFromInput(v, Route{}, text)
+		if v.err() == nil {
+			t.Errorf("routeFromInput(%q) accepted", text)
+		}
+	}
+}
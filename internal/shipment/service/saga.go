@@ -0,0 +1,198 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "transline.kz/api/proto/customerpb"
+	"transline.kz/internal/shipment/repo"
+)
+
+const (
+	customerCallTimeout  = 2 * time.Second
+	customerCallAttempts = 3
+	compensationTimeout  = 5 * time.Second
+)
+
+// sagaStore — шаги саги создания в БД; *repo.Repo
+type sagaStore interface {
+	SagaCustomerUpserted(ctx context.Context, id, customerID uuid.UUID, created bool) error
+	CompleteSaga(ctx context.Context, id uuid.UUID) (*repo.Shipment, error)
+	BeginSagaCompensation(ctx context.Context, id uuid.UUID, cause string) (*repo.Saga, error)
+	FinishSagaCompensation(ctx context.Context, id uuid.UUID) error
+	RecordSagaError(ctx context.Context, id uuid.UUID, cause string) error
+	StaleSagas(ctx context.Context, olderThan time.Duration, limit int) ([]repo.Saga, error)
+}
+
+// sagaCustomers — вызовы customer-service, которые делает сага; *shgrpc.Client
+type sagaCustomers interface {
+	UpsertCustomer(ctx context.Context, idn, requestID string) (*pb.CustomerResponse, error)
+	CompensateUpsertCustomer(ctx context.Context, requestID string) (bool, error)
+}
+
+// runCreateSaga выполняет шаги saga создания отправления:
+//
+//  1. UpsertCustomer в customer-service (request_id = id саги)
+//  2. INSERT shipment + COMPLETED в одной транзакции
+//
+// При любом сбое сага переводится в COMPENSATING и клиент, созданный на шаге 1,
+// удаляется. Если компенсация не удалась, сагу доведёт RecoverSagas.
+func (s *Service) runCreateSaga(ctx context.Context, sg *repo.Saga) (*repo.Shipment, error) {
+	log := slog.With("saga_id", sg.ID)
+
+	// Клиента могла удалить компенсация параллельной саги между шагами —
+	// тогда шаг 1 повторяется один раз
+	for attempt := 0; ; attempt++ {
+		cus, err := s.upsertCustomer(ctx, sg.IDN, sg.ID)
+		if err != nil {
+			s.compensate(ctx, sg.ID, err)
+			return nil, fmt.Errorf("failed to upsert customer: %w", customerError(err))
+		}
+
+		customerID, err := uuid.Parse(cus.Id)
+		if err != nil {
+			s.compensate(ctx, sg.ID, err)
+			return nil, fmt.Errorf("invalid customer id format: %w", err)
+		}
+
+		if err := s.sagas.SagaCustomerUpserted(ctx, sg.ID, customerID, cus.Created); err != nil {
+			s.compensate(ctx, sg.ID, err)
+			return nil, fmt.Errorf("failed to record saga step: %w", storageError(err))
+		}
+		sagaStep(ctx, sg.ID, repo.SagaCustomerUpserted, attribute.Bool("customer.created", cus.Created))
+
+		sh, err := s.sagas.CompleteSaga(ctx, sg.ID)
+		if errors.Is(err, repo.ErrCustomerMissing) && attempt == 0 {
+			log.Warn("customer vanished between saga steps, retrying upsert")
+			continue
+		}
+		if err != nil {
+			s.compensate(ctx, sg.ID, err)
+			return nil, fmt.Errorf("failed to create shipment: %w", storageError(err))
+		}
+		sagaStep(ctx, sg.ID, repo.SagaCompleted)
+		return sh, nil
+	}
+}
+
+// upsertCustomer вызывает customer-service с таймаутом и повторами при недоступности.
+// UpsertCustomer идемпотентен по (idn, request_id), так что повтор безопасен.
+func (s *Service) upsertCustomer(ctx context.Context, idn string, requestID uuid.UUID) (*pb.CustomerResponse, error) {
+	var resp *pb.CustomerResponse
+	err := callWithRetry(ctx, customerCallTimeout, func(ctx context.Context) error {
+		var err error
+		resp, err = s.sagaCustomers.UpsertCustomer(ctx, idn, requestID.String())
+		return err
+	})
+	return resp, err
+}
+
+// callWithRetry выполняет идемпотентный вызов customer-service с таймаутом
+// на попытку и экспоненциальной паузой между повторами
+func callWithRetry(ctx context.Context, timeout time.Duration, call func(context.Context) error) error {
+	backoff := 100 * time.Millisecond
+	for attempt := 1; ; attempt++ {
+		grpcCtx, cancel := context.WithTimeout(ctx, timeout)
+		err := call(grpcCtx)
+		cancel()
+		if err == nil {
+			return nil
+		}
+		if attempt == customerCallAttempts || !retryable(err) {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+func retryable(err error) bool {
+	switch status.Code(err) {
+	case codes.Unavailable, codes.ResourceExhausted, codes.Aborted:
+		return true
+	}
+	return false
+}
+
+// compensate откатывает шаг 1. Выполняется и при отменённом ctx клиента,
+// чтобы не оставлять полусозданное состояние.
+func (s *Service) compensate(ctx context.Context, id uuid.UUID, cause error) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), compensationTimeout)
+	defer cancel()
+	log := slog.With("saga_id", id)
+
+	sg, err := s.sagas.BeginSagaCompensation(ctx, id, cause.Error())
+	if errors.Is(err, repo.ErrSagaConflict) {
+		// сагу уже завершили или компенсировали
+		return
+	}
+	if err != nil {
+		log.Error("failed to begin saga compensation", "err", err, "cause", cause)
+		return
+	}
+	sagaStep(ctx, id, repo.SagaCompensating, attribute.String("saga.cause", cause.Error()))
+
+	if err := s.compensateCustomer(ctx, sg); err != nil {
+		log.Error("saga compensation failed, will be retried", "err", err, "cause", cause)
+		if err := s.sagas.RecordSagaError(ctx, id, err.Error()); err != nil {
+			log.Error("failed to record saga error", "err", err)
+		}
+		return
+	}
+	log.Warn("shipment saga compensated", "cause", cause)
+}
+
+func (s *Service) compensateCustomer(ctx context.Context, sg *repo.Saga) error {
+	// Шаг 1 точно не создавал клиента — откатывать нечего
+	skip := sg.CustomerID != nil && !sg.CustomerCreated
+	if !skip {
+		grpcCtx, cancel := context.WithTimeout(ctx, customerCallTimeout)
+		deleted, err := s.sagaCustomers.CompensateUpsertCustomer(grpcCtx, sg.ID.String())
+		cancel()
+		if err != nil {
+			return fmt.Errorf("failed to compensate customer upsert: %w", err)
+		}
+		sagaStep(ctx, sg.ID, "CUSTOMER_COMPENSATED", attribute.Bool("customer.deleted", deleted))
+	}
+
+	if err := s.sagas.FinishSagaCompensation(ctx, sg.ID); err != nil && !errors.Is(err, repo.ErrSagaConflict) {
+		return err
+	}
+	sagaStep(ctx, sg.ID, repo.SagaCompensated)
+	return nil
+}
+
+// RecoverSagas компенсирует саги, зависшие дольше staleAfter (упал инстанс,
+// не ответил customer-service и т.п.). Возвращает число обработанных саг.
+func (s *Service) RecoverSagas(ctx context.Context, staleAfter time.Duration, limit int) (int, error) {
+	sagas, err := s.sagas.StaleSagas(ctx, staleAfter, limit)
+	if err != nil {
+		return 0, fmt.Errorf("failed to load stale sagas: %w", err)
+	}
+
+	n := 0
+	for _, sg := range sagas {
+		s.compensate(ctx, sg.ID, fmt.Errorf("saga stuck in %s since %s", sg.State, sg.UpdatedAt.Format(time.RFC3339)))
+		n++
+	}
+	return n, nil
+}
+
+func sagaStep(ctx context.Context, id uuid.UUID, step string, attrs ...attribute.KeyValue) {
+	attrs = append(attrs, attribute.String("saga.id", id.String()), attribute.String("saga.step", step))
+	trace.SpanFromContext(ctx).AddEvent("shipment.saga", trace.WithAttributes(attrs...))
+}
@@ -0,0 +1,365 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "transline.kz/api/proto/customerpb"
+	"transline.kz/internal/shipment/repo"
+)
+
+// fakeSagaStore хранит саги в памяти и пишет вызовы в calls
+type fakeSagaStore struct {
+	sagas map[uuid.UUID]*repo.Saga
+	calls []string
+
+	upsertedErr error
+	// completeErrs — ошибки CompleteSaga по очереди вызовов; nil — успех
+	completeErrs []error
+	finishErr    error
+	staleErr     error
+	errors       []string
+}
+
+func newFakeSagaStore(sagas ...repo.Saga) *fakeSagaStore {
+	f := &fakeSagaStore{sagas: make(map[uuid.UUID]*repo.Saga)}
+	for _, sg := range sagas {
+		f.sagas[sg.ID] = &sg
+	}
+	return f
+}
+
+func (f *fakeSagaStore) SagaCustomerUpserted(_ context.Context, id, customerID uuid.UUID, created bool) error {
+	f.calls = append(f.calls, "SagaCustomerUpserted")
+	if f.upsertedErr != nil {
+		return f.upsertedErr
+	}
+	sg := f.sagas[id]
+	sg.State, sg.CustomerID, sg.CustomerCreated = repo.SagaCustomerUpserted, &customerID, created
+	return nil
+}
+
+func (f *fakeSagaStore) CompleteSaga(_ context.Context, id uuid.UUID) (*repo.Shipment, error) {
+	f.calls = append(f.calls, "CompleteSaga")
+	if len(f.completeErrs) > 0 {
+		err := f.completeErrs[0]
+		f.completeErrs = f.completeErrs[1:]
+		if err != nil {
+			return nil, err
+		}
+	}
+	sg := f.sagas[id]
+	sg.State = repo.SagaCompleted
+	return &repo.Shipment{ID: id, CustomerID: *sg.CustomerID}, nil
+}
+
+func (f *fakeSagaStore) BeginSagaCompensation(_ context.Context, id uuid.UUID, cause string) (*repo.Saga, error) {
+	f.calls = append(f.calls, "BeginSagaCompensation")
+	sg := f.sagas[id]
+	if sg.State != repo.SagaStarted && sg.State != repo.SagaCustomerUpserted && sg.State != repo.SagaCompensating {
+		return nil, repo.ErrSagaConflict
+	}
+	sg.State, sg.LastError = repo.SagaCompensating, cause
+	out := *sg
+	return &out, nil
+}
+
+func (f *fakeSagaStore) FinishSagaCompensation(_ context.Context, id uuid.UUID) error {
+	f.calls = append(f.calls, "FinishSagaCompensation")
+	if f.finishErr != nil {
+		return f.finishErr
+	}
+	f.sagas[id].State = repo.SagaCompensated
+	return nil
+}
+
+func (f *fakeSagaStore) RecordSagaError(_ context.Context, _ uuid.UUID, cause string) error {
+	f.calls = append(f.calls, "RecordSagaError")
+	f.errors = append(f.errors, cause)
+	return nil
+}
+
+func (f *fakeSagaStore) StaleSagas(context.Context, time.Duration, int) ([]repo.Saga, error) {
+	if f.staleErr != nil {
+		return nil, f.staleErr
+	}
+	var out []repo.Saga
+	for _, sg := range f.sagas {
+		if sg.State == repo.SagaStarted || sg.State == repo.SagaCustomerUpserted || sg.State == repo.SagaCompensating {
+			out = append(out, *sg)
+		}
+	}
+	return out, nil
+}
+
+// fakeCustomers — customer-service: upsertErrs возвращаются по очереди, потом успех
+type fakeCustomers struct {
+	customerID    string
+	created       bool
+	upsertErrs    []error
+	upsertCalls   int
+	compensateErr error
+	compensated   []string
+}
+
+func (f *fakeCustomers) UpsertCustomer(_ context.Context, _, _ string) (*pb.CustomerResponse, error) {
+	f.upsertCalls++
+	if len(f.upsertErrs) > 0 {
+		err := f.upsertErrs[0]
+		f.upsertErrs = f.upsertErrs[1:]
+		return nil, err
+	}
+	return &pb.CustomerResponse{Id: f.customerID, Created: f.created}, nil
+}
+
+func (f *fakeCustomers) CompensateUpsertCustomer(_ context.Context, requestID string) (bool, error) {
+	if f.compensateErr != nil {
+		return false, f.compensateErr
+	}
+	f.compensated = append(f.compensated, requestID)
+	return true, nil
+}
+
+func newSagaService(store *fakeSagaStore, customers *fakeCustomers) *Service {
+	return &Service{sagas: store, sagaCustomers: customers}
+}
+
+func startedSaga() repo.Saga {
+	return repo.Saga{ID: uuid.New(), IDN: "900101300127", State: repo.SagaStarted}
+}
+
+func TestRunCreateSaga(t *testing.T) {
+	unavailable := status.Error(codes.Unavailable, "connection refused")
+
+	tests := []struct {
+		name      string
+		store     func(*fakeSagaStore)
+		customers func(*fakeCustomers)
+		wantErr   error
+		// wantState — состояние саги после вызова
+		wantState       string
+		wantUpserts     int
+		wantCompensated bool
+	}{
+		{
+			name:        "success",
+			wantState:   repo.SagaCompleted,
+			wantUpserts: 1,
+		},
+		{
+			name:        "transient upsert failure is retried",
+			customers:   func(c *fakeCustomers) { c.upsertErrs = []error{unavailable} },
+			wantState:   repo.SagaCompleted,
+			wantUpserts: 2,
+		},
+		{
+			name: "upsert retries exhausted",
+			customers: func(c *fakeCustomers) {
+				c.upsertErrs = []error{unavailable, unavailable, unavailable}
+			},
+			wantErr:         ErrCustomerServiceUnavailable,
+			wantState:       repo.SagaCompensated,
+			wantUpserts:     customerCallAttempts,
+			wantCompensated: true,
+		},
+		{
+			name: "upsert rejected",
+			customers: func(c *fakeCustomers) {
+				c.upsertErrs = []error{status.Error(codes.InvalidArgument, "bad idn")}
+			},
+			wantErr:         ErrValidation,
+			wantState:       repo.SagaCompensated,
+			wantUpserts:     1,
+			wantCompensated: true,
+		},
+		{
+			name:            "invalid customer id",
+			customers:       func(c *fakeCustomers) { c.customerID = "not-a-uuid" },
+			wantState:       repo.SagaCompensated,
+			wantUpserts:     1,
+			wantCompensated: true,
+		},
+		{
+			name:            "customer upserted step conflict",
+			store:           func(s *fakeSagaStore) { s.upsertedErr = repo.ErrSagaConflict },
+			wantErr:         repo.ErrSagaConflict,
+			wantState:       repo.SagaCompensated,
+			wantUpserts:     1,
+			wantCompensated: true,
+		},
+		{
+			name:            "complete fails",
+			store:           func(s *fakeSagaStore) { s.completeErrs = []error{errors.New("insert failed")} },
+			wantState:       repo.SagaCompensated,
+			wantUpserts:     1,
+			wantCompensated: true,
+		},
+		{
+			name:        "customer missing is retried once",
+			store:       func(s *fakeSagaStore) { s.completeErrs = []error{repo.ErrCustomerMissing} },
+			wantState:   repo.SagaCompleted,
+			wantUpserts: 2,
+		},
+		{
+			name: "customer missing twice",
+			store: func(s *fakeSagaStore) {
+				s.completeErrs = []error{repo.ErrCustomerMissing, repo.ErrCustomerMissing}
+			},
+			wantErr:         repo.ErrCustomerMissing,
+			wantState:       repo.SagaCompensated,
+			wantUpserts:     2,
+			wantCompensated: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sg := startedSaga()
+			store := newFakeSagaStore(sg)
+			customers := &fakeCustomers{customerID: uuid.NewString(), created: true}
+			if tt.store != nil {
+				tt.store(store)
+			}
+			if tt.customers != nil {
+				tt.customers(customers)
+			}
+
+			sh, err := newSagaService(store, customers).runCreateSaga(context.Background(), &sg)
+			wantFail := tt.wantErr != nil || tt.wantCompensated
+			switch {
+			case wantFail && err == nil:
+				t.Fatalf("runCreateSaga() succeeded, want error")
+			case !wantFail && err != nil:
+				t.Fatalf("runCreateSaga() error = %v", err)
+			case tt.wantErr != nil && !errors.Is(err, tt.wantErr):
+				t.Errorf("runCreateSaga() error = %v, want %v", err, tt.wantErr)
+			case !wantFail && sh.ID != sg.ID:
+				t.Errorf("shipment id = %s, want saga id %s", sh.ID, sg.ID)
+			}
+
+			if got := store.sagas[sg.ID].State; got != tt.wantState {
+				t.Errorf("saga state = %s, want %s", got, tt.wantState)
+			}
+			if customers.upsertCalls != tt.wantUpserts {
+				t.Errorf("UpsertCustomer calls = %d, want %d", customers.upsertCalls, tt.wantUpserts)
+			}
+			if got := len(customers.compensated) > 0; got != tt.wantCompensated {
+				t.Errorf("customer compensated = %v, want %v", got, tt.wantCompensated)
+			}
+		})
+	}
+}
+
+func TestCompensate(t *testing.T) {
+	customerID := uuid.New()
+
+	tests := []struct {
+		name string
+		saga func(*repo.Saga)
+		// compensateErr — ошибка CompensateUpsertCustomer
+		compensateErr   error
+		wantState       string
+		wantCompensated bool
+		wantCalls       []string
+	}{
+		{
+			name: "customer created by saga is deleted",
+			saga: func(sg *repo.Saga) {
+				sg.State, sg.CustomerID, sg.CustomerCreated = repo.SagaCustomerUpserted, &customerID, true
+			},
+			wantState:       repo.SagaCompensated,
+			wantCompensated: true,
+			wantCalls:       []string{"BeginSagaCompensation", "FinishSagaCompensation"},
+		},
+		{
+			name:      "existing customer is kept",
+			saga:      func(sg *repo.Saga) { sg.State, sg.CustomerID = repo.SagaCustomerUpserted, &customerID },
+			wantState: repo.SagaCompensated,
+			wantCalls: []string{"BeginSagaCompensation", "FinishSagaCompensation"},
+		},
+		{
+			// шаг 1 мог выполниться, но ответ не дошёл — удаление по request_id
+			name:            "started saga compensates by request id",
+			wantState:       repo.SagaCompensated,
+			wantCompensated: true,
+			wantCalls:       []string{"BeginSagaCompensation", "FinishSagaCompensation"},
+		},
+		{
+			name:          "compensation failure is recorded",
+			compensateErr: status.Error(codes.Unavailable, "down"),
+			wantState:     repo.SagaCompensating,
+			wantCalls:     []string{"BeginSagaCompensation", "RecordSagaError"},
+		},
+		{
+			name:      "completed saga is left alone",
+			saga:      func(sg *repo.Saga) { sg.State = repo.SagaCompleted },
+			wantState: repo.SagaCompleted,
+			wantCalls: []string{"BeginSagaCompensation"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sg := startedSaga()
+			if tt.saga != nil {
+				tt.saga(&sg)
+			}
+			store := newFakeSagaStore(sg)
+			customers := &fakeCustomers{compensateErr: tt.compensateErr}
+
+			newSagaService(store, customers).compensate(context.Background(), sg.ID, errors.New("boom"))
+
+			if got := store.sagas[sg.ID].State; got != tt.wantState {
+				t.Errorf("saga state = %s, want %s", got, tt.wantState)
+			}
+			if got := len(customers.compensated) > 0; got != tt.wantCompensated {
+				t.Errorf("customer compensated = %v, want %v", got, tt.wantCompensated)
+			}
+			if !slices.Equal(store.calls, tt.wantCalls) {
+				t.Errorf("store calls = %v, want %v", store.calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestRecoverSagas(t *testing.T) {
+	customerID := uuid.New()
+	started := startedSaga()
+	upserted := startedSaga()
+	upserted.State, upserted.CustomerID, upserted.CustomerCreated = repo.SagaCustomerUpserted, &customerID, true
+	completed := startedSaga()
+	completed.State = repo.SagaCompleted
+
+	store := newFakeSagaStore(started, upserted, completed)
+	customers := &fakeCustomers{}
+	n, err := newSagaService(store, customers).RecoverSagas(context.Background(), time.Minute, 10)
+	if err != nil {
+		t.Fatalf("RecoverSagas() error = %v", err)
+	}
+	if n != 2 {
+		t.Errorf("RecoverSagas() = %d, want 2", n)
+	}
+	for _, sg := range []repo.Saga{started, upserted} {
+		if got := store.sagas[sg.ID].State; got != repo.SagaCompensated {
+			t.Errorf("saga %s state = %s, want %s", sg.ID, got, repo.SagaCompensated)
+		}
+		if !slices.Contains(customers.compensated, sg.ID.String()) {
+			t.Errorf("saga %s: customer not compensated", sg.ID)
+		}
+	}
+	if got := store.sagas[completed.ID].State; got != repo.SagaCompleted {
+		t.Errorf("completed saga state = %s, want %s", got, repo.SagaCompleted)
+	}
+
+	store.staleErr = errors.New("db down")
+	if _, err := newSagaService(store, customers).RecoverSagas(context.Background(), time.Minute, 10); err == nil {
+		t.Error("RecoverSagas() with storage error succeeded, want error")
+	}
+}
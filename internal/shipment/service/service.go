@@ -4,10 +4,13 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"regexp"
+	"net/http"
 	"time"
 
 	"github.com/google/uuid"
+	"transline.kz/internal/idn"
+	"transline.kz/internal/location"
+	"transline.kz/internal/money"
 	shgrpc "transline.kz/internal/shipment/grpc"
 	"transline.kz/internal/shipment/repo"
 )
@@ -15,28 +18,142 @@ import (
 type Service struct {
 	repo         *repo.Repo
 	customerGRPC *shgrpc.Client
+	locations    *location.Directory
+	cfg          Config
+	// sagas и sagaCustomers — те же repo и customerGRPC за узкими
+	// интерфейсами, чтобы сагу можно было проверить без БД и customer-service
+	sagas         sagaStore
+	sagaCustomers sagaCustomers
+	// webhookClient не следует редиректам (3xx — неудачная попытка) и не
+	// соединяется с внутренними адресами
+	webhookClient *http.Client
 }
 
+// Config — настраиваемые параметры сервиса; нулевые поля заменяются значениями по умолчанию
+type Config struct {
+	// QuoteTTL — срок действия котировки
+	QuoteTTL time.Duration
+	// TrackingURL — префикс ссылки на публичное отслеживание для QR-кода на
+	// этикетке (трек-номер дописывается в конец); пустой — в QR только номер
+	TrackingURL string
+	// CancelGracePeriod — бесплатная отмена неподтверждённого отправления после создания
+	CancelGracePeriod time.Duration
+	// WebhookTimeout — ожидание ответа подписчика на одну попытку
+	WebhookTimeout time.Duration
+	// WebhookMaxAttempts — после стольких неудачных попыток доставка становится DEAD
+	WebhookMaxAttempts int
+	// AllowInsecureWebhooks разрешает http:// адреса подписок (локальная разработка)
+	AllowInsecureWebhooks bool
+	// AllowPrivateWebhooks разрешает доставку на localhost и внутренние адреса
+	// (локальная разработка)
+	AllowPrivateWebhooks bool
+}
+
+const DefaultQuoteTTL = 30 * time.Minute
+
 func New(
 	repo *repo.Repo,
 	customerGRPC *shgrpc.Client,
+	locations *location.Directory,
+	cfg Config,
 ) *Service {
+	if cfg.QuoteTTL <= 0 {
+		cfg.QuoteTTL = DefaultQuoteTTL
+	}
+	if cfg.CancelGracePeriod <= 0 {
+		cfg.CancelGracePeriod = DefaultCancelGracePeriod
+	}
+	if cfg.WebhookTimeout <= 0 {
+		cfg.WebhookTimeout = DefaultWebhookTimeout
+	}
+	if cfg.WebhookMaxAttempts <= 0 {
+		cfg.WebhookMaxAttempts = DefaultWebhookMaxAttempts
+	}
 	return &Service{
-		repo:         repo,
-		customerGRPC: customerGRPC,
+		repo:          repo,
+		customerGRPC:  customerGRPC,
+		locations:     locations,
+		cfg:           cfg,
+		sagas:         repo,
+		sagaCustomers: customerGRPC,
+		webhookClient: newWebhookClient(cfg),
+	}
+}
+
+var (
+	ErrShipmentNotFound = errors.New("shipment not found")
+	ErrStatusConflict   = errors.New("shipment status was changed concurrently")
+	// ErrInvalidTrackingNumber — ввод не является трек-номером (формат или контрольная цифра)
+	ErrInvalidTrackingNumber = errors.New("invalid tracking number")
+)
+
+// Shipment — отправление в терминах сервиса
+type Shipment struct {
+	ID             uuid.UUID
+	TrackingNumber string
+	// RouteText — маршрут строкой ("ALMATY→ASTANA"), Route — по точкам;
+	// у старых записей, чей маршрут не удалось разобрать, Route пустой
+	RouteText  string
+	Route      Route
+	Cargo      Cargo
+	Price      money.Money
+	Status     Status
+	CustomerID uuid.UUID
+	CreatedAt  time.Time
+	// Version — для ETag/If-Match; растёт при каждом изменении
+	Version int64
+	// Cancellation — причина и сбор, если отправление отменено через Cancel
+	Cancellation *Cancellation
+}
+
+func (s *Service) toShipment(sh *repo.Shipment) (*Shipment, error) {
+	cargo, err := toCargo(sh.Items, sh.Price.Currency())
+	if err != nil {
+		return nil, fmt.Errorf("shipment %s: %w", sh.ID, err)
+	}
+	out := &Shipment{
+		ID:             sh.ID,
+		TrackingNumber: sh.TrackingNumber,
+		RouteText:      sh.Route,
+		Price:          sh.Price,
+		Status:         Status(sh.Status),
+		Cargo:          cargo,
+		CustomerID:     sh.CustomerID,
+		CreatedAt:      sh.CreatedAt,
+		Version:        sh.Version,
+		Cancellation:   toCancellation(sh.Cancellation),
 	}
+	if r, ok := toRoute(sh.Stops); ok {
+		s.markUnresolved(&r)
+		out.Route = r
+	}
+	return out, nil
 }
 
 type CreateShipmentInput struct {
-	Route string
-	Price float64
+	// Route — структурированный маршрут; RouteText — старый строковый формат,
+	// используется, если Route не задан
+	Route     Route
+	RouteText string
+	// Price — десятичная строка ("120000.50"), Currency — ISO 4217, по умолчанию KZT
+	Price    string
+	Currency string
+	// QuoteID — котировка вместо Price: цена (и маршрут, если он не задан) берутся из неё
+	QuoteID *uuid.UUID
+	// Items — грузовые места; необязательны, но с котировкой не могут превышать её вес и объём
+	Items []ItemInput
 	IDN   string
+	Actor string
 }
 
+// maxPrice — верхняя граница цены в основных единицах валюты
+const maxPrice = "10000000000"
+
 type CreateShipmentResult struct {
-	ID         uuid.UUID
-	Status     string
-	CustomerID uuid.UUID
+	ID             uuid.UUID
+	TrackingNumber string
+	Status         string
+	CustomerID     uuid.UUID
 }
 
 func (s *Service) CreateShipment(
@@ -44,56 +161,170 @@ func (s *Service) CreateShipment(
 	in CreateShipmentInput,
 ) (*CreateShipmentResult, error) {
 
-	// Бизнес-валидация
-	if in.Route == "" {
-		return nil, errors.New("route is required")
+	saga, err := s.prepareShipment(ctx, in)
+	if err != nil {
+		return nil, err
 	}
 
-	if len(in.Route) > 255 {
-		return nil, errors.New("route is too long (max 255 chars)")
+	// Двухшаговое создание через saga: при сбое второго шага клиент,
+	// созданный первым, компенсируется
+	sg, err := s.repo.StartSaga(ctx, saga)
+	if errors.Is(err, repo.ErrQuoteUnavailable) {
+		return nil, invalidField("quoteId", "quote has expired or was already used")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to start shipment saga: %w", storageError(err))
 	}
 
-	if in.Price <= 0 {
-		return nil, errors.New("price must be positive")
+	sh, err := s.runCreateSaga(ctx, sg)
+	if err != nil {
+		return nil, err
 	}
 
-	if in.Price > 1e10 {
-		return nil, errors.New("price is too high")
+	// Возврат результата
+	return &CreateShipmentResult{
+		ID:             sh.ID,
+		TrackingNumber: sh.TrackingNumber,
+		Status:         sh.Status,
+		CustomerID:     sh.CustomerID,
+	}, nil
+}
+
+// prepareShipment проверяет входные данные по правилам создания и собирает
+// сагу; нарушения возвращаются одной ValidationError
+func (s *Service) prepareShipment(ctx context.Context, in CreateShipmentInput) (repo.Saga, error) {
+	// Бизнес-валидация: собираем все нарушения сразу
+	v := newValidation()
+	var (
+		route Route
+		price money.Money
+	)
+	var quote *repo.Quote
+	if in.QuoteID != nil {
+		q, err := s.usableQuote(ctx, v, *in.QuoteID)
+		if err != nil {
+			return repo.Saga{}, err
+		}
+		if quote = q; q != nil {
+			route, price = s.routeForQuote(v, in.Route, in.RouteText, q), q.Price
+			if in.Price != "" {
+				v.add("price", "must not be set together with quoteId")
+			}
+			if cur, err := money.ParseCurrency(in.Currency); in.Currency != "" && (err != nil || cur != price.Currency()) {
+				v.add("currency", "must match the quote currency %s", price.Currency())
+			}
+		}
+	} else {
+		route = s.routeFromInput(v, in.Route, in.RouteText)
+		var ok bool
+		price, ok = parsePrice(v, "price", in.Price, in.Currency)
+		if ok && !price.IsPositive() {
+			v.add("price", "must be positive")
+		}
 	}
 
-	if !regexp.MustCompile(`^\d{12}$`).MatchString(in.IDN) {
-		return nil, errors.New("invalid idn format (must be 12 digits)")
+	cur := price.Currency()
+	if cur == "" {
+		// цена не разобрана — ценность мест проверяется в валюте по умолчанию
+		cur = money.DefaultCurrency
 	}
+	cargo := parseCargo(v, in.Items, cur)
+	if quote != nil && len(in.Items) > 0 && !v.has("items") {
+		checkQuotedCargo(v, cargo, quote)
+	}
+
+	if err := idn.Validate(in.IDN); err != nil {
+		v.add("customer.idn", "%v", err)
+	}
+
+	if err := v.err(); err != nil {
+		return repo.Saga{}, err
+	}
+
+	return repo.Saga{
+		ID:      uuid.New(),
+		IDN:     in.IDN,
+		Route:   route.String(),
+		Stops:   route.toRepo(),
+		Items:   cargo.toRepo(),
+		Price:   price,
+		QuoteID: in.QuoteID,
+		Actor:   actorOrDefault(in.Actor),
+	}, nil
+}
 
-	// Upsert customer через gRPC (с таймаутом)
-	grpcCtx, cancel := context.WithTimeout(ctx, 2*time.Second)
-	defer cancel()
-	cus, err := s.customerGRPC.UpsertCustomer(grpcCtx, in.IDN)
+// parsePrice разбирает сумму и валюту, записывая нарушения в v;
+// ok=false, если сумму разобрать не удалось
+func parsePrice(v *ValidationError, field, amount, currency string) (money.Money, bool) {
+	cur, err := money.ParseCurrency(currency)
 	if err != nil {
-		return nil, fmt.Errorf("failed to upsert customer: %w", err)
+		v.add("currency", "%v", err)
+		return money.Money{}, false
 	}
+	if amount == "" {
+		v.add(field, "is required")
+		return money.Money{}, false
+	}
+	m, err := money.Parse(amount, cur)
+	if err != nil {
+		v.add(field, "%v", err)
+		return money.Money{}, false
+	}
+	if c, _ := m.Cmp(money.MustParse(maxPrice, cur)); c > 0 {
+		v.add(field, "too high (max %s)", maxPrice)
+		return m, false
+	}
+	return m, true
+}
+
+type TransitionInput struct {
+	ShipmentID uuid.UUID
+	To         Status
+	EventDetails
+}
 
-	// Parse customer ID from string to UUID
-	customerID, err := uuid.Parse(cus.Id)
+// Transition переводит отправление в новый статус по правилам жизненного цикла
+func (s *Service) Transition(
+	ctx context.Context,
+	in TransitionInput,
+) (*Shipment, error) {
+
+	v := newValidation()
+	if _, err := ParseStatus(string(in.To)); err != nil {
+		v.add("status", "unknown status %q", in.To)
+	}
+	if in.To == StatusCancelled {
+		// отмена требует причины и начисляет сбор — только через Cancel
+		v.add("status", "use the cancel endpoint to cancel a shipment")
+	}
+	in.validate(v)
+	if err := v.err(); err != nil {
+		return nil, err
+	}
+
+	current, err := s.repo.Get(ctx, in.ShipmentID)
+	if errors.Is(err, repo.ErrNotFound) {
+		return nil, ErrShipmentNotFound
+	}
 	if err != nil {
-		return nil, fmt.Errorf("invalid customer id format: %w", err)
+		return nil, fmt.Errorf("failed to load shipment: %w", storageError(err))
 	}
 
-	// Создание shipment
-	sh, err := s.repo.Create(
-		ctx,
-		customerID,
-		in.Route,
-		in.Price,
-	)
+	if err := checkTransition(Status(current.Status), in.To); err != nil {
+		return nil, err
+	}
+
+	// CAS по текущему статусу: параллельный переход вернёт ErrStatusConflict
+	ev := in.toRepo()
+	ev.StatusFrom = current.Status
+	ev.StatusTo = string(in.To)
+	sh, err := s.repo.UpdateStatus(ctx, in.ShipmentID, ev)
+	if errors.Is(err, repo.ErrStatusConflict) {
+		return nil, ErrStatusConflict
+	}
 	if err != nil {
-		return nil, fmt.Errorf("failed to create shipment: %w", err)
+		return nil, fmt.Errorf("failed to update shipment status: %w", storageError(err))
 	}
 
-	// Возврат результата
-	return &CreateShipmentResult{
-		ID:         sh.ID,
-		Status:     sh.Status,
-		CustomerID: sh.CustomerID,
-	}, nil
+	return s.toShipment(sh)
 }
@@ -0,0 +1,90 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Status — этап жизненного цикла отправления
+type Status string
+
+const (
+	StatusCreated        Status = "CREATED"
+	StatusConfirmed      Status = "CONFIRMED"
+	StatusPickedUp       Status = "PICKED_UP"
+	StatusInTransit      Status = "IN_TRANSIT"
+	StatusAtHub          Status = "AT_HUB"
+	StatusOutForDelivery Status = "OUT_FOR_DELIVERY"
+	StatusDelivered      Status = "DELIVERED"
+	StatusCancelled      Status = "CANCELLED"
+	StatusReturned       Status = "RETURNED"
+	StatusLost           Status = "LOST"
+)
+
+var (
+	ErrUnknownStatus     = errors.New("unknown shipment status")
+	ErrIllegalTransition = errors.New("illegal status transition")
+)
+
+// transitions — разрешённые переходы; статусы без записи терминальные
+var transitions = map[Status][]Status{
+	StatusCreated:        {StatusConfirmed, StatusCancelled},
+	StatusConfirmed:      {StatusPickedUp, StatusCancelled},
+	StatusPickedUp:       {StatusInTransit, StatusAtHub, StatusReturned, StatusLost},
+	StatusInTransit:      {StatusAtHub, StatusOutForDelivery, StatusReturned, StatusLost},
+	StatusAtHub:          {StatusInTransit, StatusOutForDelivery, StatusReturned, StatusLost},
+	StatusOutForDelivery: {StatusDelivered, StatusAtHub, StatusReturned, StatusLost},
+}
+
+var allStatuses = []Status{
+	StatusCreated,
+	StatusConfirmed,
+	StatusPickedUp,
+	StatusInTransit,
+	StatusAtHub,
+	StatusOutForDelivery,
+	StatusDelivered,
+	StatusCancelled,
+	StatusReturned,
+	StatusLost,
+}
+
+// ParseStatus проверяет, что строка — известный статус
+func ParseStatus(s string) (Status, error) {
+	for _, st := range allStatuses {
+		if string(st) == s {
+			return st, nil
+		}
+	}
+	return "", fmt.Errorf("%w: %q", ErrUnknownStatus, s)
+}
+
+// Terminal — из статуса нет ни одного перехода
+func (s Status) Terminal() bool {
+	return len(transitions[s]) == 0
+}
+
+// CanTransitionTo сообщает, разрешён ли переход s → next
+func (s Status) CanTransitionTo(next Status) bool {
+	for _, st := range transitions[s] {
+		if st == next {
+			return true
+		}
+	}
+	return false
+}
+
+// Next возвращает статусы, в которые можно перейти из s
+func (s Status) Next() []Status {
+	return append([]Status(nil), transitions[s]...)
+}
+
+func checkTransition(from, to Status) error {
+	if from.CanTransitionTo(to) {
+		return nil
+	}
+	if from.Terminal() {
+		return fmt.Errorf("%w: %s is terminal", ErrIllegalTransition, from)
+	}
+	return fmt.Errorf("%w: %s → %s (allowed: %v)", ErrIllegalTransition, from, to, transitions[from])
+}
@@ -0,0 +1,130 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"transline.kz/internal/location"
+	"transline.kz/internal/shipment/repo"
+	"transline.kz/internal/tracking"
+)
+
+// nearestCityKm — насколько далеко от города может быть скан по координатам,
+// чтобы считаться «в городе»
+const nearestCityKm = 50
+
+// PublicTracking — отслеживание для получателя без учётной записи: статус,
+// города и хронология. Цена, клиент, адреса, контакты, координаты и заметки
+// сюда не попадают.
+type PublicTracking struct {
+	TrackingNumber string
+	Status         Status
+	// Origin, Destination, Location — названия городов; Location — последний
+	// известный город, пустой, если неизвестен
+	Origin      string
+	Destination string
+	Location    string
+	UpdatedAt   time.Time
+	Events      []PublicEvent
+}
+
+// PublicEvent — смена статуса или скан; Location — город, если его удалось определить
+type PublicEvent struct {
+	Type       EventType
+	Status     Status
+	Location   string
+	OccurredAt time.Time
+}
+
+// Track — публичное отслеживание по трек-номеру
+func (s *Service) Track(ctx context.Context, number string) (*PublicTracking, error) {
+	number = tracking.Normalize(number)
+	if err := tracking.Validate(number); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidTrackingNumber, err)
+	}
+	id, err := s.repo.IDByTrackingNumber(ctx, number)
+	if errors.Is(err, repo.ErrNotFound) {
+		return nil, ErrShipmentNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up tracking number: %w", storageError(err))
+	}
+
+	sh, err := s.repo.Get(ctx, id)
+	if errors.Is(err, repo.ErrNotFound) {
+		return nil, ErrShipmentNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load shipment: %w", storageError(err))
+	}
+	events, err := s.repo.ListEvents(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list shipment events: %w", storageError(err))
+	}
+
+	out := &PublicTracking{
+		TrackingNumber: sh.TrackingNumber,
+		Status:         Status(sh.Status),
+		Origin:         s.cityName(sh.OriginCity),
+		Destination:    s.cityName(sh.DestinationCity),
+		UpdatedAt:      sh.CreatedAt,
+		Events:         make([]PublicEvent, 0, len(events)),
+	}
+	for _, e := range events {
+		if e.Type != repo.EventStatusChanged && e.Type != repo.EventLocationScan {
+			continue // заметки могут содержать что угодно
+		}
+		pe := PublicEvent{
+			Type:       EventType(e.Type),
+			Status:     Status(e.StatusTo),
+			OccurredAt: e.OccurredAt,
+		}
+		if c := s.coarseLocation(&e); c != nil {
+			pe.Location = c.Name
+			out.Location = c.Name
+		}
+		out.Events = append(out.Events, pe)
+		out.UpdatedAt = e.OccurredAt
+	}
+
+	// без сканов местоположение следует из статуса
+	if out.Location == "" {
+		switch out.Status {
+		case StatusCreated, StatusConfirmed:
+			out.Location = out.Origin
+		case StatusDelivered:
+			out.Location = out.Destination
+		}
+	}
+	return out, nil
+}
+
+// coarseLocation — город события: по названию места (или его части до
+// запятой), иначе ближайший к координатам. Точный адрес наружу не отдаётся.
+func (s *Service) coarseLocation(e *repo.Event) *location.City {
+	if e.Location != "" {
+		head, _, _ := strings.Cut(e.Location, ",")
+		for _, q := range []string{e.Location, head} {
+			if m, err := s.locations.Resolve(q); err == nil {
+				return m.City
+			}
+		}
+	}
+	if e.Latitude != nil && e.Longitude != nil {
+		if c, ok := s.locations.Nearest(*e.Latitude, *e.Longitude, nearestCityKm); ok {
+			return c
+		}
+	}
+	return nil
+}
+
+// cityName — название города по коду; для кода не из справочника — сам код
+func (s *Service) cityName(code string) string {
+	if c, ok := s.locations.City(code); ok {
+		return c.Name
+	}
+	return code
+}
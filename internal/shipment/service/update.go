@@ -0,0 +1,227 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"transline.kz/internal/shipment/repo"
+)
+
+// ErrVersionMismatch — отправление изменилось после того, как клиент его
+// прочитал (If-Match не совпал с текущей версией)
+var ErrVersionMismatch = errors.New("shipment was modified since it was read")
+
+// UpdateShipmentInput — изменения отправления; nil — поле не меняется
+type UpdateShipmentInput struct {
+	ShipmentID uuid.UUID
+	// Version — версия, которую видел клиент
+	Version  int64
+	Route    *Route
+	Price    *string
+	Currency *string
+	Items    *[]ItemInput
+}
+
+// Что и до какого статуса можно менять:
+//   - города маршрута, цену и валюту — пока отправление CREATED и создано
+//     без котировки (цена котировки рассчитана для её городов);
+//   - адреса и контакты отправителя и промежуточных точек, грузовые места —
+//     до забора груза (CREATED, CONFIRMED);
+//   - адрес и контакт получателя — пока отправление не в терминальном статусе.
+var (
+	editableBeforeConfirm = []Status{StatusCreated}
+	editableBeforePickup  = []Status{StatusCreated, StatusConfirmed}
+)
+
+// UpdateShipment применяет изменения, допустимые в текущем статусе, и
+// увеличивает версию. Если ничего не изменилось, возвращает отправление как есть.
+func (s *Service) UpdateShipment(ctx context.Context, in UpdateShipmentInput) (*Shipment, error) {
+	current, err := s.repo.Get(ctx, in.ShipmentID)
+	if errors.Is(err, repo.ErrNotFound) {
+		return nil, ErrShipmentNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load shipment: %w", storageError(err))
+	}
+	if current.Version != in.Version {
+		return nil, fmt.Errorf("%w: version is %d, not %d", ErrVersionMismatch, current.Version, in.Version)
+	}
+	sh, err := s.toShipment(current)
+	if err != nil {
+		return nil, err
+	}
+
+	v := newValidation()
+	status := sh.Status
+	var changed []string
+
+	stops, routeText := current.Stops, current.Route
+	citiesChanged := false
+	if in.Route != nil {
+		n := len(v.Fields)
+		r := s.routeFromInput(v, *in.Route, "")
+		if len(v.Fields) == n && !r.equal(sh.Route) {
+			checkRouteEdit(v, status, sh.Route, r)
+			citiesChanged = !sameCities(sh.Route, r)
+			stops, routeText = r.toRepo(), r.String()
+			changed = append(changed, "route")
+		}
+	}
+
+	price := sh.Price
+	if in.Price != nil || in.Currency != nil {
+		amount, currency := price.Amount(), string(price.Currency())
+		if in.Price != nil {
+			amount = *in.Price
+		}
+		if in.Currency != nil {
+			currency = *in.Currency
+		}
+		p, ok := parsePrice(v, "price", amount, currency)
+		if ok && !p.IsPositive() {
+			v.add("price", "must be positive")
+		}
+		if ok && p != price {
+			if !slices.Contains(editableBeforeConfirm, status) {
+				v.add("price", "cannot be changed in status %s", status)
+			}
+			price = p
+			changed = append(changed, "price")
+		}
+	}
+
+	items := current.Items
+	var newCargo *Cargo
+	if in.Items != nil {
+		n := len(v.Fields)
+		cargo := parseCargo(v, *in.Items, price.Currency())
+		if len(v.Fields) == n && !slices.Equal(cargo.toRepo(), current.Items) {
+			if !slices.Contains(editableBeforePickup, status) {
+				v.add("items", "cannot be changed in status %s", status)
+			}
+			items = cargo.toRepo()
+			newCargo = &cargo
+			changed = append(changed, "items")
+		}
+	} else if price.Currency() != sh.Price.Currency() {
+		// объявленная ценность мест хранится в валюте отправления
+		if sh.Cargo.DeclaredValue != nil {
+			v.add("currency", "cannot be changed while items have a declared value in %s", sh.Price.Currency())
+		}
+	}
+
+	if citiesChanged || price != sh.Price || newCargo != nil {
+		var priceFields []string
+		if price.Amount() != sh.Price.Amount() {
+			priceFields = append(priceFields, "price")
+		}
+		if price.Currency() != sh.Price.Currency() {
+			priceFields = append(priceFields, "currency")
+		}
+		if err := s.checkQuotedEdit(ctx, v, in.ShipmentID, citiesChanged, priceFields, newCargo); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := v.err(); err != nil {
+		return nil, err
+	}
+	if len(changed) == 0 {
+		return sh, nil
+	}
+
+	updated, err := s.repo.Update(ctx, in.ShipmentID, in.Version, repo.ShipmentUpdate{
+		Route: routeText,
+		Stops: stops,
+		Price: price,
+		Items: items,
+	}, repo.Event{
+		Type:  repo.EventNote,
+		Note:  "updated " + strings.Join(changed, ", "),
+		Actor: DefaultActor,
+	})
+	if errors.Is(err, repo.ErrVersionConflict) {
+		return nil, fmt.Errorf("%w: concurrent update", ErrVersionMismatch)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to update shipment: %w", storageError(err))
+	}
+	return s.toShipment(updated)
+}
+
+// checkQuotedEdit запрещает менять цену и города отправления, созданного по
+// котировке, и превышать её вес и объём: цена котировки рассчитана по тарифу
+// для её маршрута и груза
+func (s *Service) checkQuotedEdit(
+	ctx context.Context,
+	v *ValidationError,
+	id uuid.UUID,
+	cities bool,
+	priceFields []string,
+	cargo *Cargo,
+) error {
+	q, err := s.repo.ShipmentQuote(ctx, id)
+	if errors.Is(err, repo.ErrQuoteNotFound) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to load quote: %w", storageError(err))
+	}
+	if cities {
+		v.add("route", "cities cannot be changed: the price comes from quote %s", q.ID)
+	}
+	for _, f := range priceFields {
+		v.add(f, "cannot be changed: the price comes from quote %s", q.ID)
+	}
+	if cargo != nil {
+		checkQuotedCargo(v, *cargo, q)
+	}
+	return nil
+}
+
+func sameCities(from, to Route) bool {
+	return from.Origin.CityCode == to.Origin.CityCode &&
+		from.Destination.CityCode == to.Destination.CityCode &&
+		slices.EqualFunc(from.Waypoints, to.Waypoints, func(a, b Stop) bool { return a.CityCode == b.CityCode })
+}
+
+// checkRouteEdit проверяет, что изменённые части маршрута можно менять в статусе
+func checkRouteEdit(v *ValidationError, status Status, from, to Route) {
+	if !sameCities(from, to) {
+		if !slices.Contains(editableBeforeConfirm, status) {
+			v.add("route", "cities cannot be changed in status %s", status)
+		}
+		return
+	}
+
+	if !slices.Contains(editableBeforePickup, status) {
+		if !from.Origin.equal(to.Origin) {
+			v.add("route.origin", "cannot be changed in status %s", status)
+		}
+		if !slices.EqualFunc(from.Waypoints, to.Waypoints, Stop.equal) {
+			v.add("route.waypoints", "cannot be changed in status %s", status)
+		}
+	}
+	if status.Terminal() && !from.Destination.equal(to.Destination) {
+		v.add("route.destination", "cannot be changed in status %s", status)
+	}
+}
+
+func (r Route) equal(o Route) bool {
+	return r.Origin.equal(o.Origin) && r.Destination.equal(o.Destination) &&
+		slices.EqualFunc(r.Waypoints, o.Waypoints, Stop.equal)
+}
+
+// equal сравнивает точки без Kind: у точек из запроса он ещё не проставлен
+func (st Stop) equal(o Stop) bool {
+	if (st.Coordinates == nil) != (o.Coordinates == nil) ||
+		st.Coordinates != nil && *st.Coordinates != *o.Coordinates {
+		return false
+	}
+	return st.CityCode == o.CityCode && st.Address == o.Address && st.Contact == o.Contact
+}
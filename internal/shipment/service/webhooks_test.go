@@ -0,0 +1,112 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"sync/atomic"
+	"testing"
+)
+
+func TestPublicAddr(t *testing.T) {
+	tests := []struct {
+		ip   string
+		want bool
+	}{
+		{"127.0.0.1", false},
+		{"127.10.0.1", false},
+		{"10.1.2.3", false},
+		{"172.16.0.1", false},
+		{"192.168.1.1", false},
+		{"169.254.169.254", false},
+		{"0.0.0.0", false},
+		{"0.1.2.3", false},
+		{"100.64.0.1", false},
+		{"224.0.0.1", false},
+		{"::1", false},
+		{"::", false},
+		{"fe80::1", false},
+		{"fc00::1", false},
+		{"::ffff:127.0.0.1", false},
+		{"::ffff:10.0.0.1", false},
+		{"93.184.216.34", true},
+		{"8.8.8.8", true},
+		{"2001:4860:4860::8888", true},
+	}
+	for _, tt := range tests {
+		if got := publicAddr(netip.MustParseAddr(tt.ip)); got != tt.want {
+			t.Errorf("publicAddr(%s) = %v, want %v", tt.ip, got, tt.want)
+		}
+	}
+}
+
+func TestValidateWebhookURL(t *testing.T) {
+	tests := []struct {
+		url     string
+		private bool
+		wantErr bool
+	}{
+		{"https://client.example/hooks", false, false},
+		{"https://93.184.216.34/hooks", false, false},
+		{"https://127.0.0.1/hooks", false, true},
+		{"https://localhost:8443/hooks", false, true},
+		{"https://api.localhost/hooks", false, true},
+		{"https://[::1]/hooks", false, true},
+		{"https://169.254.169.254/latest/meta-data", false, true},
+		{"https://10.0.0.5/hooks", false, true},
+		{"https://10.0.0.5/hooks", true, false},
+		{"https://localhost/hooks", true, false},
+	}
+	for _, tt := range tests {
+		s := &Service{cfg: Config{AllowPrivateWebhooks: tt.private}}
+		if err := s.validateWebhookURL(tt.url); (err != nil) != tt.wantErr {
+			t.Errorf("validateWebhookURL(%q) with private=%v: error = %v, wantErr %v", tt.url, tt.private, err, tt.wantErr)
+		}
+	}
+}
+
+// Адрес проверяется при соединении: имя, разрешившееся во внутренний адрес,
+// отклоняется, и запрос до подписчика не доходит
+func TestWebhookClientRejectsInternalAddress(t *testing.T) {
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+	}))
+	defer srv.Close()
+
+	_, port, err := net.SplitHostPort(srv.Listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// имя, а не IP: адрес известен только после разрешения
+	target := "http://localhost:" + port + "/hooks"
+
+	client := newWebhookClient(Config{})
+	req, _ := http.NewRequestWithContext(context.Background(), http.MethodPost, target, nil)
+	resp, err := client.Do(req)
+	if err == nil {
+		resp.Body.Close()
+		t.Fatal("request to a loopback address succeeded")
+	}
+	if !errors.Is(err, errWebhookAddrForbidden) {
+		t.Errorf("error = %v, want %v", err, errWebhookAddrForbidden)
+	}
+	if n := hits.Load(); n != 0 {
+		t.Errorf("server received %d requests, want 0", n)
+	}
+
+	// в локальной разработке ограничение снимается
+	client = newWebhookClient(Config{AllowPrivateWebhooks: true})
+	req, _ = http.NewRequestWithContext(context.Background(), http.MethodPost, target, nil)
+	resp, err = client.Do(req)
+	if err != nil {
+		t.Fatalf("AllowPrivateWebhooks: error = %v", err)
+	}
+	resp.Body.Close()
+	if n := hits.Load(); n != 1 {
+		t.Errorf("server received %d requests, want 1", n)
+	}
+}
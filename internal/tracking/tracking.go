@@ -0,0 +1,115 @@
+// Package tracking — трек-номера отправлений вида TL2610180000014:
+// префикс, дата создания ГГММДД по времени Казахстана, порядковый номер за
+// день (6 цифр) и контрольная цифра Луна по 12 цифрам даты и номера.
+package tracking
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Prefix — префикс трек-номеров Transline
+const Prefix = "TL"
+
+// MaxSeq — максимум отправлений за день
+const MaxSeq = 999_999
+
+// Length — длина номера: префикс, 6 цифр даты, 6 цифр номера, контрольная
+const Length = len(Prefix) + 6 + 6 + 1
+
+// Zone — часовой пояс, по которому считается дата в номере (UTC+5 с 2024 года)
+var Zone = time.FixedZone("UTC+5", 5*60*60)
+
+var (
+	ErrFormat   = errors.New("tracking number must look like TL2610180000014")
+	ErrChecksum = errors.New("tracking number check digit mismatch")
+	ErrSeq      = errors.New("tracking number sequence is out of range")
+)
+
+// Number — разобранный трек-номер
+type Number struct {
+	Day time.Time
+	Seq int
+}
+
+// Day — дата в Zone, к которой относится номер, созданный в момент t
+func Day(t time.Time) time.Time {
+	y, m, d := t.In(Zone).Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, Zone)
+}
+
+// Format собирает номер из даты и порядкового номера за день (1..MaxSeq)
+func Format(day time.Time, seq int) (string, error) {
+	if seq < 1 || seq > MaxSeq {
+		return "", fmt.Errorf("%w: %d", ErrSeq, seq)
+	}
+	body := day.In(Zone).Format("060102") + fmt.Sprintf("%06d", seq)
+	return Prefix + body + string(rune('0'+checkDigit(body))), nil
+}
+
+// Normalize приводит ввод к каноническому виду: верхний регистр, без пробелов
+// и дефисов ("tl 261018-000001-4" → "TL2610180000014")
+func Normalize(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r == ' ' || r == '-':
+			return -1
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		}
+		return r
+	}, strings.TrimSpace(s))
+}
+
+// Parse проверяет формат, дату и контрольную цифру нормализованного номера
+func Parse(s string) (Number, error) {
+	if len(s) != Length || !strings.HasPrefix(s, Prefix) {
+		return Number{}, ErrFormat
+	}
+	digits := s[len(Prefix):]
+	for _, r := range digits {
+		if r < '0' || r > '9' {
+			return Number{}, ErrFormat
+		}
+	}
+	body := digits[:12]
+	if int(digits[12]-'0') != checkDigit(body) {
+		return Number{}, ErrChecksum
+	}
+	day, err := time.ParseInLocation("060102", body[:6], Zone)
+	if err != nil {
+		return Number{}, ErrFormat
+	}
+	seq, _ := strconv.Atoi(body[6:])
+	if seq < 1 {
+		return Number{}, fmt.Errorf("%w: %d", ErrSeq, seq)
+	}
+	return Number{Day: day, Seq: seq}, nil
+}
+
+// Validate — то же, что Parse, когда разобранные данные не нужны
+func Validate(s string) error {
+	_, err := Parse(s)
+	return err
+}
+
+// checkDigit — контрольная цифра по алгоритму Луна: ловит любую одиночную
+// ошибку и почти все перестановки соседних цифр
+func checkDigit(digits string) int {
+	sum := 0
+	double := true // справа налево, начиная с цифры перед контрольной
+	for i := len(digits) - 1; i >= 0; i-- {
+		d := int(digits[i] - '0')
+		if double {
+			if d *= 2; d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return (10 - sum%10) % 10
+}
@@ -0,0 +1,137 @@
+package tracking
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func date(y int, m time.Month, d int) time.Time {
+	return time.Date(y, m, d, 0, 0, 0, 0, Zone)
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in      string
+		wantDay time.Time
+		wantSeq int
+	}{
+		{"TL2610180000014", date(2026, time.October, 18), 1},
+		{"TL2512319999993", date(2025, time.December, 31), MaxSeq},
+		{"TL2402290000427", date(2024, time.February, 29), 42},
+	}
+	for _, tt := range tests {
+		n, err := Parse(tt.in)
+		if err != nil {
+			t.Errorf("Parse(%q) error = %v", tt.in, err)
+			continue
+		}
+		if !n.Day.Equal(tt.wantDay) || n.Seq != tt.wantSeq {
+			t.Errorf("Parse(%q) = %s #%d, want %s #%d", tt.in, n.Day.Format(time.DateOnly), n.Seq,
+				tt.wantDay.Format(time.DateOnly), tt.wantSeq)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want error
+	}{
+		// TL2610180000014 с одной искажённой цифрой
+		{"single digit in sequence", "TL2610180000024", ErrChecksum},
+		{"single digit in date", "TL2610190000014", ErrChecksum},
+		{"wrong check digit", "TL2610180000015", ErrChecksum},
+		// перестановка соседних цифр
+		{"adjacent transposition in sequence", "TL2610180000104", ErrChecksum},
+		{"adjacent transposition in date", "TL2601180000014", ErrChecksum},
+		// контрольная цифра верна, даты нет
+		{"month 13", "TL2613180000018", ErrFormat},
+		{"30 February", "TL2602300000016", ErrFormat},
+		{"zero sequence", "TL2610180000006", ErrSeq},
+		{"too short", "TL261018000001", ErrFormat},
+		{"too long", "TL26101800000144", ErrFormat},
+		{"wrong prefix", "XX2610180000014", ErrFormat},
+		{"letter in digits", "TL26101800O0014", ErrFormat},
+		{"not normalized", "tl2610180000014", ErrFormat},
+		{"empty", "", ErrFormat},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Parse(tt.in); !errors.Is(err, tt.want) {
+				t.Errorf("Parse(%q) error = %v, want %v", tt.in, err, tt.want)
+			}
+		})
+	}
+}
+
+// Луна не различает перестановку 09 ↔ 90: оба номера проходят проверку
+func TestParseLuhnBlindSpot(t *testing.T) {
+	for _, in := range []string{"TL2610180009015", "TL2610180090015"} {
+		if err := Validate(in); err != nil {
+			t.Errorf("Validate(%q) error = %v", in, err)
+		}
+	}
+}
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		day  time.Time
+		seq  int
+		want string
+	}{
+		{date(2026, time.October, 18), 1, "TL2610180000014"},
+		{date(2025, time.December, 31), MaxSeq, "TL2512319999993"},
+		// дата берётся в UTC+5: 19:30 UTC 17 октября — уже 18 октября
+		{time.Date(2026, time.October, 17, 19, 30, 0, 0, time.UTC), 1, "TL2610180000014"},
+	}
+	for _, tt := range tests {
+		got, err := Format(tt.day, tt.seq)
+		if err != nil || got != tt.want {
+			t.Errorf("Format(%s, %d) = %q, %v; want %q", tt.day, tt.seq, got, err, tt.want)
+		}
+		if err := Validate(got); err != nil {
+			t.Errorf("Validate(Format(%s, %d)) error = %v", tt.day, tt.seq, err)
+		}
+	}
+	for _, seq := range []int{0, -1, MaxSeq + 1} {
+		if _, err := Format(date(2026, time.October, 18), seq); !errors.Is(err, ErrSeq) {
+			t.Errorf("Format(seq %d) error = %v, want %v", seq, err, ErrSeq)
+		}
+	}
+}
+
+func TestDay(t *testing.T) {
+	tests := []struct {
+		at   time.Time
+		want time.Time
+	}{
+		{time.Date(2026, time.October, 17, 18, 59, 59, 0, time.UTC), date(2026, time.October, 17)},
+		{time.Date(2026, time.October, 17, 19, 0, 0, 0, time.UTC), date(2026, time.October, 18)},
+		{time.Date(2026, time.October, 18, 0, 0, 0, 0, Zone), date(2026, time.October, 18)},
+	}
+	for _, tt := range tests {
+		if got := Day(tt.at); !got.Equal(tt.want) {
+			t.Errorf("Day(%s) = %s, want %s", tt.at, got, tt.want)
+		}
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"TL2610180000014", "TL2610180000014"},
+		{"tl 261018-000001-4", "TL2610180000014"},
+		{"  Tl2610180000014\t", "TL2610180000014"},
+		// остальное не трогается, и Parse отклонит номер
+		{"TL261018_000001_4", "TL261018_000001_4"},
+	}
+	for _, tt := range tests {
+		if got := Normalize(tt.in); got != tt.want {
+			t.Errorf("Normalize(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
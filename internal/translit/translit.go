@@ -0,0 +1,14 @@
+// Package translit — общая таблица транслитерации кириллицы русского и
+// казахского алфавитов в ASCII-латиницу. По ней строятся ключи справочника
+// городов и текст документов, поэтому "Қарағанды" в обоих — "karagandy".
+package translit
+
+// Cyrillic — строчные буквы кириллицы в ASCII-латиницу; ъ и ь опускаются
+var Cyrillic = map[rune]string{
+	'а': "a", 'ә': "a", 'б': "b", 'в': "v", 'г': "g", 'ғ': "g", 'д': "d",
+	'е': "e", 'ё': "e", 'ж': "zh", 'з': "z", 'и': "i", 'й': "y", 'і': "i",
+	'к': "k", 'қ': "k", 'л': "l", 'м': "m", 'н': "n", 'ң': "n", 'о': "o",
+	'ө': "o", 'п': "p", 'р': "r", 'с': "s", 'т': "t", 'у': "u", 'ұ': "u",
+	'ү': "u", 'ф': "f", 'х': "kh", 'һ': "h", 'ц': "ts", 'ч': "ch", 'ш': "sh",
+	'щ': "shch", 'ъ': "", 'ы': "y", 'ь': "", 'э': "e", 'ю': "yu", 'я': "ya",
+}
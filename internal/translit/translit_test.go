@@ -0,0 +1,27 @@
+package translit
+
+import (
+	"testing"
+	"unicode"
+)
+
+// Все строчные буквы русского и казахского алфавитов есть в таблице,
+// и замены — строчная ASCII-латиница
+func TestCyrillic(t *testing.T) {
+	const alphabets = "абвгдеёжзийклмнопрстуфхцчшщъыьэюя" + "әғқңөұүһі"
+	for _, r := range alphabets {
+		lat, ok := Cyrillic[r]
+		if !ok {
+			t.Errorf("no transliteration for %q", r)
+			continue
+		}
+		for _, c := range lat {
+			if c > unicode.MaxASCII || !unicode.IsLower(c) {
+				t.Errorf("%q → %q: not lower-case ASCII", r, lat)
+			}
+		}
+	}
+	if len(Cyrillic) != len([]rune(alphabets)) {
+		t.Errorf("table has %d letters, want %d", len(Cyrillic), len([]rune(alphabets)))
+	}
+}
@@ -0,0 +1,14 @@
+-- 003_shipment_status.sql
+ALTER TABLE shipments
+  ADD CONSTRAINT shipments_status_check CHECK (status IN (
+    'CREATED',
+    'CONFIRMED',
+    'PICKED_UP',
+    'IN_TRANSIT',
+    'AT_HUB',
+    'OUT_FOR_DELIVERY',
+    'DELIVERED',
+    'CANCELLED',
+    'RETURNED',
+    'LOST'
+  ));
@@ -0,0 +1,15 @@
+-- 004_shipments_indexes.sql
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+ALTER TABLE shipments
+  ALTER COLUMN created_at SET NOT NULL;
+
+-- keyset-пагинация: (sort_key, id)
+CREATE INDEX shipments_created_at_id_idx ON shipments (created_at, id);
+CREATE INDEX shipments_price_id_idx ON shipments (price, id);
+
+CREATE INDEX shipments_customer_id_created_at_idx ON shipments (customer_id, created_at);
+CREATE INDEX shipments_status_created_at_idx ON shipments (status, created_at);
+
+-- поиск по подстроке маршрута (ILIKE '%...%')
+CREATE INDEX shipments_route_trgm_idx ON shipments USING gin (route gin_trgm_ops);
@@ -0,0 +1,18 @@
+-- 005_shipment_events.sql
+CREATE TABLE shipment_events (
+  id BIGSERIAL PRIMARY KEY,
+  shipment_id UUID NOT NULL REFERENCES shipments(id),
+  type TEXT NOT NULL CHECK (type IN ('STATUS_CHANGED', 'LOCATION_SCAN', 'NOTE')),
+  status_from TEXT,
+  status_to TEXT,
+  location TEXT,
+  latitude DOUBLE PRECISION CHECK (latitude BETWEEN -90 AND 90),
+  longitude DOUBLE PRECISION CHECK (longitude BETWEEN -180 AND 180),
+  note TEXT,
+  actor TEXT NOT NULL,
+  occurred_at TIMESTAMP NOT NULL DEFAULT now(),
+  CHECK ((latitude IS NULL) = (longitude IS NULL)),
+  CHECK (type <> 'STATUS_CHANGED' OR status_to IS NOT NULL)
+);
+
+CREATE INDEX shipment_events_shipment_id_idx ON shipment_events (shipment_id, occurred_at, id);
@@ -0,0 +1,15 @@
+-- 006_idempotency_keys.sql
+CREATE TABLE idempotency_keys (
+  scope TEXT NOT NULL,
+  key TEXT NOT NULL,
+  request_hash TEXT NOT NULL,
+  -- NULL пока запрос обрабатывается
+  status_code INT,
+  content_type TEXT,
+  response_body BYTEA,
+  created_at TIMESTAMP NOT NULL DEFAULT now(),
+  expires_at TIMESTAMP NOT NULL,
+  PRIMARY KEY (scope, key)
+);
+
+CREATE INDEX idempotency_keys_expires_at_idx ON idempotency_keys (expires_at);
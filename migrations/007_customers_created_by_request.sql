@@ -0,0 +1,7 @@
+-- 007_customers_created_by_request.sql
+-- Операция (saga), создавшая клиента; нужна для компенсации UpsertCustomer
+ALTER TABLE customers
+  ADD COLUMN created_by_request UUID;
+
+CREATE INDEX customers_created_by_request_idx ON customers (created_by_request)
+  WHERE created_by_request IS NOT NULL;
@@ -0,0 +1,26 @@
+-- 008_shipment_sagas.sql
+-- Журнал двухшаговой операции создания: UpsertCustomer (customer-service) → INSERT shipment
+CREATE TABLE shipment_sagas (
+  -- совпадает с id создаваемого shipment и request_id в customer-service
+  id UUID PRIMARY KEY,
+  idn TEXT NOT NULL,
+  route TEXT NOT NULL,
+  price NUMERIC NOT NULL,
+  actor TEXT NOT NULL,
+  state TEXT NOT NULL CHECK (state IN (
+    'STARTED',
+    'CUSTOMER_UPSERTED',
+    'COMPLETED',
+    'COMPENSATING',
+    'COMPENSATED'
+  )),
+  customer_id UUID,
+  customer_created BOOLEAN NOT NULL DEFAULT false,
+  attempts INT NOT NULL DEFAULT 0,
+  last_error TEXT,
+  created_at TIMESTAMP NOT NULL DEFAULT now(),
+  updated_at TIMESTAMP NOT NULL DEFAULT now()
+);
+
+CREATE INDEX shipment_sagas_pending_idx ON shipment_sagas (updated_at)
+  WHERE state NOT IN ('COMPLETED', 'COMPENSATED');
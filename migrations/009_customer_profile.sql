@@ -0,0 +1,25 @@
+-- 009_customer_profile.sql
+ALTER TABLE customers
+  ADD COLUMN name TEXT NOT NULL DEFAULT '',
+  ADD COLUMN customer_type TEXT,
+  ADD COLUMN phone TEXT NOT NULL DEFAULT '',
+  ADD COLUMN email TEXT NOT NULL DEFAULT '',
+  ADD COLUMN legal_address TEXT NOT NULL DEFAULT '',
+  ADD COLUMN updated_at TIMESTAMP NOT NULL DEFAULT now();
+
+-- 5-я цифра 4..6 — БИН (юрлицо), иначе ИИН
+UPDATE customers
+SET customer_type = CASE
+  WHEN substr(idn, 5, 1) IN ('4', '5', '6') THEN 'LEGAL_ENTITY'
+  ELSE 'INDIVIDUAL'
+END;
+
+UPDATE customers SET created_at = now() WHERE created_at IS NULL;
+
+ALTER TABLE customers
+  ALTER COLUMN customer_type SET NOT NULL,
+  ADD CONSTRAINT customers_customer_type_check CHECK (customer_type IN ('INDIVIDUAL', 'LEGAL_ENTITY')),
+  ALTER COLUMN created_at SET NOT NULL;
+
+CREATE INDEX customers_created_at_id_idx ON customers (created_at, id);
+CREATE INDEX customers_type_created_at_idx ON customers (customer_type, created_at);
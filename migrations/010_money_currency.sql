@@ -0,0 +1,12 @@
+-- 010_money_currency.sql
+-- Суммы храним точно: не больше 2 знаков после запятой (минорная единица
+-- всех поддерживаемых валют), рядом — код валюты ISO 4217
+ALTER TABLE shipments
+  ALTER COLUMN price TYPE NUMERIC(18, 2) USING round(price, 2),
+  ADD COLUMN currency CHAR(3) NOT NULL DEFAULT 'KZT'
+    CHECK (currency ~ '^[A-Z]{3}$');
+
+ALTER TABLE shipment_sagas
+  ALTER COLUMN price TYPE NUMERIC(18, 2) USING round(price, 2),
+  ADD COLUMN currency CHAR(3) NOT NULL DEFAULT 'KZT'
+    CHECK (currency ~ '^[A-Z]{3}$');
@@ -0,0 +1,54 @@
+-- 011_shipment_stops.sql
+-- Маршрут как последовательность точек: ORIGIN, 0..n WAYPOINT, DESTINATION
+CREATE TABLE shipment_stops (
+  shipment_id UUID NOT NULL REFERENCES shipments(id),
+  seq INT NOT NULL CHECK (seq >= 0),
+  kind TEXT NOT NULL CHECK (kind IN ('ORIGIN', 'WAYPOINT', 'DESTINATION')),
+  city_code TEXT NOT NULL,
+  address TEXT,
+  latitude DOUBLE PRECISION CHECK (latitude BETWEEN -90 AND 90),
+  longitude DOUBLE PRECISION CHECK (longitude BETWEEN -180 AND 180),
+  contact_name TEXT,
+  contact_phone TEXT,
+  PRIMARY KEY (shipment_id, seq),
+  CHECK ((latitude IS NULL) = (longitude IS NULL))
+);
+
+-- денормализация для фильтров (и тарификации) по направлению
+ALTER TABLE shipments
+  ADD COLUMN origin_city TEXT,
+  ADD COLUMN destination_city TEXT;
+
+ALTER TABLE shipment_sagas
+  ADD COLUMN stops JSONB NOT NULL DEFAULT '[]';
+
+-- Перенос старых маршрутов вида "ALMATY→ASTANA" или "ALMATY -> ASTANA" — как в
+-- ParseRoute: оба разделителя, пробелы по краям обрезаются. Маршруты, которые
+-- API отклонил бы (пустой сегмент вроде "A→→B", меньше двух точек), не
+-- переносятся и остаются только строкой в shipments.route
+WITH parts AS (
+  SELECT s.id, upper(btrim(p.city, E' \t\r\n')) AS city, p.ord - 1 AS seq,
+    count(*) OVER (PARTITION BY s.id) AS total
+  FROM shipments s
+  CROSS JOIN LATERAL unnest(regexp_split_to_array(s.route, '→|->')) WITH ORDINALITY AS p(city, ord)
+)
+INSERT INTO shipment_stops (shipment_id, seq, kind, city_code)
+SELECT id, seq,
+  CASE
+    WHEN seq = 0 THEN 'ORIGIN'
+    WHEN seq = total - 1 THEN 'DESTINATION'
+    ELSE 'WAYPOINT'
+  END,
+  city
+FROM parts
+WHERE total >= 2
+  AND id NOT IN (SELECT id FROM parts WHERE city = '');
+
+UPDATE shipments s
+SET origin_city = o.city_code, destination_city = d.city_code
+FROM shipment_stops o, shipment_stops d
+WHERE o.shipment_id = s.id AND o.kind = 'ORIGIN'
+  AND d.shipment_id = s.id AND d.kind = 'DESTINATION';
+
+CREATE INDEX shipments_origin_destination_idx ON shipments (origin_city, destination_city, created_at);
+CREATE INDEX shipments_destination_city_idx ON shipments (destination_city, created_at);
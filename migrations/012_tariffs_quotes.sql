@@ -0,0 +1,102 @@
+-- 012_tariffs_quotes.sql
+-- Версионированные тарифы: новая версия — новая строка tariffs, старые
+-- остаются для уже выданных котировок
+CREATE TABLE tariffs (
+  id BIGSERIAL PRIMARY KEY,
+  version INT NOT NULL,
+  currency CHAR(3) NOT NULL,
+  valid_from TIMESTAMP NOT NULL,
+  valid_to TIMESTAMP,
+  -- минимальная стоимость перевозки
+  min_price NUMERIC(18, 2) NOT NULL CHECK (min_price >= 0),
+  -- объёмный вес: кг на 1 м³
+  volumetric_kg_per_m3 INT NOT NULL CHECK (volumetric_kg_per_m3 > 0),
+  -- коэффициент от расстояния по прямой к дорожному
+  road_factor NUMERIC(4, 2) NOT NULL DEFAULT 1.00 CHECK (road_factor >= 1),
+  created_at TIMESTAMP NOT NULL DEFAULT now(),
+  UNIQUE (currency, version),
+  CHECK (valid_to IS NULL OR valid_to > valid_from)
+);
+
+-- Ставки по уровню сервиса и поясу расстояния (max_distance_km IS NULL — без ограничения)
+CREATE TABLE tariff_rates (
+  tariff_id BIGINT NOT NULL REFERENCES tariffs(id),
+  service_level TEXT NOT NULL CHECK (service_level IN ('ECONOMY', 'STANDARD', 'EXPRESS')),
+  max_distance_km INT CHECK (max_distance_km > 0),
+  base_price NUMERIC(18, 2) NOT NULL CHECK (base_price >= 0),
+  per_km NUMERIC(18, 2) NOT NULL CHECK (per_km >= 0),
+  per_kg NUMERIC(18, 2) NOT NULL CHECK (per_kg >= 0),
+  transit_days INT NOT NULL CHECK (transit_days > 0),
+  UNIQUE NULLS NOT DISTINCT (tariff_id, service_level, max_distance_km)
+);
+
+-- Надбавки: PERCENT — процент от стоимости перевозки, FIXED — сумма;
+-- mandatory применяются всегда, остальные — по опциям запроса
+CREATE TABLE tariff_surcharges (
+  tariff_id BIGINT NOT NULL REFERENCES tariffs(id),
+  code TEXT NOT NULL,
+  kind TEXT NOT NULL CHECK (kind IN ('PERCENT', 'FIXED')),
+  value NUMERIC(18, 2) NOT NULL CHECK (value >= 0),
+  mandatory BOOLEAN NOT NULL DEFAULT false,
+  description TEXT NOT NULL DEFAULT '',
+  PRIMARY KEY (tariff_id, code)
+);
+
+CREATE TABLE quotes (
+  id UUID PRIMARY KEY,
+  tariff_id BIGINT NOT NULL REFERENCES tariffs(id),
+  origin_city TEXT NOT NULL,
+  destination_city TEXT NOT NULL,
+  waypoints TEXT[] NOT NULL DEFAULT '{}',
+  distance_km INT NOT NULL,
+  weight_g BIGINT NOT NULL,
+  volume_cm3 BIGINT NOT NULL,
+  chargeable_weight_g BIGINT NOT NULL,
+  service_level TEXT NOT NULL,
+  options TEXT[] NOT NULL DEFAULT '{}',
+  price NUMERIC(18, 2) NOT NULL,
+  currency CHAR(3) NOT NULL,
+  transit_days INT NOT NULL,
+  breakdown JSONB NOT NULL,
+  expires_at TIMESTAMP NOT NULL,
+  -- отправление (id саги), для которого использована котировка
+  shipment_id UUID UNIQUE,
+  created_at TIMESTAMP NOT NULL DEFAULT now()
+);
+
+CREATE INDEX quotes_expires_at_idx ON quotes (expires_at) WHERE shipment_id IS NULL;
+
+ALTER TABLE shipment_sagas
+  ADD COLUMN quote_id UUID REFERENCES quotes(id);
+
+-- Тариф по умолчанию
+WITH t AS (
+  INSERT INTO tariffs (version, currency, valid_from, min_price, volumetric_kg_per_m3, road_factor)
+  VALUES (1, 'KZT', '2024-01-01', 5000, 250, 1.20)
+  RETURNING id
+)
+INSERT INTO tariff_rates (tariff_id, service_level, max_distance_km, base_price, per_km, per_kg, transit_days)
+SELECT t.id, r.level, r.max_km, r.base, r.per_km, r.per_kg, r.days
+FROM t, (VALUES
+  ('ECONOMY',  500,  3000, 25, 40, 3),
+  ('ECONOMY',  1500, 4000, 20, 35, 5),
+  ('ECONOMY',  NULL, 5000, 18, 30, 7),
+  ('STANDARD', 500,  4000, 32, 50, 2),
+  ('STANDARD', 1500, 5000, 26, 45, 3),
+  ('STANDARD', NULL, 6500, 23, 40, 5),
+  ('EXPRESS',  500,  6000, 45, 70, 1),
+  ('EXPRESS',  1500, 7500, 38, 62, 2),
+  ('EXPRESS',  NULL, 9000, 34, 55, 3)
+) AS r(level, max_km, base, per_km, per_kg, days);
+
+INSERT INTO tariff_surcharges (tariff_id, code, kind, value, mandatory, description)
+SELECT t.id, s.code, s.kind, s.value, s.mandatory, s.description
+FROM tariffs t, (VALUES
+  ('FUEL',          'PERCENT', 8,     true,  'Fuel surcharge'),
+  ('FRAGILE',       'PERCENT', 15,    false, 'Fragile cargo handling'),
+  ('HAZARDOUS',     'PERCENT', 40,    false, 'Dangerous goods'),
+  ('OVERSIZE',      'FIXED',   10000, false, 'Oversized cargo'),
+  ('DOOR_PICKUP',   'FIXED',   2500,  false, 'Pickup from sender address'),
+  ('DOOR_DELIVERY', 'FIXED',   2500,  false, 'Delivery to recipient address')
+) AS s(code, kind, value, mandatory, description)
+WHERE t.currency = 'KZT' AND t.version = 1;
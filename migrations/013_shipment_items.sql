@@ -0,0 +1,21 @@
+-- 013_shipment_items.sql
+-- Грузовые места отправления. Вес и габариты — на одно место, declared_value —
+-- на всю строку в валюте отправления.
+CREATE TABLE shipment_items (
+  shipment_id UUID NOT NULL REFERENCES shipments(id),
+  seq INT NOT NULL CHECK (seq >= 0),
+  description TEXT NOT NULL,
+  quantity INT NOT NULL CHECK (quantity > 0),
+  weight_g BIGINT NOT NULL CHECK (weight_g > 0),
+  length_mm INT CHECK (length_mm > 0),
+  width_mm INT CHECK (width_mm > 0),
+  height_mm INT CHECK (height_mm > 0),
+  declared_value NUMERIC(18,2) CHECK (declared_value >= 0),
+  packaging TEXT NOT NULL CHECK (packaging IN ('BOX', 'PALLET', 'CRATE', 'BAG', 'DRUM', 'ROLL', 'LOOSE', 'OTHER')),
+  hs_code TEXT CHECK (hs_code ~ '^[0-9]{6,10}$'),
+  PRIMARY KEY (shipment_id, seq),
+  CHECK ((length_mm IS NULL) = (width_mm IS NULL) AND (width_mm IS NULL) = (height_mm IS NULL))
+);
+
+ALTER TABLE shipment_sagas
+  ADD COLUMN items JSONB NOT NULL DEFAULT '[]';
@@ -0,0 +1,57 @@
+-- 014_tracking_numbers.sql
+-- Трек-номер TL + ГГММДД (UTC+5) + порядковый номер за день + контрольная цифра Луна
+CREATE TABLE tracking_sequences (
+  day DATE PRIMARY KEY,
+  last_seq INT NOT NULL CHECK (last_seq BETWEEN 1 AND 999999)
+);
+
+ALTER TABLE shipments
+  ADD COLUMN tracking_number TEXT;
+
+-- Временная функция для переноса: та же контрольная цифра, что в internal/tracking
+CREATE FUNCTION tmp_luhn_check_digit(digits TEXT) RETURNS INT AS $$
+DECLARE
+  total INT := 0;
+  d INT;
+  dbl BOOLEAN := true;
+BEGIN
+  FOR i IN REVERSE length(digits)..1 LOOP
+    d := substr(digits, i, 1)::INT;
+    IF dbl THEN
+      d := d * 2;
+      IF d > 9 THEN d := d - 9; END IF;
+    END IF;
+    total := total + d;
+    dbl := NOT dbl;
+  END LOOP;
+  RETURN (10 - total % 10) % 10;
+END;
+$$ LANGUAGE plpgsql IMMUTABLE;
+
+-- created_at хранится в UTC без часового пояса
+UPDATE shipments SET created_at = now() WHERE created_at IS NULL;
+
+WITH numbered AS (
+  SELECT id,
+    (created_at + INTERVAL '5 hours')::date AS day,
+    row_number() OVER (PARTITION BY (created_at + INTERVAL '5 hours')::date ORDER BY created_at, id) AS seq
+  FROM shipments
+)
+UPDATE shipments s
+SET tracking_number = 'TL' || b.body || tmp_luhn_check_digit(b.body)
+FROM (
+  SELECT id, to_char(day, 'YYMMDD') || lpad(seq::text, 6, '0') AS body
+  FROM numbered
+) b
+WHERE b.id = s.id;
+
+INSERT INTO tracking_sequences (day, last_seq)
+SELECT (created_at + INTERVAL '5 hours')::date, count(*)
+FROM shipments
+GROUP BY 1;
+
+DROP FUNCTION tmp_luhn_check_digit(TEXT);
+
+ALTER TABLE shipments
+  ALTER COLUMN tracking_number SET NOT NULL,
+  ADD CONSTRAINT shipments_tracking_number_key UNIQUE (tracking_number);
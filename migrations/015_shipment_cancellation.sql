@@ -0,0 +1,19 @@
+-- 015_shipment_cancellation.sql
+-- Отмена с причиной и сбором. Отменённые раньше через переход статуса
+-- остаются без причины.
+ALTER TABLE shipments
+  ADD COLUMN cancel_reason TEXT CHECK (cancel_reason IN (
+    'CUSTOMER_REQUEST',
+    'DUPLICATE',
+    'INCORRECT_DETAILS',
+    'CARGO_NOT_READY',
+    'CARRIER_UNAVAILABLE',
+    'OTHER'
+  )),
+  ADD COLUMN cancel_note TEXT,
+  ADD COLUMN cancellation_fee NUMERIC(18, 2) CHECK (cancellation_fee >= 0),
+  ADD COLUMN cancelled_at TIMESTAMP,
+  ADD CONSTRAINT shipments_cancellation_check CHECK (
+    cancel_reason IS NULL
+    OR (status = 'CANCELLED' AND cancellation_fee IS NOT NULL AND cancelled_at IS NOT NULL)
+  );
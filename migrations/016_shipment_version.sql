@@ -0,0 +1,5 @@
+-- 016_shipment_version.sql
+-- Версия для оптимистичной блокировки (ETag / If-Match): каждое изменение
+-- строки shipments увеличивает её на 1
+ALTER TABLE shipments
+  ADD COLUMN version BIGINT NOT NULL DEFAULT 1 CHECK (version > 0);
@@ -0,0 +1,20 @@
+-- 017_outbox.sql
+-- Transactional outbox: событие пишется в той же транзакции, что и изменение
+-- отправления или клиента; relay публикует строки по порядку id
+CREATE TABLE outbox (
+  id BIGSERIAL PRIMARY KEY,
+  event_id UUID NOT NULL UNIQUE,
+  aggregate_type TEXT NOT NULL,
+  aggregate_id UUID NOT NULL,
+  event_type TEXT NOT NULL,
+  payload JSONB NOT NULL,
+  occurred_at TIMESTAMP NOT NULL DEFAULT now(),
+  published_at TIMESTAMP,
+  attempts INT NOT NULL DEFAULT 0,
+  last_error TEXT
+);
+
+CREATE INDEX outbox_unpublished_idx ON outbox (id)
+  WHERE published_at IS NULL;
+CREATE INDEX outbox_published_at_idx ON outbox (published_at)
+  WHERE published_at IS NOT NULL;
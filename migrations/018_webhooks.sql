@@ -0,0 +1,48 @@
+-- 018_webhooks.sql
+-- Подписки клиентов на события отправлений и журнал доставки
+CREATE TABLE webhook_subscriptions (
+  id UUID PRIMARY KEY,
+  customer_id UUID NOT NULL REFERENCES customers(id),
+  url TEXT NOT NULL,
+  -- ключ HMAC-SHA256 подписи; нужен в открытом виде, чтобы подписывать
+  secret TEXT NOT NULL,
+  event_types TEXT[] NOT NULL CHECK (cardinality(event_types) > 0),
+  created_at TIMESTAMP NOT NULL DEFAULT now()
+);
+
+CREATE INDEX webhook_subscriptions_customer_idx ON webhook_subscriptions (customer_id);
+
+-- Доставка одного события outbox одной подписке; повтор того же события
+-- (relay доставляет «хотя бы один раз») не создаёт второй доставки
+CREATE TABLE webhook_deliveries (
+  id UUID PRIMARY KEY,
+  subscription_id UUID NOT NULL REFERENCES webhook_subscriptions(id) ON DELETE CASCADE,
+  event_id UUID NOT NULL,
+  event_type TEXT NOT NULL,
+  payload JSONB NOT NULL,
+  state TEXT NOT NULL CHECK (state IN ('PENDING', 'DELIVERED', 'DEAD')),
+  attempts INT NOT NULL DEFAULT 0,
+  next_attempt_at TIMESTAMP,
+  last_status_code INT,
+  last_error TEXT,
+  created_at TIMESTAMP NOT NULL DEFAULT now(),
+  delivered_at TIMESTAMP,
+  UNIQUE (subscription_id, event_id)
+);
+
+CREATE INDEX webhook_deliveries_due_idx ON webhook_deliveries (next_attempt_at)
+  WHERE state = 'PENDING';
+CREATE INDEX webhook_deliveries_log_idx ON webhook_deliveries (subscription_id, created_at DESC, id DESC);
+
+-- Каждая попытка доставки: код ответа или ошибка сети
+CREATE TABLE webhook_delivery_attempts (
+  id BIGSERIAL PRIMARY KEY,
+  delivery_id UUID NOT NULL REFERENCES webhook_deliveries(id) ON DELETE CASCADE,
+  attempt INT NOT NULL,
+  status_code INT,
+  error TEXT,
+  duration_ms INT NOT NULL,
+  attempted_at TIMESTAMP NOT NULL DEFAULT now()
+);
+
+CREATE INDEX webhook_delivery_attempts_delivery_idx ON webhook_delivery_attempts (delivery_id, id);
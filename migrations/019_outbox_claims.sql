@@ -0,0 +1,12 @@
+-- 019_outbox_claims.sql
+-- Relay забирает пачку событий, продлевая next_attempt_at на время публикации,
+-- и публикует вне транзакции. После неудачи next_attempt_at — время повтора;
+-- событие, не опубликованное за OUTBOX_MAX_ATTEMPTS попыток, откладывается
+-- (parked_at) и больше не задерживает следующие
+ALTER TABLE outbox
+  ADD COLUMN next_attempt_at TIMESTAMP,
+  ADD COLUMN parked_at TIMESTAMP;
+
+DROP INDEX outbox_unpublished_idx;
+CREATE INDEX outbox_unpublished_idx ON outbox (id)
+  WHERE published_at IS NULL AND parked_at IS NULL;
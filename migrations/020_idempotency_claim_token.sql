@@ -0,0 +1,4 @@
+-- 020_idempotency_claim_token.sql
+-- Токен заявки: сохранить ответ или освободить ключ может только тот запрос,
+-- который его занял, а не перехвативший ключ повтор
+ALTER TABLE idempotency_keys ADD COLUMN claim_token UUID;